@@ -0,0 +1,262 @@
+// A bidirectional map with unique keys and unique values (bimap).
+package bimap
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/order"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// ErrDuplicateValue is returned (wrapped) if a value cannot be added because it is already mapped to another key.
+var ErrDuplicateValue = errors.New("duplicate value")
+
+// BiMap maps unique keys of type K to unique values of type V, so keys can be looked up by their values as fast as values
+// by their keys (in O(1)). Inverse returns the bimap mapping the values to the keys.
+// A BiMap is not safe for concurrent use.
+type BiMap[K comparable, V comparable] interface {
+	Put(K, V) error
+	ForcePut(K, V)
+	RemoveByKey(K) (V, bool)
+	RemoveByValue(V) (K, bool)
+	Clear()
+
+	GetByKey(K) (V, bool)
+	GetByValue(V) (K, bool)
+	ContainsKey(K) bool
+	ContainsValue(V) bool
+	Size() int
+	All() iter.Seq2[K, V]
+	Keys() iter.Seq[K]
+	Values() iter.Seq[V]
+	Equals(BiMap[K, V]) bool
+	String() string
+
+	Inverse() BiMap[V, K]
+	Copy() BiMap[K, V]
+	ToSet() set.Set[K, V]
+}
+
+type biMap[K comparable, V comparable] struct {
+	forward  map[K]V
+	backward map[V]K
+	// inverse shares the maps of this bimap with keys and values swapped
+	inverse *biMap[V, K]
+}
+
+// New creates a new, empty bimap.
+func New[K comparable, V comparable]() BiMap[K, V] {
+	return newBiMap[K, V]()
+}
+
+func newBiMap[K comparable, V comparable]() *biMap[K, V] {
+	m := &biMap[K, V]{forward: make(map[K]V), backward: make(map[V]K)}
+	m.inverse = &biMap[V, K]{forward: m.backward, backward: m.forward, inverse: m}
+	return m
+}
+
+// DuplicateValuesError is returned by FromSet if elements of the set share values, which cannot be values of a bimap.
+type DuplicateValuesError[K comparable, V comparable] struct {
+	// Duplicates maps each value shared by elements of the set to these elements.
+	Duplicates map[V][]K
+}
+
+// Error returns the shared values with their elements, sorted if their types are ordered.
+func (e *DuplicateValuesError[K, V]) Error() string {
+	values := slices.Collect(maps.Keys(e.Duplicates))
+	if compare := order.Compare[V](); compare != nil {
+		slices.SortFunc(values, compare)
+	}
+	strValues := make([]string, 0, len(values))
+	for _, value := range values {
+		keys := slices.Clone(e.Duplicates[value])
+		if compare := order.Compare[K](); compare != nil {
+			slices.SortFunc(keys, compare)
+		}
+		strKeys := make([]string, len(keys))
+		for i, key := range keys {
+			strKeys[i] = fmt.Sprint(key)
+		}
+		strValues = append(strValues, fmt.Sprintf("%v (%s)", value, strings.Join(strKeys, ", ")))
+	}
+	return fmt.Sprintf("cannot create bimap from set, %v of elements: %s", ErrDuplicateValue, strings.Join(strValues, ", "))
+}
+
+// Unwrap returns ErrDuplicateValue.
+func (e *DuplicateValuesError[K, V]) Unwrap() error {
+	return ErrDuplicateValue
+}
+
+// FromSet creates a new bimap mapping the elements of the set to their values.
+// If elements share values, a DuplicateValuesError reporting all shared values is returned.
+// A nil set is treated as an empty set.
+func FromSet[K comparable, V comparable](s set.Set[K, V]) (BiMap[K, V], error) {
+	m := newBiMap[K, V]()
+	if s == nil {
+		return m, nil
+	}
+	var duplicates map[V][]K
+	for key, value := range s.All() {
+		if other, ok := m.backward[value]; ok {
+			if duplicates == nil {
+				duplicates = make(map[V][]K)
+			}
+			if len(duplicates[value]) == 0 {
+				duplicates[value] = []K{other}
+			}
+			duplicates[value] = append(duplicates[value], key)
+			continue
+		}
+		m.forward[key] = value
+		m.backward[value] = key
+	}
+	if duplicates != nil {
+		return nil, &DuplicateValuesError[K, V]{Duplicates: duplicates}
+	}
+	return m, nil
+}
+
+// Put maps the key to the value, replacing the previous value of the key.
+// If the value is already mapped to another key, an error wrapping ErrDuplicateValue is returned and the bimap is not changed.
+// Use ForcePut to remove the other key instead.
+func (m *biMap[K, V]) Put(key K, value V) error {
+	if other, ok := m.backward[value]; ok && other != key {
+		return fmt.Errorf("cannot put %v: %v into bimap, %w: %v is the value of %v", key, value, ErrDuplicateValue, value, other)
+	}
+	m.ForcePut(key, value)
+	return nil
+}
+
+// ForcePut maps the key to the value, replacing the previous value of the key.
+// If the value is already mapped to another key, that key is removed.
+func (m *biMap[K, V]) ForcePut(key K, value V) {
+	if old, ok := m.forward[key]; ok {
+		delete(m.backward, old)
+	}
+	if other, ok := m.backward[value]; ok {
+		delete(m.forward, other)
+	}
+	m.forward[key] = value
+	m.backward[value] = key
+}
+
+// RemoveByKey removes the key with its value and returns the value, or returns false if the key is not contained.
+func (m *biMap[K, V]) RemoveByKey(key K) (V, bool) {
+	value, ok := m.forward[key]
+	if ok {
+		delete(m.forward, key)
+		delete(m.backward, value)
+	}
+	return value, ok
+}
+
+// RemoveByValue removes the value with its key and returns the key, or returns false if the value is not contained.
+func (m *biMap[K, V]) RemoveByValue(value V) (K, bool) {
+	return m.inverse.RemoveByKey(value)
+}
+
+// Clear removes all keys and values from the bimap.
+func (m *biMap[K, V]) Clear() {
+	clear(m.forward)
+	clear(m.backward)
+}
+
+// GetByKey returns the value of the key, or returns false if the key is not contained.
+func (m *biMap[K, V]) GetByKey(key K) (V, bool) {
+	value, ok := m.forward[key]
+	return value, ok
+}
+
+// GetByValue returns the key of the value, or returns false if the value is not contained.
+func (m *biMap[K, V]) GetByValue(value V) (K, bool) {
+	key, ok := m.backward[value]
+	return key, ok
+}
+
+// ContainsKey checks if the key is contained in the bimap.
+func (m *biMap[K, V]) ContainsKey(key K) bool {
+	_, ok := m.forward[key]
+	return ok
+}
+
+// ContainsValue checks if the value is contained in the bimap.
+func (m *biMap[K, V]) ContainsValue(value V) bool {
+	_, ok := m.backward[value]
+	return ok
+}
+
+// Size returns the number of keys (which is the number of values).
+func (m *biMap[K, V]) Size() int {
+	return len(m.forward)
+}
+
+// All returns an iterator over the keys and their values. The order is not defined.
+func (m *biMap[K, V]) All() iter.Seq2[K, V] {
+	return maps.All(m.forward)
+}
+
+// Keys returns an iterator over the keys. The order is not defined.
+func (m *biMap[K, V]) Keys() iter.Seq[K] {
+	return maps.Keys(m.forward)
+}
+
+// Values returns an iterator over the values. The order is not defined.
+func (m *biMap[K, V]) Values() iter.Seq[V] {
+	return maps.Keys(m.backward)
+}
+
+// Equals checks if both bimaps map the same keys to the same values.
+// If otherBiMap is nil, true is returned if this bimap is empty.
+func (m *biMap[K, V]) Equals(otherBiMap BiMap[K, V]) bool {
+	if otherBiMap == nil {
+		return len(m.forward) == 0
+	}
+	if len(m.forward) != otherBiMap.Size() {
+		return false
+	}
+	for key, value := range m.forward {
+		if otherValue, ok := otherBiMap.GetByKey(key); !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the bimap in the form {a: 1, b: 2}.
+// The keys are sorted if K is an ordered type, the order is not defined otherwise.
+func (m *biMap[K, V]) String() string {
+	keys := slices.Collect(maps.Keys(m.forward))
+	if compare := order.Compare[K](); compare != nil {
+		slices.SortFunc(keys, compare)
+	}
+	strEntries := make([]string, len(keys))
+	for i, key := range keys {
+		strEntries[i] = fmt.Sprintf("%v: %v", key, m.forward[key])
+	}
+	return "{" + strings.Join(strEntries, ", ") + "}"
+}
+
+// Inverse returns the bimap mapping the values of this bimap to their keys. It is a view backed by this bimap:
+// changes of this bimap are visible in the inverse and vice versa. Lookups in the inverse take O(1) time as well.
+// The inverse of the inverse is this bimap.
+func (m *biMap[K, V]) Inverse() BiMap[V, K] {
+	return m.inverse
+}
+
+// Copy returns a new bimap containing all keys and values of this bimap.
+func (m *biMap[K, V]) Copy() BiMap[K, V] {
+	c := newBiMap[K, V]()
+	maps.Copy(c.forward, m.forward)
+	maps.Copy(c.backward, m.backward)
+	return c
+}
+
+// ToSet returns a new set with the keys as elements and their values.
+func (m *biMap[K, V]) ToSet() set.Set[K, V] {
+	return set.CollectWithValues(maps.All(m.forward))
+}
@@ -0,0 +1,213 @@
+package bimap
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func newTestBiMap() BiMap[string, int] {
+	m := New[string, int]()
+	m.ForcePut("a", 1)
+	m.ForcePut("b", 2)
+	m.ForcePut("c", 3)
+	return m
+}
+
+func TestShouldPutAndGetByKeyAndValue(t *testing.T) {
+	// Given
+	m := New[string, int]()
+
+	// When
+	err1 := m.Put("a", 1)
+	err2 := m.Put("b", 2)
+	err3 := m.Put("a", 1)
+	err4 := m.Put("a", 3)
+
+	// Then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.NoError(t, err4)
+	assert.Equal(t, 2, m.Size())
+	value, ok := m.GetByKey("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	key, ok := m.GetByValue(2)
+	assert.True(t, ok)
+	assert.Equal(t, "b", key)
+	_, ok = m.GetByValue(1)
+	assert.False(t, ok)
+	assert.True(t, m.ContainsKey("b"))
+	assert.False(t, m.ContainsKey("x"))
+	assert.True(t, m.ContainsValue(3))
+	assert.False(t, m.ContainsValue(1))
+	assert.Equal(t, "{a: 3, b: 2}", m.String())
+}
+
+func TestShouldRejectDuplicateValueOnPut(t *testing.T) {
+	// Given
+	m := newTestBiMap()
+
+	// When
+	err := m.Put("x", 2)
+
+	// Then
+	assert.EqualError(t, err, "cannot put x: 2 into bimap, duplicate value: 2 is the value of b")
+	assert.True(t, errors.Is(err, ErrDuplicateValue))
+	assert.Equal(t, "{a: 1, b: 2, c: 3}", m.String())
+}
+
+func TestShouldOverwriteDuplicateValueOnForcePut(t *testing.T) {
+	// Given
+	m := newTestBiMap()
+
+	// When
+	m.ForcePut("x", 2)
+	m.ForcePut("a", 3)
+
+	// Then
+	assert.Equal(t, "{a: 3, x: 2}", m.String())
+	assert.Equal(t, 2, m.Size())
+	assert.False(t, m.ContainsKey("b"))
+	assert.False(t, m.ContainsKey("c"))
+	assert.False(t, m.ContainsValue(1))
+	assert.Equal(t, "{2: x, 3: a}", m.Inverse().String())
+}
+
+func TestShouldRemoveByKeyAndValue(t *testing.T) {
+	// Given
+	m := newTestBiMap()
+
+	// When
+	value, removed1 := m.RemoveByKey("a")
+	_, removed2 := m.RemoveByKey("a")
+	key, removed3 := m.RemoveByValue(2)
+	_, removed4 := m.RemoveByValue(2)
+
+	// Then
+	assert.True(t, removed1)
+	assert.Equal(t, 1, value)
+	assert.False(t, removed2)
+	assert.True(t, removed3)
+	assert.Equal(t, "b", key)
+	assert.False(t, removed4)
+	assert.Equal(t, "{c: 3}", m.String())
+	assert.False(t, m.ContainsValue(1))
+	assert.False(t, m.ContainsKey("b"))
+
+	// When
+	m.Clear()
+	// Then
+	assert.Equal(t, 0, m.Size())
+	assert.Equal(t, 0, m.Inverse().Size())
+}
+
+func TestShouldIterateOverKeysAndValues(t *testing.T) {
+	// Given
+	m := newTestBiMap()
+
+	// Expect
+	assert.Equal(t, []string{"a", "b", "c"}, slices.Sorted(m.Keys()))
+	assert.Equal(t, []int{1, 2, 3}, slices.Sorted(m.Values()))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, maps.Collect(m.All()))
+}
+
+func TestShouldProvideLiveInverseView(t *testing.T) {
+	// Given
+	m := newTestBiMap()
+
+	// When
+	inverse := m.Inverse()
+
+	// Then
+	assert.Equal(t, "{1: a, 2: b, 3: c}", inverse.String())
+	assert.Equal(t, 3, inverse.Size())
+	key, ok := inverse.GetByKey(2)
+	assert.True(t, ok)
+	assert.Equal(t, "b", key)
+	assert.Same(t, m, inverse.Inverse())
+
+	// When the bimap is changed
+	m.ForcePut("d", 4)
+	// Then the view reflects the change
+	assert.True(t, inverse.ContainsKey(4))
+
+	// When the view is changed
+	inverse.ForcePut(5, "a")
+	err := inverse.Put(6, "e")
+	errDuplicate := inverse.Put(7, "b")
+	inverse.RemoveByValue("c")
+	// Then the bimap is changed
+	assert.NoError(t, err)
+	assert.EqualError(t, errDuplicate, "cannot put 7: b into bimap, duplicate value: b is the value of 2")
+	assert.Equal(t, "{a: 5, b: 2, d: 4, e: 6}", m.String())
+}
+
+func TestShouldCopyAndCompareBiMaps(t *testing.T) {
+	// Given
+	m := newTestBiMap()
+
+	// When
+	copied := m.Copy()
+	copied.ForcePut("x", 0)
+
+	// Then
+	assert.Equal(t, 3, m.Size())
+	assert.False(t, m.Equals(copied))
+	copied.RemoveByKey("x")
+	assert.True(t, m.Equals(copied))
+	copied.ForcePut("a", 0)
+	assert.False(t, m.Equals(copied))
+	assert.False(t, m.Equals(nil))
+	assert.True(t, New[string, int]().Equals(nil))
+}
+
+func TestShouldConvertFromAndToSet(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+	s.AddWithValue("a", 1)
+	s.AddWithValue("b", 2)
+
+	// When
+	m, err := FromSet(s)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "{a: 1, b: 2}", m.String())
+	assert.True(t, m.ToSet().Equals(s))
+	assert.True(t, m.Inverse().ToSet().Equals(set.CollectWithValues(maps.All(map[int]string{1: "a", 2: "b"}))))
+
+	// When
+	empty, err := FromSet[string, int](nil)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 0, empty.Size())
+}
+
+func TestShouldReportAllDuplicateValuesOfSet(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+	s.AddWithValue("a", 1)
+	s.AddWithValue("b", 2)
+	s.AddWithValue("c", 1)
+	s.AddWithValue("d", 2)
+	s.AddWithValue("e", 1)
+	s.AddWithValue("f", 3)
+
+	// When
+	m, err := FromSet(s)
+
+	// Then
+	assert.Nil(t, m)
+	assert.EqualError(t, err, "cannot create bimap from set, duplicate value of elements: 1 (a, c, e), 2 (b, d)")
+	assert.True(t, errors.Is(err, ErrDuplicateValue))
+	var duplicatesErr *DuplicateValuesError[string, int]
+	assert.True(t, errors.As(err, &duplicatesErr))
+	assert.Len(t, duplicatesErr.Duplicates, 2)
+	assert.ElementsMatch(t, []string{"a", "c", "e"}, duplicatesErr.Duplicates[1])
+}
@@ -0,0 +1,214 @@
+// Probabilistic membership filters (Bloom filters) for elements too numerous to be held in a set exactly.
+package bloom
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// Filter is a Bloom filter: a compact, probabilistic set of elements of type T.
+// MayContain never reports false negatives, but reports false positives with a probability depending on the size of the filter
+// and the number of added elements. Elements cannot be removed, see CountingFilter for a filter supporting Remove.
+// Filters can only be combined if they have the same number of bits and hash functions, and use the same hash function for T.
+type Filter[T comparable] interface {
+	Add(T)
+	MayContain(T) bool
+	Clear()
+
+	BitCount() int
+	HashCount() int
+	EstimatedCount() int
+
+	Copy() Filter[T]
+	Union(Filter[T]) (Filter[T], error)
+	Intersection(Filter[T]) (Filter[T], error)
+}
+
+type filter[T comparable] struct {
+	words     []uint64
+	bitCount  uint64
+	hashCount int
+	hashFunc  set.HashFunc[T]
+}
+
+// New creates a new, empty Bloom filter sized for expectedCount elements with the given false-positive rate,
+// using set.DefaultHashFunc for hashing the elements.
+// An error is returned if expectedCount is not positive or falsePositiveRate is not between 0 and 1 (exclusive).
+func New[T comparable](expectedCount int, falsePositiveRate float64) (Filter[T], error) {
+	return NewWithHashFunc[T](expectedCount, falsePositiveRate, nil)
+}
+
+// NewWithHashFunc creates a new, empty Bloom filter sized for expectedCount elements with the given false-positive rate,
+// using hashFunc for hashing the elements. If hashFunc is nil, set.DefaultHashFunc is used.
+// An error is returned if expectedCount is not positive or falsePositiveRate is not between 0 and 1 (exclusive).
+func NewWithHashFunc[T comparable](expectedCount int, falsePositiveRate float64, hashFunc set.HashFunc[T]) (Filter[T], error) {
+	bitCount, hashCount, err := optimalParameters(expectedCount, falsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+	return newFilter(bitCount, hashCount, hashFunc), nil
+}
+
+// FromSet creates a new Bloom filter containing all elements of the given set (ignoring the values),
+// sized for the size of the set with the given false-positive rate. If hashFunc is nil, set.DefaultHashFunc is used.
+// A nil set is treated as an empty set.
+// An error is returned if falsePositiveRate is not between 0 and 1 (exclusive).
+func FromSet[T comparable, V any](s set.Set[T, V], falsePositiveRate float64, hashFunc set.HashFunc[T]) (Filter[T], error) {
+	size := 0
+	if s != nil {
+		size = s.Size()
+	}
+	f, err := NewWithHashFunc(max(size, 1), falsePositiveRate, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		for elem := range s.Elements() {
+			f.Add(elem)
+		}
+	}
+	return f, nil
+}
+
+// optimalParameters calculates the number of bits m and hash functions k for n expected elements and a false-positive rate p:
+// m = -n*ln(p) / ln(2)^2 and k = m/n * ln(2).
+func optimalParameters(expectedCount int, falsePositiveRate float64) (uint64, int, error) {
+	if expectedCount <= 0 {
+		return 0, 0, fmt.Errorf("cannot create bloom filter, expected count must be positive but is %d", expectedCount)
+	}
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		return 0, 0, fmt.Errorf("cannot create bloom filter, false-positive rate must be between 0 and 1 but is %v", falsePositiveRate)
+	}
+	n := float64(expectedCount)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / n * math.Ln2))
+	return uint64(m), max(k, 1), nil
+}
+
+func newFilter[T comparable](bitCount uint64, hashCount int, hashFunc set.HashFunc[T]) *filter[T] {
+	if hashFunc == nil {
+		hashFunc = set.DefaultHashFunc[T]()
+	}
+	return &filter[T]{
+		words:     make([]uint64, (bitCount+63)/64),
+		bitCount:  bitCount,
+		hashCount: hashCount,
+		hashFunc:  hashFunc,
+	}
+}
+
+// indexes calls yield with the k bit indexes of an element hash, derived from two halves of the hash by double hashing
+// (Kirsch and Mitzenmacher), so the element only needs to be hashed once.
+func indexes(hash uint64, hashCount int, bitCount uint64, yield func(uint64) bool) bool {
+	h1 := hash
+	h2 := bits.RotateLeft64(hash, 32) | 1
+	for i := range hashCount {
+		if !yield((h1 + uint64(i)*h2) % bitCount) {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateCount estimates the number of added elements from the number of set bits x: n = -m/k * ln(1 - x/m).
+// A saturated filter is estimated as if all but one bit were set.
+func estimateCount(setBits, bitCount uint64, hashCount int) int {
+	x := float64(min(setBits, bitCount-1))
+	m := float64(bitCount)
+	return int(math.Round(-m / float64(hashCount) * math.Log(1-x/m)))
+}
+
+// Add adds an element to the filter.
+func (f *filter[T]) Add(element T) {
+	indexes(f.hashFunc(element), f.hashCount, f.bitCount, func(i uint64) bool {
+		f.words[i/64] |= 1 << (i % 64)
+		return true
+	})
+}
+
+// MayContain returns false if the element has definitely not been added to the filter,
+// and true if it has probably been added.
+func (f *filter[T]) MayContain(element T) bool {
+	return indexes(f.hashFunc(element), f.hashCount, f.bitCount, func(i uint64) bool {
+		return f.words[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+// Clear removes all elements from the filter.
+func (f *filter[T]) Clear() {
+	clear(f.words)
+}
+
+// BitCount returns the number of bits of the filter.
+func (f *filter[T]) BitCount() int {
+	return int(f.bitCount)
+}
+
+// HashCount returns the number of hash functions (bits set per element) of the filter.
+func (f *filter[T]) HashCount() int {
+	return f.hashCount
+}
+
+// EstimatedCount returns the estimated number of distinct elements added to the filter,
+// calculated from the number of set bits.
+func (f *filter[T]) EstimatedCount() int {
+	setBits := 0
+	for _, word := range f.words {
+		setBits += bits.OnesCount64(word)
+	}
+	return estimateCount(uint64(setBits), f.bitCount, f.hashCount)
+}
+
+// Copy returns a new filter containing all elements of this filter.
+func (f *filter[T]) Copy() Filter[T] {
+	newFilter := *f
+	newFilter.words = append([]uint64(nil), f.words...)
+	return &newFilter
+}
+
+// compatible returns the internal representation of other, or an error if it cannot be combined with this filter.
+// A nil other is treated as an empty filter.
+func (f *filter[T]) compatible(other Filter[T], operation string) (*filter[T], error) {
+	if other == nil {
+		return newFilter(f.bitCount, f.hashCount, f.hashFunc), nil
+	}
+	o := other.(*filter[T])
+	if f.bitCount != o.bitCount || f.hashCount != o.hashCount {
+		return nil, fmt.Errorf("cannot calculate %s of bloom filters with %d bits and %d hashes and with %d bits and %d hashes",
+			operation, f.bitCount, f.hashCount, o.bitCount, o.hashCount)
+	}
+	return o, nil
+}
+
+// Union returns a new filter containing all elements of both, this filter and other.
+// The union is exact: it equals the filter created by adding the elements of both filters.
+// A nil other is treated as an empty filter. An error is returned if the filters are not compatible.
+func (f *filter[T]) Union(other Filter[T]) (Filter[T], error) {
+	o, err := f.compatible(other, "union")
+	if err != nil {
+		return nil, err
+	}
+	newFilter := f.Copy().(*filter[T])
+	for i, word := range o.words {
+		newFilter.words[i] |= word
+	}
+	return newFilter, nil
+}
+
+// Intersection returns a new filter containing the elements being in both, this filter and other.
+// The intersection may report more false positives than a filter created by adding only the common elements.
+// A nil other is treated as an empty filter. An error is returned if the filters are not compatible.
+func (f *filter[T]) Intersection(other Filter[T]) (Filter[T], error) {
+	o, err := f.compatible(other, "intersection")
+	if err != nil {
+		return nil, err
+	}
+	newFilter := f.Copy().(*filter[T])
+	for i, word := range o.words {
+		newFilter.words[i] &= word
+	}
+	return newFilter, nil
+}
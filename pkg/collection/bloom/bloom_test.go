@@ -0,0 +1,181 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func TestShouldCalculateOptimalParameters(t *testing.T) {
+	// When
+	f, err := New[string](1000, 0.01)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 9586, f.BitCount())
+	assert.Equal(t, 7, f.HashCount())
+	assert.Equal(t, 0, f.EstimatedCount())
+}
+
+func TestShouldFailToCreateFilterWithInvalidParameters(t *testing.T) {
+	for _, testCase := range []struct {
+		expectedCount     int
+		falsePositiveRate float64
+		err               string
+	}{
+		{expectedCount: 0, falsePositiveRate: 0.01, err: "cannot create bloom filter, expected count must be positive but is 0"},
+		{expectedCount: 10, falsePositiveRate: 0, err: "cannot create bloom filter, false-positive rate must be between 0 and 1 but is 0"},
+		{expectedCount: 10, falsePositiveRate: 1, err: "cannot create bloom filter, false-positive rate must be between 0 and 1 but is 1"},
+	} {
+		// When
+		f, err := New[int](testCase.expectedCount, testCase.falsePositiveRate)
+		cf, countingErr := NewCounting[int](testCase.expectedCount, testCase.falsePositiveRate)
+
+		// Then
+		assert.Nil(t, f)
+		assert.EqualError(t, err, testCase.err)
+		assert.Nil(t, cf)
+		assert.EqualError(t, countingErr, testCase.err)
+	}
+}
+
+func TestShouldAddElementsWithoutFalseNegatives(t *testing.T) {
+	// Given
+	f, _ := New[string](1000, 0.01)
+
+	// When
+	for i := range 1000 {
+		f.Add(fmt.Sprintf("element %d", i))
+	}
+
+	// Then there are no false negatives
+	for i := range 1000 {
+		assert.True(t, f.MayContain(fmt.Sprintf("element %d", i)))
+	}
+	// and the false-positive rate is close to the configured one
+	falsePositives := 0
+	for i := range 10_000 {
+		if f.MayContain(fmt.Sprintf("other %d", i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 200)
+	// and the estimated count is close to the real count
+	assert.InDelta(t, 1000, f.EstimatedCount(), 50)
+
+	// When
+	f.Clear()
+	// Then
+	assert.False(t, f.MayContain("element 1"))
+	assert.Equal(t, 0, f.EstimatedCount())
+}
+
+func TestShouldUniteAndIntersectFilters(t *testing.T) {
+	// Given
+	f1, _ := New[int](100, 0.01)
+	f2, _ := New[int](100, 0.01)
+	expected, _ := New[int](100, 0.01)
+	for i := range 50 {
+		f1.Add(i)
+		f2.Add(i + 25)
+		expected.Add(i)
+		expected.Add(i + 25)
+	}
+
+	// When
+	union, err := f1.Union(f2)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, expected.(*filter[int]).words, union.(*filter[int]).words)
+	assert.InDelta(t, 75, union.EstimatedCount(), 5)
+
+	// When
+	intersection, err := f1.Intersection(f2)
+	// Then
+	assert.Nil(t, err)
+	for i := 25; i < 50; i++ {
+		assert.True(t, intersection.MayContain(i))
+	}
+	assert.InDelta(t, 25, intersection.EstimatedCount(), 5)
+
+	// When combining with nil
+	union, _ = f1.Union(nil)
+	intersection, _ = f1.Intersection(nil)
+	// Then
+	assert.Equal(t, f1.(*filter[int]).words, union.(*filter[int]).words)
+	assert.Equal(t, 0, intersection.EstimatedCount())
+
+	// and the operands remain unchanged
+	assert.False(t, f1.MayContain(70))
+	assert.True(t, f1.MayContain(10))
+}
+
+func TestShouldFailToCombineIncompatibleFilters(t *testing.T) {
+	// Given
+	f1, _ := New[int](100, 0.01)
+	f2, _ := New[int](1000, 0.01)
+
+	// When
+	union, err := f1.Union(f2)
+	// Then
+	assert.Nil(t, union)
+	assert.EqualError(t, err, "cannot calculate union of bloom filters with 959 bits and 7 hashes and with 9586 bits and 7 hashes")
+
+	// When
+	intersection, err := f1.Intersection(f2)
+	// Then
+	assert.Nil(t, intersection)
+	assert.EqualError(t, err, "cannot calculate intersection of bloom filters with 959 bits and 7 hashes and with 9586 bits and 7 hashes")
+}
+
+func TestShouldCreateFilterFromSet(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+	s.AddWithValue("apple", 1)
+	s.AddWithValue("banana", 2)
+
+	// When
+	f, err := FromSet(s, 0.001, nil)
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, f.MayContain("apple"))
+	assert.True(t, f.MayContain("banana"))
+	assert.False(t, f.MayContain("cherry"))
+
+	// When using a custom hash function
+	calls := 0
+	f, err = FromSet(s, 0.001, func(str string) uint64 {
+		calls++
+		return set.HashString(str)
+	})
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.True(t, f.MayContain("apple"))
+
+	// When
+	f, err = FromSet[string, int](nil, 0.01, nil)
+	// Then
+	assert.Nil(t, err)
+	assert.False(t, f.MayContain("apple"))
+
+	// When
+	f, err = FromSet(s, 2, nil)
+	// Then
+	assert.Nil(t, f)
+	assert.EqualError(t, err, "cannot create bloom filter, false-positive rate must be between 0 and 1 but is 2")
+}
+
+func BenchmarkFilterMayContain(b *testing.B) {
+	f, _ := New[int](100_000, 0.01)
+	for i := range 100_000 {
+		f.Add(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.MayContain(i)
+	}
+}
@@ -0,0 +1,200 @@
+package bloom
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// CountingFilter is a Bloom filter with a small counter instead of a single bit per position, so elements can be removed.
+// It needs eight times the memory of a Filter with the same false-positive rate.
+// Counters saturate at 255 and are never decremented afterwards, so a saturated position may cause false positives
+// but never false negatives.
+// Removing an element that has not been added may remove other elements (causing false negatives), so only remove added elements.
+type CountingFilter[T comparable] interface {
+	Add(T)
+	Remove(T) bool
+	MayContain(T) bool
+	Clear()
+
+	CounterCount() int
+	HashCount() int
+	EstimatedCount() int
+
+	Copy() CountingFilter[T]
+	Union(CountingFilter[T]) (CountingFilter[T], error)
+	Intersection(CountingFilter[T]) (CountingFilter[T], error)
+}
+
+type countingFilter[T comparable] struct {
+	counters  []uint8
+	hashCount int
+	hashFunc  set.HashFunc[T]
+}
+
+// NewCounting creates a new, empty counting Bloom filter sized for expectedCount elements with the given false-positive rate,
+// using set.DefaultHashFunc for hashing the elements.
+// An error is returned if expectedCount is not positive or falsePositiveRate is not between 0 and 1 (exclusive).
+func NewCounting[T comparable](expectedCount int, falsePositiveRate float64) (CountingFilter[T], error) {
+	return NewCountingWithHashFunc[T](expectedCount, falsePositiveRate, nil)
+}
+
+// NewCountingWithHashFunc creates a new, empty counting Bloom filter sized for expectedCount elements with the given
+// false-positive rate, using hashFunc for hashing the elements. If hashFunc is nil, set.DefaultHashFunc is used.
+// An error is returned if expectedCount is not positive or falsePositiveRate is not between 0 and 1 (exclusive).
+func NewCountingWithHashFunc[T comparable](expectedCount int, falsePositiveRate float64, hashFunc set.HashFunc[T]) (CountingFilter[T], error) {
+	counterCount, hashCount, err := optimalParameters(expectedCount, falsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+	return newCountingFilter(counterCount, hashCount, hashFunc), nil
+}
+
+// CountingFromSet creates a new counting Bloom filter containing all elements of the given set (ignoring the values),
+// sized for the size of the set with the given false-positive rate. If hashFunc is nil, set.DefaultHashFunc is used.
+// A nil set is treated as an empty set.
+// An error is returned if falsePositiveRate is not between 0 and 1 (exclusive).
+func CountingFromSet[T comparable, V any](s set.Set[T, V], falsePositiveRate float64, hashFunc set.HashFunc[T]) (CountingFilter[T], error) {
+	size := 0
+	if s != nil {
+		size = s.Size()
+	}
+	f, err := NewCountingWithHashFunc(max(size, 1), falsePositiveRate, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		for elem := range s.Elements() {
+			f.Add(elem)
+		}
+	}
+	return f, nil
+}
+
+func newCountingFilter[T comparable](counterCount uint64, hashCount int, hashFunc set.HashFunc[T]) *countingFilter[T] {
+	if hashFunc == nil {
+		hashFunc = set.DefaultHashFunc[T]()
+	}
+	return &countingFilter[T]{
+		counters:  make([]uint8, counterCount),
+		hashCount: hashCount,
+		hashFunc:  hashFunc,
+	}
+}
+
+func (f *countingFilter[T]) counterCount() uint64 {
+	return uint64(len(f.counters))
+}
+
+// Add adds an element to the filter.
+func (f *countingFilter[T]) Add(element T) {
+	indexes(f.hashFunc(element), f.hashCount, f.counterCount(), func(i uint64) bool {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]++
+		}
+		return true
+	})
+}
+
+// Remove removes an element from the filter and returns true.
+// If the element has definitely not been added, the filter remains unchanged and false is returned.
+func (f *countingFilter[T]) Remove(element T) bool {
+	if !f.MayContain(element) {
+		return false
+	}
+	indexes(f.hashFunc(element), f.hashCount, f.counterCount(), func(i uint64) bool {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]--
+		}
+		return true
+	})
+	return true
+}
+
+// MayContain returns false if the element has definitely not been added to the filter (or has been removed),
+// and true if it has probably been added.
+func (f *countingFilter[T]) MayContain(element T) bool {
+	return indexes(f.hashFunc(element), f.hashCount, f.counterCount(), func(i uint64) bool {
+		return f.counters[i] > 0
+	})
+}
+
+// Clear removes all elements from the filter.
+func (f *countingFilter[T]) Clear() {
+	clear(f.counters)
+}
+
+// CounterCount returns the number of counters of the filter.
+func (f *countingFilter[T]) CounterCount() int {
+	return len(f.counters)
+}
+
+// HashCount returns the number of hash functions (counters incremented per element) of the filter.
+func (f *countingFilter[T]) HashCount() int {
+	return f.hashCount
+}
+
+// EstimatedCount returns the estimated number of distinct elements in the filter,
+// calculated from the number of non-zero counters.
+func (f *countingFilter[T]) EstimatedCount() int {
+	nonZero := 0
+	for _, counter := range f.counters {
+		if counter > 0 {
+			nonZero++
+		}
+	}
+	return estimateCount(uint64(nonZero), f.counterCount(), f.hashCount)
+}
+
+// Copy returns a new filter containing all elements of this filter.
+func (f *countingFilter[T]) Copy() CountingFilter[T] {
+	newFilter := *f
+	newFilter.counters = append([]uint8(nil), f.counters...)
+	return &newFilter
+}
+
+// compatible returns the internal representation of other, or an error if it cannot be combined with this filter.
+// A nil other is treated as an empty filter.
+func (f *countingFilter[T]) compatible(other CountingFilter[T], operation string) (*countingFilter[T], error) {
+	if other == nil {
+		return newCountingFilter(f.counterCount(), f.hashCount, f.hashFunc), nil
+	}
+	o := other.(*countingFilter[T])
+	if len(f.counters) != len(o.counters) || f.hashCount != o.hashCount {
+		return nil, fmt.Errorf("cannot calculate %s of counting bloom filters with %d counters and %d hashes and with %d counters and %d hashes",
+			operation, len(f.counters), f.hashCount, len(o.counters), o.hashCount)
+	}
+	return o, nil
+}
+
+// Union returns a new filter containing all elements of both, this filter and other.
+// The counters are added, so an element added to both filters has to be removed twice.
+// A nil other is treated as an empty filter. An error is returned if the filters are not compatible.
+func (f *countingFilter[T]) Union(other CountingFilter[T]) (CountingFilter[T], error) {
+	o, err := f.compatible(other, "union")
+	if err != nil {
+		return nil, err
+	}
+	newFilter := f.Copy().(*countingFilter[T])
+	for i, counter := range o.counters {
+		newFilter.counters[i] = uint8(min(int(newFilter.counters[i])+int(counter), math.MaxUint8))
+	}
+	return newFilter, nil
+}
+
+// Intersection returns a new filter containing the elements being in both, this filter and other,
+// by taking the minimum of the counters.
+// The intersection may report more false positives than a filter created by adding only the common elements.
+// A nil other is treated as an empty filter. An error is returned if the filters are not compatible.
+func (f *countingFilter[T]) Intersection(other CountingFilter[T]) (CountingFilter[T], error) {
+	o, err := f.compatible(other, "intersection")
+	if err != nil {
+		return nil, err
+	}
+	newFilter := f.Copy().(*countingFilter[T])
+	for i, counter := range o.counters {
+		newFilter.counters[i] = min(newFilter.counters[i], counter)
+	}
+	return newFilter, nil
+}
@@ -0,0 +1,125 @@
+package bloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func TestShouldAddAndRemoveElementsOfCountingFilter(t *testing.T) {
+	// Given
+	f, _ := NewCounting[int](1000, 0.01)
+
+	// When
+	for i := range 1000 {
+		f.Add(i)
+	}
+	// Then
+	for i := range 1000 {
+		assert.True(t, f.MayContain(i))
+	}
+	assert.Equal(t, 9586, f.CounterCount())
+	assert.Equal(t, 7, f.HashCount())
+	assert.InDelta(t, 1000, f.EstimatedCount(), 50)
+
+	// When
+	for i := range 500 {
+		assert.True(t, f.Remove(i))
+	}
+	// Then the remaining elements are still contained
+	for i := 500; i < 1000; i++ {
+		assert.True(t, f.MayContain(i))
+	}
+	assert.InDelta(t, 500, f.EstimatedCount(), 25)
+
+	// When
+	removed := f.Remove(-1)
+	// Then
+	assert.False(t, removed)
+
+	// When
+	f.Clear()
+	// Then
+	assert.False(t, f.MayContain(999))
+	assert.Equal(t, 0, f.EstimatedCount())
+}
+
+func TestShouldKeepSaturatedCounters(t *testing.T) {
+	// Given
+	f, _ := NewCounting[string](10, 0.1)
+	for range 300 {
+		f.Add("hot")
+	}
+
+	// When
+	for range 300 {
+		f.Remove("hot")
+	}
+
+	// Then the saturated counters are never decremented
+	assert.True(t, f.MayContain("hot"))
+}
+
+func TestShouldUniteAndIntersectCountingFilters(t *testing.T) {
+	// Given
+	f1, _ := NewCounting[int](100, 0.01)
+	f2, _ := NewCounting[int](100, 0.01)
+	for i := range 50 {
+		f1.Add(i)
+		f2.Add(i + 25)
+	}
+
+	// When
+	union, err := f1.Union(f2)
+	// Then
+	assert.Nil(t, err)
+	assert.InDelta(t, 75, union.EstimatedCount(), 5)
+	// and elements added to both filters have to be removed twice
+	union.Remove(30)
+	assert.True(t, union.MayContain(30))
+	union.Remove(30)
+	assert.False(t, union.MayContain(30))
+
+	// When
+	intersection, err := f1.Intersection(f2)
+	// Then
+	assert.Nil(t, err)
+	for i := 25; i < 50; i++ {
+		assert.True(t, intersection.MayContain(i))
+	}
+	assert.InDelta(t, 25, intersection.EstimatedCount(), 5)
+
+	// When
+	f3, _ := NewCounting[int](10, 0.01)
+	union, err = f1.Union(f3)
+	// Then
+	assert.Nil(t, union)
+	assert.EqualError(t, err, "cannot calculate union of counting bloom filters with 959 counters and 7 hashes and with 96 counters and 7 hashes")
+
+	// and the operands remain unchanged
+	assert.True(t, f1.MayContain(30))
+	assert.False(t, f1.Copy().MayContain(60))
+}
+
+func TestShouldCreateCountingFilterFromSet(t *testing.T) {
+	// Given
+	s := set.NewWithoutValues[int]()
+	s.AddWithoutValue(1)
+	s.AddWithoutValue(2)
+
+	// When
+	f, err := CountingFromSet(s, 0.001, nil)
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, f.MayContain(1))
+	assert.True(t, f.MayContain(2))
+	assert.False(t, f.MayContain(3))
+
+	// When
+	f.Remove(1)
+	// Then
+	assert.False(t, f.MayContain(1))
+	assert.True(t, f.MayContain(2))
+}
@@ -0,0 +1,299 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func TestShouldWriteDataItemsInShortestForm(t *testing.T) {
+	// Given examples from RFC 8949, appendix A
+	for expected, write := range map[string]func(w *Writer) error{
+		"00":                 func(w *Writer) error { return w.WriteUint(0) },
+		"17":                 func(w *Writer) error { return w.WriteUint(23) },
+		"1818":               func(w *Writer) error { return w.WriteUint(24) },
+		"1903e8":             func(w *Writer) error { return w.WriteUint(1000) },
+		"1a000f4240":         func(w *Writer) error { return w.WriteInt(1_000_000) },
+		"1b000000e8d4a51000": func(w *Writer) error { return w.WriteUint(1_000_000_000_000) },
+		"20":                 func(w *Writer) error { return w.WriteInt(-1) },
+		"3903e7":             func(w *Writer) error { return w.WriteInt(-1000) },
+		"3b7fffffffffffffff": func(w *Writer) error { return w.WriteInt(math.MinInt64) },
+		"fa47c35000":         func(w *Writer) error { return w.WriteFloat(100_000) },
+		"fb3ff199999999999a": func(w *Writer) error { return w.WriteFloat(1.1) },
+		"f4":                 func(w *Writer) error { return w.WriteBool(false) },
+		"f5":                 func(w *Writer) error { return w.WriteBool(true) },
+		"f6":                 func(w *Writer) error { return w.WriteNull() },
+		"6449455446":         func(w *Writer) error { return w.WriteString("IETF") },
+		"4401020304":         func(w *Writer) error { return w.WriteBytes([]byte{1, 2, 3, 4}) },
+		"c11a514b67b0":       func(w *Writer) error { w.WriteTag(1); return w.WriteUint(1363896240) },
+		"a201020304": func(w *Writer) error {
+			w.WriteMapHeader(2)
+			w.WriteUint(1)
+			w.WriteUint(2)
+			w.WriteUint(3)
+			return w.WriteUint(4)
+		},
+	} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+
+		// When
+		err := write(w)
+		w.Flush()
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expected, hex.EncodeToString(buf.Bytes()))
+	}
+}
+
+func TestShouldReadDataItems(t *testing.T) {
+	// Given
+	r := reader("3b7fffffffffffffff" + "f93e00" + "f97c00" + "1903e8" + "7f657374726561646d696e67ff" + "5f42010243030405ff" +
+		"9f018202039f0405ffff" + "f5" + "f7")
+
+	// Expect
+	assertRead(t, int64(math.MinInt64), r.ReadInt)
+	assertRead(t, 1.5, r.ReadFloat)
+	assertRead(t, math.Inf(1), r.ReadFloat)
+	assertRead(t, 1000.0, r.ReadFloat)
+	assertRead(t, "streaming", r.ReadString)
+	assertRead(t, []byte{1, 2, 3, 4, 5}, r.ReadBytes)
+	assertRead(t, -1, r.ReadArrayHeader)
+	assert.Nil(t, r.Skip())
+	assert.Nil(t, r.Skip())
+	assert.Nil(t, r.Skip())
+	isBreak, err := r.IsBreak()
+	assert.Nil(t, err)
+	assert.True(t, isBreak)
+	assertRead(t, true, r.ReadBool)
+	isNull, err := r.IsNull()
+	assert.Nil(t, err)
+	assert.True(t, isNull)
+	assert.Equal(t, int64(52), r.Offset())
+}
+
+func TestShouldFailToReadUnexpectedDataItems(t *testing.T) {
+	// Expect
+	_, err := reader("6161").ReadInt()
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	assert.EqualError(t, err, "invalid CBOR data: expected integer but found text string at offset 0")
+	_, err = reader("1bffffffffffffffff").ReadInt()
+	assert.EqualError(t, err, "invalid CBOR data: integer at offset 0 overflows int64")
+	_, err = reader("f6").ReadString()
+	assert.EqualError(t, err, "invalid CBOR data: expected text string but found null at offset 0")
+	_, err = reader("1c").ReadUint()
+	assert.EqualError(t, err, "invalid CBOR data: reserved additional information 28 at offset 0")
+	_, err = reader("6461").ReadString()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = reader("1a0001").ReadUint()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.EqualError(t, reader("ff").Skip(), "invalid CBOR data: unexpected break at offset 0")
+	assert.EqualError(t, reader(string(bytes.Repeat([]byte("81"), 1002))+"00").Skip(), "invalid CBOR data: nesting deeper than 1000 at offset 1001")
+}
+
+func TestShouldEncodeSetWithoutValuesInAllForms(t *testing.T) {
+	// Given
+	s := set.NewSortedWithoutValues[int]()
+	s.AddWithoutValue(1)
+	s.AddWithoutValue(2)
+	s.AddWithoutValue(1000)
+
+	for form, expected := range map[Form]string{
+		TaggedSet: "d90102" + "83" + "01" + "02" + "1903e8",
+		Array:     "83" + "01" + "02" + "1903e8",
+		Map:       "a3" + "01f6" + "02f6" + "1903e8f6",
+	} {
+		// When
+		data, err := Marshal(s, form)
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expected, hex.EncodeToString(data))
+
+		// When
+		decoded := set.NewWithoutValues[int]()
+		err = Unmarshal(data, decoded)
+		// Then
+		assert.Nil(t, err)
+		assert.True(t, s.Equals(decoded))
+	}
+}
+
+func TestShouldRoundTripPrimitiveElementsAndValues(t *testing.T) {
+	// Given
+	type label string
+	labels := set.NewLinkedWithValues[label, []byte]()
+	labels.AddWithValue("new", []byte{1, 2})
+	labels.AddWithValue("", nil)
+	numbers := set.NewWithValues[int8, float32]()
+	numbers.AddWithValue(-128, 1.5)
+	numbers.AddWithValue(127, float32(math.Inf(-1)))
+	flags := set.NewWithValues[uint64, bool]()
+	flags.AddWithValue(math.MaxUint64, true)
+	flags.AddWithValue(0, false)
+
+	// When
+	labelsData, err1 := Marshal(labels, Map)
+	numbersData, err2 := Marshal(numbers, Map)
+	flagsData, err3 := Marshal(flags, Map)
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+
+	// When
+	decodedLabels := set.NewLinkedWithValues[label, []byte]()
+	decodedNumbers := set.NewWithValues[int8, float32]()
+	decodedFlags := set.NewWithValues[uint64, bool]()
+	err1 = Unmarshal(labelsData, decodedLabels)
+	err2 = Unmarshal(numbersData, decodedNumbers)
+	err3 = Unmarshal(flagsData, decodedFlags)
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Equal(t, labels.List(), decodedLabels.List())
+	assert.Equal(t, []byte{1, 2}, decodedLabels.GetElements()["new"])
+	assert.True(t, numbers.Equals(decodedNumbers))
+	assert.True(t, flags.Equals(decodedFlags))
+}
+
+func TestShouldEncodeStructsWithCustomCodec(t *testing.T) {
+	// Given a codec writing points as arrays of two integers
+	type point struct{ X, Y int }
+	codec := NewCodec(
+		func(w *Writer, p point) error {
+			w.WriteArrayHeader(2)
+			w.WriteInt(int64(p.X))
+			return w.WriteInt(int64(p.Y))
+		},
+		func(r *Reader) (point, error) {
+			if _, err := r.ReadArrayHeader(); err != nil {
+				return point{}, err
+			}
+			x, _ := r.ReadInt()
+			y, err := r.ReadInt()
+			return point{X: int(x), Y: int(y)}, err
+		})
+	s := set.NewWithoutValues[point]()
+	s.AddWithoutValue(point{X: 1, Y: -1})
+	var buf bytes.Buffer
+
+	// When
+	err := NewEncoder[point, set.InternalEmptyType](&buf, TaggedSet, codec, nil).Encode(s)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "d90102"+"81"+"82"+"01"+"20", hex.EncodeToString(buf.Bytes()))
+
+	// When
+	decoded := set.NewWithoutValues[point]()
+	err = NewDecoder[point, set.InternalEmptyType](&buf, codec, nil, set.StrictDuplicates).Decode(decoded)
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, s.Equals(decoded))
+
+	// When using the default codec
+	_, err = Marshal(s, Array)
+	// Then
+	assert.EqualError(t, err, "cannot encode set to CBOR: cannot encode cbor.point as CBOR, it needs a custom codec")
+}
+
+func TestShouldStreamSets(t *testing.T) {
+	// Given
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		encoder := NewEncoder[int, string](pipeWriter, Map, nil, nil)
+		large := set.NewWithValues[int, string]()
+		for i := range 100_000 {
+			large.AddWithValue(i, "v")
+		}
+		encoder.Encode(large)
+		encoder.Encode(nil)
+		// an indefinite-length map written by another encoder
+		pipeWriter.Write([]byte{0xbf, 0x01, 0x61, 'a', 0xff})
+		pipeWriter.Close()
+	}()
+	decoder := NewDecoder[int, string](pipeReader, nil, nil, set.StrictDuplicates)
+
+	// When
+	large := set.NewWithValues[int, string]()
+	err1 := decoder.Decode(large)
+	unchanged := set.NewWithValues[int, string]()
+	err2 := decoder.Decode(unchanged)
+	small := set.NewWithValues[int, string]()
+	err3 := decoder.Decode(small)
+	err4 := decoder.Decode(small)
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Equal(t, 100_000, large.Size())
+	assert.Nil(t, err2)
+	assert.Equal(t, 0, unchanged.Size())
+	assert.Nil(t, err3)
+	assert.Equal(t, "1 (a)", small.StringWithValues())
+	assert.EqualError(t, err4, "cannot decode set from CBOR: EOF")
+}
+
+func TestShouldFailToEncodeSetWithValuesAsArray(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+
+	// When
+	_, err := Marshal(s, TaggedSet)
+
+	// Then
+	assert.EqualError(t, err, "cannot encode set to CBOR: a set with values cannot be written as tagged set, use Map")
+}
+
+func TestShouldFailToDecodeInvalidSets(t *testing.T) {
+	for data, expected := range map[string]string{
+		"d90103" + "80":    "cannot decode set from CBOR: invalid CBOR data: unsupported tag 259 at offset 0",
+		"d90102" + "a0":    "cannot decode set from CBOR: invalid CBOR data: expected array but found map at offset 3",
+		"6161":             "cannot decode set from CBOR: invalid CBOR data: expected array or map but found text string at offset 0",
+		"82" + "6161":      "cannot decode set from CBOR: invalid CBOR data: expected integer but found text string at offset 1",
+		"82" + "01":        "cannot decode set from CBOR: unexpected EOF",
+		"9f" + "01":        "cannot decode set from CBOR: unexpected EOF",
+		"83" + "010201":    "cannot decode set from CBOR: duplicate element 1 at index 2 (offset 3)",
+		"81" + "1901f4":    "cannot decode set from CBOR: invalid CBOR data: integer 500 at offset 1 overflows int8",
+		"a1" + "01" + "02": "",
+	} {
+		// Given
+		s := set.NewWithoutValues[int8]()
+
+		// When
+		err := NewDecoder[int8, set.InternalEmptyType](bytes.NewReader(fromHex(data)), nil, nil, set.StrictDuplicates).Decode(s)
+
+		// Then
+		if expected == "" {
+			// values of a map are skipped for sets without values
+			assert.Nil(t, err)
+			assert.Equal(t, []int8{1}, s.List())
+		} else {
+			assert.EqualError(t, err, expected)
+		}
+	}
+}
+
+func fromHex(hexData string) []byte {
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func reader(hexData string) *Reader {
+	return NewReader(bytes.NewReader(fromHex(hexData)))
+}
+
+func assertRead[E any](t *testing.T, expected E, read func() (E, error)) {
+	actual, err := read()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
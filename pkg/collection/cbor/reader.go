@@ -0,0 +1,375 @@
+package cbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrInvalidFormat is returned (wrapped) when reading data that is not valid CBOR or does not have the expected type.
+var ErrInvalidFormat = errors.New("invalid CBOR data")
+
+// maxNesting is the maximum depth of nested arrays, maps and tags that Skip accepts.
+const maxNesting = 1000
+
+// Reader reads single CBOR data items from an io.Reader.
+// Definite and indefinite lengths are accepted, as are all integer and floating-point encodings.
+type Reader struct {
+	r       *bufio.Reader
+	offset  int64
+	scratch [8]byte
+}
+
+// header is the initial byte of a data item together with its argument.
+type header struct {
+	major  byte
+	info   byte
+	arg    uint64
+	offset int64
+}
+
+// NewReader creates a new reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Offset returns the number of bytes read so far.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+func (r *Reader) readByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.offset++
+	}
+	return b, err
+}
+
+func (r *Reader) peekByte() (byte, error) {
+	b, err := r.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *Reader) readHeader() (header, error) {
+	start := r.offset
+	b, err := r.readByte()
+	if err != nil {
+		return header{}, err
+	}
+	h := header{major: b >> 5, info: b & 0x1f, offset: start}
+	switch {
+	case h.info < 24:
+		h.arg = uint64(h.info)
+	case h.info <= 27:
+		n := 1 << (h.info - 24)
+		buf := r.scratch[8-n:]
+		read, err := io.ReadFull(r.r, buf)
+		r.offset += int64(read)
+		if err != nil {
+			return header{}, unexpectedEOF(err)
+		}
+		clear(r.scratch[:8-n])
+		h.arg = binary.BigEndian.Uint64(r.scratch[:])
+	case h.info == 31:
+		if h.major == majorUint || h.major == majorNegInt || h.major == majorTag {
+			return header{}, fmt.Errorf("%w: indefinite length not allowed for %s at offset %d", ErrInvalidFormat, majorNames[h.major], start)
+		}
+	default:
+		return header{}, fmt.Errorf("%w: reserved additional information %d at offset %d", ErrInvalidFormat, h.info, start)
+	}
+	return h, nil
+}
+
+var majorNames = [...]string{"unsigned integer", "negative integer", "byte string", "text string", "array", "map", "tag", "simple value"}
+
+// describe returns a human-readable description of the type of the data item, used in errors.
+func (h header) describe() string {
+	if h.major != majorSimple {
+		return majorNames[h.major]
+	}
+	switch b := h.major<<5 | h.info; b {
+	case simpleFalse, simpleTrue:
+		return "boolean"
+	case simpleNull:
+		return "null"
+	case simpleFloat16, simpleFloat32, simpleFloat64:
+		return "float"
+	case breakCode:
+		return "break"
+	default:
+		return "simple value"
+	}
+}
+
+func (h header) mismatch(expected string) error {
+	return fmt.Errorf("%w: expected %s but found %s at offset %d", ErrInvalidFormat, expected, h.describe(), h.offset)
+}
+
+func (r *Reader) expect(major byte) (header, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return h, err
+	}
+	if h.major != major {
+		return h, h.mismatch(majorNames[major])
+	}
+	return h, nil
+}
+
+// ReadUint reads an unsigned integer.
+func (r *Reader) ReadUint() (uint64, error) {
+	h, err := r.expect(majorUint)
+	return h.arg, err
+}
+
+// ReadInt reads a signed integer, which may be encoded as unsigned or negative integer.
+func (r *Reader) ReadInt() (int64, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	if h.major != majorUint && h.major != majorNegInt {
+		return 0, h.mismatch("integer")
+	}
+	if h.arg > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: integer at offset %d overflows int64", ErrInvalidFormat, h.offset)
+	}
+	if h.major == majorNegInt {
+		return -1 - int64(h.arg), nil
+	}
+	return int64(h.arg), nil
+}
+
+// ReadFloat reads a floating-point number of half, single or double precision, or an integer.
+func (r *Reader) ReadFloat() (float64, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case h.major == majorUint:
+		return float64(h.arg), nil
+	case h.major == majorNegInt:
+		return -1 - float64(h.arg), nil
+	case h.major == majorSimple && h.info == 25:
+		return halfToFloat(uint16(h.arg)), nil
+	case h.major == majorSimple && h.info == 26:
+		return float64(math.Float32frombits(uint32(h.arg))), nil
+	case h.major == majorSimple && h.info == 27:
+		return math.Float64frombits(h.arg), nil
+	default:
+		return 0, h.mismatch("float")
+	}
+}
+
+// halfToFloat converts an IEEE 754 half-precision number to float64.
+func halfToFloat(half uint16) float64 {
+	exp := int(half>>10) & 0x1f
+	mant := float64(half & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if half&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// ReadBool reads a boolean.
+func (r *Reader) ReadBool() (bool, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return false, err
+	}
+	if h.major != majorSimple || (h.info != simpleFalse&0x1f && h.info != simpleTrue&0x1f) {
+		return false, h.mismatch("boolean")
+	}
+	return h.info == simpleTrue&0x1f, nil
+}
+
+// IsNull reports whether the next data item is null (or undefined), and skips it if so.
+func (r *Reader) IsNull() (bool, error) {
+	return r.skipIf(func(b byte) bool { return b == simpleNull || b == simpleNull+1 })
+}
+
+// IsBreak reports whether the next byte is the break code ending an indefinite-length array or map, and skips it if so.
+func (r *Reader) IsBreak() (bool, error) {
+	return r.skipIf(func(b byte) bool { return b == breakCode })
+}
+
+func (r *Reader) skipIf(matches func(byte) bool) (bool, error) {
+	b, err := r.peekByte()
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+	if !matches(b) {
+		return false, nil
+	}
+	_, err = r.readByte()
+	return true, err
+}
+
+// ReadString reads a text string.
+func (r *Reader) ReadString() (string, error) {
+	data, err := r.readChunked(majorText)
+	return string(data), err
+}
+
+// ReadBytes reads a byte string.
+func (r *Reader) ReadBytes() ([]byte, error) {
+	return r.readChunked(majorBytes)
+}
+
+func (r *Reader) readChunked(major byte) ([]byte, error) {
+	h, err := r.expect(major)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if h.info != 31 {
+		return r.readN(&buf, h.arg)
+	}
+	for {
+		if isBreak, err := r.IsBreak(); err != nil || isBreak {
+			return buf.Bytes(), err
+		}
+		chunk, err := r.expect(major)
+		if err != nil {
+			return nil, err
+		}
+		if chunk.info == 31 {
+			return nil, fmt.Errorf("%w: nested indefinite-length %s at offset %d", ErrInvalidFormat, majorNames[major], chunk.offset)
+		}
+		if _, err := r.readN(&buf, chunk.arg); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readN appends n bytes to buf, growing it while reading, so a corrupt length cannot allocate huge amounts of memory.
+func (r *Reader) readN(buf *bytes.Buffer, n uint64) ([]byte, error) {
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: length %d at offset %d too large", ErrInvalidFormat, n, r.offset)
+	}
+	read, err := io.CopyN(buf, r.r, int64(n))
+	r.offset += read
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadArrayHeader reads the header of an array and returns its number of items, or -1 if the array has an indefinite length.
+// Then the items end with a break, see IsBreak.
+func (r *Reader) ReadArrayHeader() (int, error) {
+	return r.readContainerHeader(majorArray)
+}
+
+// ReadMapHeader reads the header of a map and returns its number of pairs, or -1 if the map has an indefinite length.
+// Then the pairs end with a break, see IsBreak.
+func (r *Reader) ReadMapHeader() (int, error) {
+	return r.readContainerHeader(majorMap)
+}
+
+func (r *Reader) readContainerHeader(major byte) (int, error) {
+	h, err := r.expect(major)
+	if err != nil {
+		return 0, err
+	}
+	if h.info == 31 {
+		return -1, nil
+	}
+	if h.arg > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: length %d at offset %d too large", ErrInvalidFormat, h.arg, h.offset)
+	}
+	return int(h.arg), nil
+}
+
+// ReadTag reads a tag, which applies to the data item read next.
+func (r *Reader) ReadTag() (uint64, error) {
+	h, err := r.expect(majorTag)
+	return h.arg, err
+}
+
+// Skip skips the next data item including all nested data items.
+func (r *Reader) Skip() error {
+	return r.skip(0)
+}
+
+func (r *Reader) skip(depth int) error {
+	if depth > maxNesting {
+		return fmt.Errorf("%w: nesting deeper than %d at offset %d", ErrInvalidFormat, maxNesting, r.offset)
+	}
+	h, err := r.readHeader()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	switch h.major {
+	case majorBytes, majorText:
+		if h.info != 31 {
+			_, err = r.readN(&bytes.Buffer{}, h.arg)
+			return err
+		}
+		for {
+			if isBreak, err := r.IsBreak(); err != nil || isBreak {
+				return err
+			}
+			if err := r.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+	case majorArray, majorMap:
+		items := h.arg
+		if h.major == majorMap {
+			items *= 2
+		}
+		if h.info == 31 {
+			for {
+				if isBreak, err := r.IsBreak(); err != nil || isBreak {
+					return err
+				}
+				if err := r.skip(depth + 1); err != nil {
+					return err
+				}
+			}
+		}
+		for range items {
+			if err := r.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+	case majorTag:
+		return r.skip(depth + 1)
+	case majorSimple:
+		if h.info == 31 {
+			return fmt.Errorf("%w: unexpected break at offset %d", ErrInvalidFormat, h.offset)
+		}
+	}
+	return nil
+}
+
+// unexpectedEOF converts io.EOF to io.ErrUnexpectedEOF, used when the end of the input is reached within a data item.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
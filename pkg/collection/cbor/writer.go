@@ -0,0 +1,137 @@
+package cbor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Major types of CBOR data items (RFC 8949, section 3.1).
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Simple values and special bytes.
+const (
+	simpleFalse   = 0xf4
+	simpleTrue    = 0xf5
+	simpleNull    = 0xf6
+	simpleFloat16 = 0xf9
+	simpleFloat32 = 0xfa
+	simpleFloat64 = 0xfb
+	breakCode     = 0xff
+)
+
+// Writer writes single CBOR data items to an io.Writer in their shortest form.
+// The output is buffered, call Flush after writing.
+type Writer struct {
+	w       *bufio.Writer
+	scratch [9]byte
+}
+
+// NewWriter creates a new writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) writeHeader(major byte, n uint64) error {
+	b := w.scratch[:0]
+	switch {
+	case n < 24:
+		b = append(b, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		b = binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	default:
+		b = binary.BigEndian.AppendUint64(append(b, major<<5|27), n)
+	}
+	_, err := w.w.Write(b)
+	return err
+}
+
+// WriteUint writes an unsigned integer.
+func (w *Writer) WriteUint(n uint64) error {
+	return w.writeHeader(majorUint, n)
+}
+
+// WriteInt writes a signed integer.
+func (w *Writer) WriteInt(n int64) error {
+	if n < 0 {
+		return w.writeHeader(majorNegInt, ^uint64(n))
+	}
+	return w.writeHeader(majorUint, uint64(n))
+}
+
+// WriteFloat writes a floating-point number, as single precision if that is lossless and as double precision otherwise.
+func (w *Writer) WriteFloat(f float64) error {
+	b := w.scratch[:0]
+	if f32 := float32(f); float64(f32) == f {
+		b = binary.BigEndian.AppendUint32(append(b, simpleFloat32), math.Float32bits(f32))
+	} else {
+		b = binary.BigEndian.AppendUint64(append(b, simpleFloat64), math.Float64bits(f))
+	}
+	_, err := w.w.Write(b)
+	return err
+}
+
+// WriteBool writes a boolean.
+func (w *Writer) WriteBool(b bool) error {
+	if b {
+		return w.w.WriteByte(simpleTrue)
+	}
+	return w.w.WriteByte(simpleFalse)
+}
+
+// WriteNull writes null.
+func (w *Writer) WriteNull() error {
+	return w.w.WriteByte(simpleNull)
+}
+
+// WriteString writes a text string.
+func (w *Writer) WriteString(s string) error {
+	if err := w.writeHeader(majorText, uint64(len(s))); err != nil {
+		return err
+	}
+	_, err := w.w.WriteString(s)
+	return err
+}
+
+// WriteBytes writes a byte string.
+func (w *Writer) WriteBytes(b []byte) error {
+	if err := w.writeHeader(majorBytes, uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := w.w.Write(b)
+	return err
+}
+
+// WriteArrayHeader writes the header of an array with n items, which have to be written next.
+func (w *Writer) WriteArrayHeader(n int) error {
+	return w.writeHeader(majorArray, uint64(n))
+}
+
+// WriteMapHeader writes the header of a map with n pairs, whose keys and values have to be written next (alternately).
+func (w *Writer) WriteMapHeader(n int) error {
+	return w.writeHeader(majorMap, uint64(n))
+}
+
+// WriteTag writes a tag, which applies to the data item written next.
+func (w *Writer) WriteTag(tag uint64) error {
+	return w.writeHeader(majorTag, tag)
+}
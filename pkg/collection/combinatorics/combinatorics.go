@@ -0,0 +1,260 @@
+// Lazy iterators over the subsets, partitions and permutations of the elements of sets.
+//
+// The iterators of this package collect the elements of the set when iteration starts and produce their results
+// in a stable order if T is an ordered type: elements are sorted, and results are in lexicographic order
+// of the sorted elements. Otherwise, the elements are taken in the iteration order of the set.
+// The number of results grows exponentially (or faster) with the size of the set, so check it with the Count functions
+// or restrict it with Limit before iterating over large sets. All iterators stop as soon as the loop body breaks.
+package combinatorics
+
+import (
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/order"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// PowerSet returns an iterator over all subsets of the set (ignoring the values), from the empty set to the set itself.
+// Subsets are ordered by their size, subsets of the same size like Subsets. There are 2^n subsets of a set of size n.
+func PowerSet[T comparable, V any](s set.Set[T, V]) iter.Seq[set.Set[T, set.InternalEmptyType]] {
+	return func(yield func(set.Set[T, set.InternalEmptyType]) bool) {
+		elements := orderedElements(s)
+		for k := 0; k <= len(elements); k++ {
+			if !combinations(len(elements), k, func(indices []int) bool { return yield(subset(elements, indices)) }) {
+				return
+			}
+		}
+	}
+}
+
+// Subsets returns an iterator over all subsets of the set having k elements (ignoring the values), in lexicographic order.
+// Nothing is yielded if k is negative or greater than the size of the set. There are n choose k subsets of a set of size n.
+func Subsets[T comparable, V any](s set.Set[T, V], k int) iter.Seq[set.Set[T, set.InternalEmptyType]] {
+	return func(yield func(set.Set[T, set.InternalEmptyType]) bool) {
+		elements := orderedElements(s)
+		combinations(len(elements), k, func(indices []int) bool { return yield(subset(elements, indices)) })
+	}
+}
+
+// Partitions returns an iterator over all partitions of the set (ignoring the values) into non-empty, disjoint subsets
+// (blocks). The blocks of a partition are ordered by their first elements, starting with the partition having a single block.
+// The empty set has a single partition without blocks. There are Bell(n) partitions of a set of size n.
+func Partitions[T comparable, V any](s set.Set[T, V]) iter.Seq[[]set.Set[T, set.InternalEmptyType]] {
+	return func(yield func([]set.Set[T, set.InternalEmptyType]) bool) {
+		elements := orderedElements(s)
+		n := len(elements)
+		// blocks is a restricted growth string: blocks[0] is 0, and each blocks[i] is at most 1 + max(blocks[:i])
+		blocks := make([]int, n)
+		for {
+			if !yield(partition(elements, blocks)) {
+				return
+			}
+			i := n - 1
+			for i > 0 && blocks[i] > slices.Max(blocks[:i]) {
+				i--
+			}
+			if i <= 0 {
+				return
+			}
+			blocks[i]++
+			clear(blocks[i+1:])
+		}
+	}
+}
+
+// Permutations returns an iterator over all orderings of the elements of the set, in lexicographic order.
+// Each permutation is a new slice, which may be kept and changed by the caller. The empty set has a single, empty permutation.
+// There are n! permutations of a set of size n.
+func Permutations[T comparable, V any](s set.Set[T, V]) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		elements := orderedElements(s)
+		n := len(elements)
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		for {
+			permutation := make([]T, n)
+			for i, index := range indices {
+				permutation[i] = elements[index]
+			}
+			if !yield(permutation) {
+				return
+			}
+			// find the longest non-increasing suffix, swap its predecessor with its next greater index and reverse the suffix
+			i := n - 2
+			for i >= 0 && indices[i] > indices[i+1] {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			j := n - 1
+			for indices[j] < indices[i] {
+				j--
+			}
+			indices[i], indices[j] = indices[j], indices[i]
+			slices.Reverse(indices[i+1:])
+		}
+	}
+}
+
+// Limit returns an iterator over the first n values of seq. It stops seq after the n-th value, so it can restrict
+// the (possibly huge) number of results of the other iterators. Nothing is yielded if n is not positive.
+func Limit[E any](seq iter.Seq[E], n int) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		if n <= 0 {
+			return
+		}
+		count := 0
+		for value := range seq {
+			if !yield(value) {
+				return
+			}
+			if count++; count == n {
+				return
+			}
+		}
+	}
+}
+
+// CountPowerSet returns the number of subsets yielded by PowerSet, or false if it exceeds the maximum int value.
+func CountPowerSet[T comparable, V any](s set.Set[T, V]) (int, bool) {
+	n := size(s)
+	if n >= bits.UintSize-1 {
+		return 0, false
+	}
+	return 1 << n, true
+}
+
+// CountSubsets returns the number of subsets yielded by Subsets, or false if it exceeds the maximum int value.
+func CountSubsets[T comparable, V any](s set.Set[T, V], k int) (int, bool) {
+	n := size(s)
+	if k < 0 || k > n {
+		return 0, true
+	}
+	k = min(k, n-k)
+	count := uint64(1)
+	for i := range k {
+		// count * (n-i) is divisible by i+1, since it is (i+1) times n choose i+1
+		hi, lo := bits.Mul64(count, uint64(n-i))
+		if hi >= uint64(i+1) {
+			return 0, false
+		}
+		count, _ = bits.Div64(hi, lo, uint64(i+1))
+		if count > math.MaxInt {
+			return 0, false
+		}
+	}
+	return int(count), true
+}
+
+// CountPartitions returns the number of partitions yielded by Partitions (the Bell number of the size of the set),
+// or false if it exceeds the maximum int value.
+func CountPartitions[T comparable, V any](s set.Set[T, V]) (int, bool) {
+	n := size(s)
+	if n == 0 {
+		return 1, true
+	}
+	// row i of the Bell triangle starts with Bell(i), which is the last number of the previous row, and ends with Bell(i+1)
+	row := []int{1}
+	for range n - 1 {
+		next := make([]int, len(row)+1)
+		next[0] = row[len(row)-1]
+		for i, above := range row {
+			if next[i] > math.MaxInt-above {
+				return 0, false
+			}
+			next[i+1] = next[i] + above
+		}
+		row = next
+	}
+	return row[len(row)-1], true
+}
+
+// CountPermutations returns the number of permutations yielded by Permutations (the factorial of the size of the set),
+// or false if it exceeds the maximum int value.
+func CountPermutations[T comparable, V any](s set.Set[T, V]) (int, bool) {
+	count := uint64(1)
+	for i := 2; i <= size(s); i++ {
+		hi, lo := bits.Mul64(count, uint64(i))
+		if hi != 0 || lo > math.MaxInt {
+			return 0, false
+		}
+		count = lo
+	}
+	return int(count), true
+}
+
+// size returns the size of the set, treating a nil set as empty.
+func size[T comparable, V any](s set.Set[T, V]) int {
+	if s == nil {
+		return 0
+	}
+	return s.Size()
+}
+
+// orderedElements returns the elements of the set, sorted if T is an ordered type. A nil set is treated as empty.
+func orderedElements[T comparable, V any](s set.Set[T, V]) []T {
+	if s == nil {
+		return nil
+	}
+	elements := slices.Collect(s.Elements())
+	if compare := order.Compare[T](); compare != nil {
+		slices.SortFunc(elements, compare)
+	}
+	return elements
+}
+
+// combinations calls yield with the indices of each k-element subset of n elements in lexicographic order,
+// until yield returns false. It returns false if yield returned false. The indices must not be kept by yield.
+func combinations(n, k int, yield func([]int) bool) bool {
+	if k < 0 || k > n {
+		return true
+	}
+	indices := make([]int, k)
+	for i := range indices {
+		indices[i] = i
+	}
+	for {
+		if !yield(indices) {
+			return false
+		}
+		// find the last index which can be increased and reset the following indices
+		i := k - 1
+		for i >= 0 && indices[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return true
+		}
+		indices[i]++
+		for j := i + 1; j < k; j++ {
+			indices[j] = indices[j-1] + 1
+		}
+	}
+}
+
+// subset returns a new set containing the elements with the given indices, iterating over them in this order.
+func subset[T comparable](elements []T, indices []int) set.Set[T, set.InternalEmptyType] {
+	s := set.NewLinkedWithoutValues[T]()
+	for _, i := range indices {
+		s.AddWithoutValue(elements[i])
+	}
+	return s
+}
+
+// partition returns new sets for the blocks of the partition, where blocks[i] is the number of the block of elements[i].
+func partition[T comparable](elements []T, blocks []int) []set.Set[T, set.InternalEmptyType] {
+	var result []set.Set[T, set.InternalEmptyType]
+	for i, block := range blocks {
+		if block == len(result) {
+			result = append(result, set.NewLinkedWithoutValues[T]())
+		}
+		result[block].AddWithoutValue(elements[i])
+	}
+	return result
+}
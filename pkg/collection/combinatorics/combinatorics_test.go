@@ -0,0 +1,174 @@
+package combinatorics
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// elements returns the elements of the subset in its iteration order.
+func elements[T comparable](s set.Set[T, set.InternalEmptyType]) []T {
+	return slices.Collect(s.Elements())
+}
+
+func TestShouldIteratePowerSetBySizeAndLexicographically(t *testing.T) {
+	// Given
+	s := set.Collect(slices.Values([]string{"c", "a", "b"}))
+
+	// When
+	var subsets [][]string
+	for subset := range PowerSet(s) {
+		subsets = append(subsets, elements(subset))
+	}
+
+	// Then
+	assert.Equal(t, [][]string{nil, {"a"}, {"b"}, {"c"}, {"a", "b"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"}}, subsets)
+	count, ok := CountPowerSet(s)
+	assert.True(t, ok)
+	assert.Equal(t, len(subsets), count)
+}
+
+func TestShouldIterateSubsetsOfGivenSize(t *testing.T) {
+	// Given
+	s := set.Collect(slices.Values([]int{4, 1, 3, 2}))
+
+	// When
+	var subsets [][]int
+	for subset := range Subsets(s, 2) {
+		subsets = append(subsets, elements(subset))
+	}
+
+	// Then
+	assert.Equal(t, [][]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}, subsets)
+	count, _ := CountSubsets(s, 2)
+	assert.Equal(t, 6, count)
+	assert.Equal(t, 1, len(slices.Collect(Subsets(s, 0))))
+	assert.Equal(t, 1, len(slices.Collect(Subsets(s, 4))))
+	assert.Empty(t, slices.Collect(Subsets(s, 5)))
+	assert.Empty(t, slices.Collect(Subsets(s, -1)))
+}
+
+func TestShouldIteratePartitions(t *testing.T) {
+	// Given
+	s := set.Collect(slices.Values([]string{"a", "b", "c"}))
+
+	// When
+	var partitions [][][]string
+	for partition := range Partitions(s) {
+		blocks := make([][]string, len(partition))
+		for i, block := range partition {
+			blocks[i] = elements(block)
+		}
+		partitions = append(partitions, blocks)
+	}
+
+	// Then
+	assert.Equal(t, [][][]string{
+		{{"a", "b", "c"}},
+		{{"a", "b"}, {"c"}},
+		{{"a", "c"}, {"b"}},
+		{{"a"}, {"b", "c"}},
+		{{"a"}, {"b"}, {"c"}},
+	}, partitions)
+}
+
+func TestShouldIteratePermutationsLexicographically(t *testing.T) {
+	// Given
+	s := set.Collect(slices.Values([]int{3, 1, 2}))
+
+	// When
+	permutations := slices.Collect(Permutations(s))
+
+	// Then
+	assert.Equal(t, [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}}, permutations)
+}
+
+func TestShouldHandleEmptyAndNilSets(t *testing.T) {
+	// Given
+	empty := set.NewWithoutValues[int]()
+
+	// Expect
+	assert.Equal(t, 1, len(slices.Collect(PowerSet(empty))))
+	assert.Equal(t, [][]int{{}}, slices.Collect(Permutations[int, set.InternalEmptyType](nil)))
+	assert.Equal(t, 1, len(slices.Collect(Partitions(empty))))
+	assert.Empty(t, slices.Collect(Partitions(empty))[0])
+	count, ok := CountPartitions(empty)
+	assert.True(t, ok)
+	assert.Equal(t, 1, count)
+}
+
+func TestShouldStopEarly(t *testing.T) {
+	// Given
+	s := set.Collect(slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+
+	// When
+	var first []int
+	for permutation := range Permutations(s) {
+		first = permutation
+		break
+	}
+	limited := slices.Collect(Limit(PowerSet(s), 3))
+	partitions := 0
+	for range Partitions(s) {
+		if partitions++; partitions == 5 {
+			break
+		}
+	}
+
+	// Then
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, first)
+	assert.Len(t, limited, 3)
+	assert.Equal(t, []int{1}, elements(limited[1]))
+	assert.Equal(t, 5, partitions)
+	assert.Empty(t, slices.Collect(Limit(PowerSet(s), 0)))
+	assert.Len(t, slices.Collect(Limit(Subsets(s, 9), 100)), 10)
+}
+
+func TestShouldCountResults(t *testing.T) {
+	// Given
+	ofSize := func(n int) set.Set[int, set.InternalEmptyType] {
+		s := set.NewWithoutValues[int]()
+		for i := range n {
+			s.AddWithoutValue(i)
+		}
+		return s
+	}
+	tests := []struct {
+		name     string
+		count    func() (int, bool)
+		expected int
+		ok       bool
+	}{
+		{"power set of 0", func() (int, bool) { return CountPowerSet(ofSize(0)) }, 1, true},
+		{"power set of 10", func() (int, bool) { return CountPowerSet(ofSize(10)) }, 1024, true},
+		{"power set of 62", func() (int, bool) { return CountPowerSet(ofSize(62)) }, 1 << 62, true},
+		{"power set of 63", func() (int, bool) { return CountPowerSet(ofSize(63)) }, 0, false},
+		{"10 choose 4", func() (int, bool) { return CountSubsets(ofSize(10), 4) }, 210, true},
+		{"20 choose 10", func() (int, bool) { return CountSubsets(ofSize(20), 10) }, 184756, true},
+		{"66 choose 33", func() (int, bool) { return CountSubsets(ofSize(66), 33) }, 7219428434016265740, true},
+		{"100 choose 99", func() (int, bool) { return CountSubsets(ofSize(100), 99) }, 100, true},
+		{"100 choose 50", func() (int, bool) { return CountSubsets(ofSize(100), 50) }, 0, false},
+		{"Bell(1)", func() (int, bool) { return CountPartitions(ofSize(1)) }, 1, true},
+		{"Bell(4)", func() (int, bool) { return CountPartitions(ofSize(4)) }, 15, true},
+		{"Bell(10)", func() (int, bool) { return CountPartitions(ofSize(10)) }, 115975, true},
+		{"Bell(25)", func() (int, bool) { return CountPartitions(ofSize(25)) }, 4638590332229999353, true},
+		{"Bell(26)", func() (int, bool) { return CountPartitions(ofSize(26)) }, 0, false},
+		{"4!", func() (int, bool) { return CountPermutations(ofSize(4)) }, 24, true},
+		{"20!", func() (int, bool) { return CountPermutations(ofSize(20)) }, 2432902008176640000, true},
+		{"21!", func() (int, bool) { return CountPermutations(ofSize(21)) }, 0, false},
+		{"permutations of nil", func() (int, bool) { return CountPermutations[int, set.InternalEmptyType](nil) }, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			count, ok := tt.count()
+
+			// Then
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// A probabilistic membership filter supporting deletion (cuckoo filter).
+package cuckoo
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+const (
+	// bucketSize is the number of fingerprints per bucket.
+	bucketSize = 4
+	// maxKicks is the number of fingerprints relocated before an insertion fails.
+	maxKicks = 500
+	// maxLoadFactor is the load factor up to which insertions succeed with high probability for buckets of size 4.
+	maxLoadFactor = 0.95
+)
+
+// ErrFull is returned (wrapped) by Insert if there is no room for another fingerprint.
+var ErrFull = errors.New("cuckoo filter is full")
+
+// Filter is a cuckoo filter: a compact, probabilistic set of elements of type T storing a 16-bit fingerprint per element
+// in one of two candidate buckets. Lookup never reports false negatives for inserted elements, and reports false positives
+// with a probability of at most 2*4/(2^16-1) (about 0.012%), needing about 16.8 bits per element at a load factor of 95%.
+// A Bloom filter needs about 19 bits per element for the same false-positive rate.
+//
+// Unlike a Bloom filter, a cuckoo filter supports Delete without counters:
+//   - Inserting an element n times stores n fingerprints, so it has to be deleted n times until Lookup returns false.
+//   - After an element has been deleted, Lookup may still return true if another inserted element has the same fingerprint
+//     in the same buckets (a false positive, as for elements never inserted).
+//   - Deleting an element that has never been inserted may delete the fingerprint of another element with the same fingerprint,
+//     causing a false negative for that element. So only delete elements that have been inserted.
+type Filter[T comparable] interface {
+	Insert(T) error
+	Lookup(T) bool
+	Delete(T) bool
+	Count() int
+	Capacity() int
+	Clear()
+
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+type filter[T comparable] struct {
+	// fingerprints holds bucketSize fingerprints per bucket, 0 marks an empty slot
+	fingerprints []uint16
+	count        int
+	hashFunc     set.HashFunc[T]
+}
+
+// New creates a new, empty cuckoo filter with room for at least capacity elements,
+// using set.DefaultHashFunc for hashing the elements.
+// An error is returned if capacity is not positive.
+func New[T comparable](capacity int) (Filter[T], error) {
+	return NewWithHashFunc[T](capacity, nil)
+}
+
+// NewWithHashFunc creates a new, empty cuckoo filter with room for at least capacity elements,
+// using hashFunc for hashing the elements. If hashFunc is nil, set.DefaultHashFunc is used.
+// An error is returned if capacity is not positive.
+func NewWithHashFunc[T comparable](capacity int, hashFunc set.HashFunc[T]) (Filter[T], error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("cannot create cuckoo filter, capacity must be positive but is %d", capacity)
+	}
+	// the number of buckets must be a power of two, so the alternate bucket of the alternate bucket is the original one
+	bucketCount := 1 << bits.Len(uint(capacity-1)/bucketSize)
+	if float64(capacity)/float64(bucketCount*bucketSize) > maxLoadFactor {
+		bucketCount *= 2
+	}
+	return newFilter(bucketCount, hashFunc), nil
+}
+
+// FromSet creates a new cuckoo filter containing all elements of the given set (ignoring the values),
+// with room for the elements of the set. If hashFunc is nil, set.DefaultHashFunc is used.
+// A nil set is treated as an empty set.
+func FromSet[T comparable, V any](s set.Set[T, V], hashFunc set.HashFunc[T]) (Filter[T], error) {
+	size := 0
+	if s != nil {
+		size = s.Size()
+	}
+	f, err := NewWithHashFunc(max(size, 1), hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		for elem := range s.Elements() {
+			if err := f.Insert(elem); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+func newFilter[T comparable](bucketCount int, hashFunc set.HashFunc[T]) *filter[T] {
+	if hashFunc == nil {
+		hashFunc = set.DefaultHashFunc[T]()
+	}
+	return &filter[T]{fingerprints: make([]uint16, bucketCount*bucketSize), hashFunc: hashFunc}
+}
+
+func (f *filter[T]) bucketMask() uint64 {
+	return uint64(len(f.fingerprints)/bucketSize - 1)
+}
+
+// locate returns the fingerprint and the first candidate bucket of an element. The fingerprint is taken uniformly
+// from [1, 2^16) by scaling the upper 32 bits of the hash, since 0 marks an empty slot.
+func (f *filter[T]) locate(element T) (uint16, uint64) {
+	hash := f.hashFunc(element)
+	fingerprint := uint16(1 + (hash>>32)*(1<<16-1)>>32)
+	return fingerprint, hash & f.bucketMask()
+}
+
+// alternate returns the other candidate bucket of a fingerprint (partial-key cuckoo hashing).
+// Since only the fingerprint is needed, fingerprints can be relocated without knowing the element.
+func (f *filter[T]) alternate(bucket uint64, fingerprint uint16) uint64 {
+	return (bucket ^ set.HashUint64(uint64(fingerprint))) & f.bucketMask()
+}
+
+func (f *filter[T]) bucket(index uint64) []uint16 {
+	return f.fingerprints[index*bucketSize : (index+1)*bucketSize]
+}
+
+// put stores the fingerprint in a free slot of the bucket and returns true, or returns false if the bucket is full.
+func (f *filter[T]) put(index uint64, fingerprint uint16) bool {
+	bucket := f.bucket(index)
+	for i, fp := range bucket {
+		if fp == 0 {
+			bucket[i] = fingerprint
+			return true
+		}
+	}
+	return false
+}
+
+// Insert adds an element to the filter.
+// If there is no room for the element, an error wrapping ErrFull is returned and the filter remains unchanged.
+func (f *filter[T]) Insert(element T) error {
+	fingerprint, i1 := f.locate(element)
+	i2 := f.alternate(i1, fingerprint)
+	if f.put(i1, fingerprint) || f.put(i2, fingerprint) {
+		f.count++
+		return nil
+	}
+
+	// relocate fingerprints to their alternate buckets, remembering the swaps to undo them on failure
+	type swap struct {
+		slot        int
+		fingerprint uint16
+	}
+	swaps := make([]swap, 0, maxKicks)
+	index := []uint64{i1, i2}[rand.IntN(2)]
+	for range maxKicks {
+		slot := int(index)*bucketSize + rand.IntN(bucketSize)
+		swaps = append(swaps, swap{slot: slot, fingerprint: f.fingerprints[slot]})
+		fingerprint, f.fingerprints[slot] = f.fingerprints[slot], fingerprint
+		index = f.alternate(index, fingerprint)
+		if f.put(index, fingerprint) {
+			f.count++
+			return nil
+		}
+	}
+	for i := len(swaps) - 1; i >= 0; i-- {
+		f.fingerprints[swaps[i].slot] = swaps[i].fingerprint
+	}
+	return fmt.Errorf("cannot insert element into cuckoo filter with %d of %d slots used: %w", f.count, len(f.fingerprints), ErrFull)
+}
+
+// Lookup returns false if the element is definitely not in the filter, and true if it probably is.
+func (f *filter[T]) Lookup(element T) bool {
+	fingerprint, i1 := f.locate(element)
+	i2 := f.alternate(i1, fingerprint)
+	for _, index := range []uint64{i1, i2} {
+		for _, fp := range f.bucket(index) {
+			if fp == fingerprint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Delete removes one fingerprint of the element from the filter and returns true.
+// If the element is definitely not in the filter, false is returned.
+// Only delete elements that have been inserted, see Filter.
+func (f *filter[T]) Delete(element T) bool {
+	fingerprint, i1 := f.locate(element)
+	i2 := f.alternate(i1, fingerprint)
+	for _, index := range []uint64{i1, i2} {
+		bucket := f.bucket(index)
+		for i, fp := range bucket {
+			if fp == fingerprint {
+				bucket[i] = 0
+				f.count--
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Count returns the number of fingerprints stored in the filter,
+// i.e. the number of successful insertions minus the number of successful deletions.
+func (f *filter[T]) Count() int {
+	return f.count
+}
+
+// Capacity returns the number of fingerprint slots of the filter.
+// Insertions usually start failing at a load of about 95% of the capacity.
+func (f *filter[T]) Capacity() int {
+	return len(f.fingerprints)
+}
+
+// Clear removes all elements from the filter.
+func (f *filter[T]) Clear() {
+	clear(f.fingerprints)
+	f.count = 0
+}
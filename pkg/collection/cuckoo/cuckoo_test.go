@@ -0,0 +1,239 @@
+package cuckoo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func TestShouldInsertLookupAndDeleteElements(t *testing.T) {
+	// Given
+	f, err := New[string](1000)
+	assert.Nil(t, err)
+
+	// When
+	for i := range 1000 {
+		assert.Nil(t, f.Insert(fmt.Sprintf("element %d", i)))
+	}
+
+	// Then there are no false negatives
+	assert.Equal(t, 1000, f.Count())
+	assert.Equal(t, 2048, f.Capacity())
+	for i := range 1000 {
+		assert.True(t, f.Lookup(fmt.Sprintf("element %d", i)))
+	}
+	// and the false-positive rate is low
+	falsePositives := 0
+	for i := range 100_000 {
+		if f.Lookup(fmt.Sprintf("other %d", i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 30)
+
+	// When
+	for i := range 500 {
+		assert.True(t, f.Delete(fmt.Sprintf("element %d", i)))
+	}
+	// Then
+	assert.Equal(t, 500, f.Count())
+	for i := 500; i < 1000; i++ {
+		assert.True(t, f.Lookup(fmt.Sprintf("element %d", i)))
+	}
+	deleted := 0
+	for i := range 500 {
+		if !f.Lookup(fmt.Sprintf("element %d", i)) {
+			deleted++
+		}
+	}
+	assert.GreaterOrEqual(t, deleted, 495)
+
+	// When
+	f.Clear()
+	// Then
+	assert.Equal(t, 0, f.Count())
+	assert.False(t, f.Lookup("element 999"))
+	assert.False(t, f.Delete("element 999"))
+}
+
+func TestShouldLookupElementsUnderDeletion(t *testing.T) {
+	// Given a hash function mapping "a" and "b" to the same fingerprint and bucket
+	hashFunc := func(str string) uint64 {
+		if str == "b" {
+			str = "a"
+		}
+		return set.HashString(str)
+	}
+	f, _ := NewWithHashFunc(100, hashFunc)
+
+	// When inserting an element twice
+	assert.Nil(t, f.Insert("x"))
+	assert.Nil(t, f.Insert("x"))
+	// Then it has to be deleted twice
+	assert.Equal(t, 2, f.Count())
+	assert.True(t, f.Delete("x"))
+	assert.True(t, f.Lookup("x"))
+	assert.True(t, f.Delete("x"))
+	assert.False(t, f.Lookup("x"))
+	assert.False(t, f.Delete("x"))
+
+	// When deleting an element sharing its fingerprint with another element
+	assert.Nil(t, f.Insert("a"))
+	assert.Nil(t, f.Insert("b"))
+	assert.True(t, f.Delete("a"))
+	// Then the other element is still found, and so is the deleted element (false positive)
+	assert.True(t, f.Lookup("b"))
+	assert.True(t, f.Lookup("a"))
+
+	// When deleting an element that has never been inserted but shares the fingerprint
+	f.Clear()
+	assert.Nil(t, f.Insert("a"))
+	assert.True(t, f.Delete("b"))
+	// Then the inserted element is lost (false negative)
+	assert.False(t, f.Lookup("a"))
+}
+
+func TestShouldTakeFingerprintsFromWholeNonZeroRange(t *testing.T) {
+	// Given a hash function returning the element itself
+	f := newFilter[uint64](100, func(hash uint64) uint64 { return hash })
+
+	// When
+	lowest, _ := f.locate(0)
+	highest, _ := f.locate(math.MaxUint64)
+	middle, _ := f.locate(1 << 63)
+
+	// Then
+	assert.Equal(t, uint16(1), lowest)
+	assert.Equal(t, uint16(math.MaxUint16), highest)
+	assert.Equal(t, uint16(1<<15), middle)
+}
+
+func TestShouldFailToInsertIntoFullFilter(t *testing.T) {
+	// Given
+	f, _ := New[int](8)
+	assert.Equal(t, 16, f.Capacity())
+
+	// When
+	var err error
+	inserted := 0
+	for ; inserted < 100; inserted++ {
+		before, _ := f.MarshalBinary()
+		if err = f.Insert(inserted); err != nil {
+			// Then the filter remains unchanged
+			after, _ := f.MarshalBinary()
+			assert.Equal(t, before, after)
+			break
+		}
+	}
+
+	// Then
+	assert.True(t, errors.Is(err, ErrFull))
+	assert.EqualError(t, err, fmt.Sprintf("cannot insert element into cuckoo filter with %d of 16 slots used: cuckoo filter is full", inserted))
+	assert.Equal(t, inserted, f.Count())
+	for i := range inserted {
+		assert.True(t, f.Lookup(i))
+	}
+}
+
+func TestShouldFailToCreateFilterWithInvalidCapacity(t *testing.T) {
+	// When
+	f, err := New[int](0)
+
+	// Then
+	assert.Nil(t, f)
+	assert.EqualError(t, err, "cannot create cuckoo filter, capacity must be positive but is 0")
+}
+
+func TestShouldCreateFilterFromSet(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+	s.AddWithValue("apple", 1)
+	s.AddWithValue("banana", 2)
+
+	// When
+	f, err := FromSet(s, nil)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 2, f.Count())
+	assert.True(t, f.Lookup("apple"))
+	assert.True(t, f.Lookup("banana"))
+	assert.False(t, f.Lookup("cherry"))
+
+	// When
+	f, err = FromSet[string, int](nil, nil)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 0, f.Count())
+}
+
+func TestShouldMarshalAndUnmarshalFilter(t *testing.T) {
+	// Given
+	f, _ := New[int](100)
+	for i := range 90 {
+		assert.Nil(t, f.Insert(i))
+	}
+
+	// When
+	data, err := f.MarshalBinary()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 5+2*128, len(data))
+
+	// When
+	unmarshaled, _ := New[int](1)
+	err = unmarshaled.UnmarshalBinary(data)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 90, unmarshaled.Count())
+	assert.Equal(t, 128, unmarshaled.Capacity())
+	for i := range 90 {
+		assert.True(t, unmarshaled.Lookup(i))
+	}
+	assert.True(t, unmarshaled.Delete(5))
+	assert.Equal(t, 89, unmarshaled.Count())
+}
+
+func TestShouldFailToUnmarshalInvalidData(t *testing.T) {
+	// Given
+	valid, _ := newFilter[int](2, nil).MarshalBinary()
+
+	for name, testCase := range map[string]struct {
+		data []byte
+		err  string
+	}{
+		"too short":    {data: []byte{1, 2}, err: "invalid cuckoo filter format: data too short (2 bytes)"},
+		"version":      {data: append([]byte{2}, valid[1:]...), err: "invalid cuckoo filter format: unsupported version 2"},
+		"bucket count": {data: append([]byte{1, 3, 0, 0, 0}, valid[5:]...), err: "invalid cuckoo filter format: bucket count 3 is not a power of two"},
+		"truncated":    {data: valid[:len(valid)-1], err: "invalid cuckoo filter format: expected 16 bytes of fingerprints for 2 buckets but got 15"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			f, _ := New[int](1)
+			assert.Nil(t, f.Insert(42))
+
+			// When
+			err := f.UnmarshalBinary(testCase.data)
+
+			// Then
+			assert.True(t, errors.Is(err, ErrInvalidFormat))
+			assert.EqualError(t, err, testCase.err)
+			assert.True(t, f.Lookup(42))
+		})
+	}
+}
+
+func BenchmarkFilterLookup(b *testing.B) {
+	f, _ := New[int](100_000)
+	for i := range 90_000 {
+		_ = f.Insert(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Lookup(i)
+	}
+}
@@ -0,0 +1,57 @@
+package cuckoo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// formatVersion is the first byte of the serialized format:
+// version (1 byte) | bucket count (uint32) | bucket count * 4 fingerprints (uint16), all little endian.
+const formatVersion = 1
+
+// ErrInvalidFormat is returned (wrapped) when unmarshaling data that is not a valid serialized cuckoo filter.
+var ErrInvalidFormat = errors.New("invalid cuckoo filter format")
+
+// MarshalBinary serializes the filter. The hash function is not serialized,
+// so the filter has to be unmarshaled into a filter using the same hash function.
+func (f *filter[T]) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 5+2*len(f.fingerprints))
+	data = append(data, formatVersion)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(f.fingerprints)/bucketSize))
+	for _, fp := range f.fingerprints {
+		data = binary.LittleEndian.AppendUint16(data, fp)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary replaces the content of the filter by the serialized filter, which may have a different capacity.
+// If the data is invalid, an error wrapping ErrInvalidFormat is returned and the filter remains unchanged.
+func (f *filter[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return fmt.Errorf("%w: data too short (%d bytes)", ErrInvalidFormat, len(data))
+	}
+	if data[0] != formatVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidFormat, data[0])
+	}
+	bucketCount := binary.LittleEndian.Uint32(data[1:])
+	if bits.OnesCount32(bucketCount) != 1 {
+		return fmt.Errorf("%w: bucket count %d is not a power of two", ErrInvalidFormat, bucketCount)
+	}
+	if uint64(len(data)-5) != 2*bucketSize*uint64(bucketCount) {
+		return fmt.Errorf("%w: expected %d bytes of fingerprints for %d buckets but got %d",
+			ErrInvalidFormat, 2*bucketSize*uint64(bucketCount), bucketCount, len(data)-5)
+	}
+	fingerprints := make([]uint16, bucketCount*bucketSize)
+	count := 0
+	for i := range fingerprints {
+		fingerprints[i] = binary.LittleEndian.Uint16(data[5+2*i:])
+		if fingerprints[i] != 0 {
+			count++
+		}
+	}
+	f.fingerprints = fingerprints
+	f.count = count
+	return nil
+}
@@ -0,0 +1,69 @@
+package hyperloglog
+
+import (
+	"fmt"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// Counter counts distinct elements of type T exactly in a set as long as there are at most threshold of them.
+// Once the threshold is passed, the elements are moved to a sketch and the count is estimated from then on,
+// so the memory needed is bounded by the threshold and the size of the sketch.
+type Counter[T comparable] interface {
+	Add(T)
+	Count() uint64
+	IsExact() bool
+}
+
+type counter[T comparable] struct {
+	threshold int
+	// exact is nil once the threshold has been passed
+	exact  set.Set[T, set.InternalEmptyType]
+	sketch *sketch[T]
+}
+
+// NewCounter creates a new counter counting up to threshold distinct elements exactly,
+// and estimating the count with a sketch of the given precision afterwards. If hashFunc is nil, set.DefaultHashFunc is used.
+// An error is returned if threshold is negative or the precision is not between MinPrecision and MaxPrecision.
+func NewCounter[T comparable](threshold int, precision int, hashFunc set.HashFunc[T]) (Counter[T], error) {
+	if threshold < 0 {
+		return nil, fmt.Errorf("cannot create counter, threshold must not be negative but is %d", threshold)
+	}
+	if err := checkPrecision(precision); err != nil {
+		return nil, err
+	}
+	return &counter[T]{
+		threshold: threshold,
+		exact:     set.NewWithoutValues[T](),
+		sketch:    newSketch(precision, hashFunc),
+	}, nil
+}
+
+// Add adds an element to the counter.
+func (c *counter[T]) Add(element T) {
+	if c.exact == nil {
+		c.sketch.Add(element)
+		return
+	}
+	c.exact.AddWithoutValue(element)
+	if c.exact.Size() > c.threshold {
+		for elem := range c.exact.Elements() {
+			c.sketch.Add(elem)
+		}
+		c.exact = nil
+	}
+}
+
+// Count returns the number of distinct elements added to the counter,
+// which is exact as long as the threshold has not been passed and estimated afterwards.
+func (c *counter[T]) Count() uint64 {
+	if c.exact != nil {
+		return uint64(c.exact.Size())
+	}
+	return c.sketch.Estimate()
+}
+
+// IsExact checks whether or not the count is exact, i.e. the threshold has not been passed yet.
+func (c *counter[T]) IsExact() bool {
+	return c.exact != nil
+}
@@ -0,0 +1,45 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCountExactlyUpToThreshold(t *testing.T) {
+	// Given
+	c, err := NewCounter[string](1000, DefaultPrecision, nil)
+	assert.Nil(t, err)
+
+	// When
+	for i := range 1000 {
+		c.Add(fmt.Sprintf("visitor %d", i))
+		c.Add(fmt.Sprintf("visitor %d", i))
+	}
+	// Then
+	assert.True(t, c.IsExact())
+	assert.Equal(t, uint64(1000), c.Count())
+
+	// When
+	for i := range 50_000 {
+		c.Add(fmt.Sprintf("visitor %d", i))
+	}
+	// Then
+	assert.False(t, c.IsExact())
+	assert.InEpsilon(t, 50_000, c.Count(), 0.025)
+}
+
+func TestShouldFailToCreateCounterWithInvalidParameters(t *testing.T) {
+	// When
+	c, err := NewCounter[int](-1, DefaultPrecision, nil)
+	// Then
+	assert.Nil(t, c)
+	assert.EqualError(t, err, "cannot create counter, threshold must not be negative but is -1")
+
+	// When
+	c, err = NewCounter[int](10, 2, nil)
+	// Then
+	assert.Nil(t, c)
+	assert.EqualError(t, err, "cannot create hyperloglog sketch, precision must be between 4 and 18 but is 2")
+}
@@ -0,0 +1,212 @@
+// A cardinality estimator for counting distinct elements in constant memory (HyperLogLog).
+package hyperloglog
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/bits"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+const (
+	// MinPrecision is the smallest supported precision (16 registers, standard error about 26%).
+	MinPrecision = 4
+	// MaxPrecision is the largest supported precision (262144 registers, standard error about 0.2%).
+	MaxPrecision = 18
+	// DefaultPrecision is a precision with a standard error of about 0.8% using 16 KiB of registers.
+	DefaultPrecision = 14
+)
+
+// Sketch is a HyperLogLog sketch estimating the number of distinct elements of type T added to it.
+// With precision p, the sketch has 2^p registers of one byte each and a standard error of about 1.04/sqrt(2^p),
+// regardless of the number of added elements.
+// As long as only few registers are in use, the sketch is sparse and stores only the used registers.
+type Sketch[T comparable] interface {
+	Add(T)
+	Estimate() uint64
+	Merge(Sketch[T]) error
+	Clear()
+
+	Precision() int
+	IsSparse() bool
+
+	Copy() Sketch[T]
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+type sketch[T comparable] struct {
+	precision int
+	// registers is nil as long as the sketch is sparse
+	registers []uint8
+	// sparse holds the used registers (index to value) of a sparse sketch
+	sparse   map[uint32]uint8
+	hashFunc set.HashFunc[T]
+}
+
+// New creates a new, empty sketch with the given precision, using set.DefaultHashFunc for hashing the elements.
+// An error is returned if the precision is not between MinPrecision and MaxPrecision.
+func New[T comparable](precision int) (Sketch[T], error) {
+	return NewWithHashFunc[T](precision, nil)
+}
+
+// NewWithHashFunc creates a new, empty sketch with the given precision, using hashFunc for hashing the elements.
+// If hashFunc is nil, set.DefaultHashFunc is used.
+// An error is returned if the precision is not between MinPrecision and MaxPrecision.
+func NewWithHashFunc[T comparable](precision int, hashFunc set.HashFunc[T]) (Sketch[T], error) {
+	if err := checkPrecision(precision); err != nil {
+		return nil, err
+	}
+	return newSketch(precision, hashFunc), nil
+}
+
+func checkPrecision(precision int) error {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return fmt.Errorf("cannot create hyperloglog sketch, precision must be between %d and %d but is %d", MinPrecision, MaxPrecision, precision)
+	}
+	return nil
+}
+
+func newSketch[T comparable](precision int, hashFunc set.HashFunc[T]) *sketch[T] {
+	if hashFunc == nil {
+		hashFunc = set.DefaultHashFunc[T]()
+	}
+	return &sketch[T]{precision: precision, sparse: map[uint32]uint8{}, hashFunc: hashFunc}
+}
+
+func (s *sketch[T]) registerCount() int {
+	return 1 << s.precision
+}
+
+// sparseLimit is the number of used registers above which a sparse sketch is converted to a dense one.
+// A map entry needs several bytes, so the sparse representation only saves memory for a small fraction of used registers.
+func (s *sketch[T]) sparseLimit() int {
+	return s.registerCount() / 8
+}
+
+// set raises the register to the given value if it is smaller.
+func (s *sketch[T]) set(index uint32, value uint8) {
+	if s.registers != nil {
+		s.registers[index] = max(s.registers[index], value)
+		return
+	}
+	if value > s.sparse[index] {
+		s.sparse[index] = value
+		if len(s.sparse) > s.sparseLimit() {
+			s.toDense()
+		}
+	}
+}
+
+func (s *sketch[T]) toDense() {
+	s.registers = make([]uint8, s.registerCount())
+	for index, value := range s.sparse {
+		s.registers[index] = value
+	}
+	s.sparse = nil
+}
+
+// Add adds an element to the sketch.
+// The upper p bits of the hash select the register, which is raised to the position of the first set bit in the remaining bits.
+func (s *sketch[T]) Add(element T) {
+	hash := s.hashFunc(element)
+	index := uint32(hash >> (64 - s.precision))
+	rest := hash<<s.precision | 1<<(s.precision-1)
+	s.set(index, uint8(bits.LeadingZeros64(rest)+1))
+}
+
+// Estimate returns the estimated number of distinct elements added to the sketch.
+// Small cardinalities are estimated by linear counting of the empty registers, which is much more accurate in that range.
+func (s *sketch[T]) Estimate() uint64 {
+	m := float64(s.registerCount())
+	sum := 0.0
+	zeros := 0
+	if s.registers != nil {
+		for _, value := range s.registers {
+			sum += math.Ldexp(1, -int(value))
+			if value == 0 {
+				zeros++
+			}
+		}
+	} else {
+		zeros = s.registerCount() - len(s.sparse)
+		sum = float64(zeros)
+		for _, value := range s.sparse {
+			sum += math.Ldexp(1, -int(value))
+		}
+	}
+
+	estimate := alpha(s.registerCount()) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+// alpha is the bias correction constant for m registers.
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
+
+// Merge adds all elements of other to this sketch by taking the maximum of each register.
+// Merging is lossless: the result is the same as if all elements had been added to this sketch.
+// A nil other is treated as an empty sketch. An error is returned if the precisions of the sketches differ.
+func (s *sketch[T]) Merge(other Sketch[T]) error {
+	if other == nil {
+		return nil
+	}
+	o := other.(*sketch[T])
+	if s.precision != o.precision {
+		return fmt.Errorf("cannot merge hyperloglog sketches with precision %d and %d", s.precision, o.precision)
+	}
+	if o.registers == nil {
+		for index, value := range o.sparse {
+			s.set(index, value)
+		}
+		return nil
+	}
+	if s.registers == nil {
+		s.toDense()
+	}
+	for index, value := range o.registers {
+		s.registers[index] = max(s.registers[index], value)
+	}
+	return nil
+}
+
+// Clear removes all elements from the sketch, making it sparse again.
+func (s *sketch[T]) Clear() {
+	s.registers = nil
+	s.sparse = map[uint32]uint8{}
+}
+
+// Precision returns the precision of the sketch, i.e. the logarithm of its number of registers.
+func (s *sketch[T]) Precision() int {
+	return s.precision
+}
+
+// IsSparse checks whether or not the sketch only stores its used registers.
+func (s *sketch[T]) IsSparse() bool {
+	return s.registers == nil
+}
+
+// Copy returns a new sketch containing all elements of this sketch.
+func (s *sketch[T]) Copy() Sketch[T] {
+	return &sketch[T]{
+		precision: s.precision,
+		registers: append([]uint8(nil), s.registers...),
+		sparse:    maps.Clone(s.sparse),
+		hashFunc:  s.hashFunc,
+	}
+}
@@ -0,0 +1,221 @@
+package hyperloglog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldEstimateCardinality(t *testing.T) {
+	for _, count := range []int{0, 1, 10, 1000, 100_000, 1_000_000} {
+		t.Run(fmt.Sprint(count), func(t *testing.T) {
+			// Given
+			s, err := New[string](DefaultPrecision)
+			assert.Nil(t, err)
+
+			// When adding every element twice
+			for i := range count {
+				s.Add(fmt.Sprintf("visitor %d", i))
+				s.Add(fmt.Sprintf("visitor %d", i))
+			}
+
+			// Then the estimate is within three standard errors (0.8% each)
+			assert.InEpsilon(t, float64(count)+1, float64(s.Estimate())+1, 0.025)
+		})
+	}
+}
+
+func TestShouldSwitchFromSparseToDense(t *testing.T) {
+	// Given
+	s, _ := New[int](10)
+
+	// Expect
+	assert.True(t, s.IsSparse())
+	assert.Equal(t, 10, s.Precision())
+
+	// When
+	for i := range 100 {
+		s.Add(i)
+	}
+	// Then
+	assert.True(t, s.IsSparse())
+	sparseEstimate := s.Estimate()
+	assert.InDelta(t, 100, sparseEstimate, 5)
+
+	// When
+	for i := 100; i < 1000; i++ {
+		s.Add(i)
+	}
+	// Then
+	assert.False(t, s.IsSparse())
+	assert.InEpsilon(t, 1000, s.Estimate(), 0.1)
+
+	// When
+	s.Clear()
+	// Then
+	assert.True(t, s.IsSparse())
+	assert.Equal(t, uint64(0), s.Estimate())
+}
+
+func TestShouldEstimateSameCardinalityForSparseAndDenseSketch(t *testing.T) {
+	// Given
+	sparse, _ := New[int](12)
+	dense := newSketch[int](12, nil)
+	dense.toDense()
+
+	// When
+	for i := range 300 {
+		sparse.Add(i)
+		dense.Add(i)
+	}
+
+	// Then
+	assert.True(t, sparse.IsSparse())
+	assert.False(t, dense.IsSparse())
+	assert.Equal(t, dense.Estimate(), sparse.Estimate())
+}
+
+func TestShouldMergeSketchesLosslessly(t *testing.T) {
+	for name, counts := range map[string][2]int{
+		"sparse into sparse": {100, 200},
+		"dense into sparse":  {100, 20_000},
+		"sparse into dense":  {20_000, 100},
+		"dense into dense":   {20_000, 30_000},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			s1, _ := New[int](12)
+			s2, _ := New[int](12)
+			all, _ := New[int](12)
+			for i := range counts[0] {
+				s1.Add(i)
+				all.Add(i)
+			}
+			for i := range counts[1] {
+				s2.Add(i + 50)
+				all.Add(i + 50)
+			}
+			copied := s2.Copy()
+
+			// When
+			err := s1.Merge(s2)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, all.Estimate(), s1.Estimate())
+			expected, _ := all.MarshalBinary()
+			merged, _ := s1.MarshalBinary()
+			assert.Equal(t, expected, merged)
+			// and the other sketch remains unchanged
+			assert.Equal(t, copied.Estimate(), s2.Estimate())
+		})
+	}
+}
+
+func TestShouldFailToMergeSketchesWithDifferentPrecision(t *testing.T) {
+	// Given
+	s1, _ := New[int](12)
+	s2, _ := New[int](14)
+
+	// When
+	err := s1.Merge(s2)
+
+	// Then
+	assert.EqualError(t, err, "cannot merge hyperloglog sketches with precision 12 and 14")
+	assert.Nil(t, s1.Merge(nil))
+}
+
+func TestShouldFailToCreateSketchWithInvalidPrecision(t *testing.T) {
+	// When
+	s, err := New[int](3)
+
+	// Then
+	assert.Nil(t, s)
+	assert.EqualError(t, err, "cannot create hyperloglog sketch, precision must be between 4 and 18 but is 3")
+
+	// When
+	s, err = New[int](19)
+	// Then
+	assert.Nil(t, s)
+	assert.EqualError(t, err, "cannot create hyperloglog sketch, precision must be between 4 and 18 but is 19")
+}
+
+func TestShouldMarshalAndUnmarshalSketch(t *testing.T) {
+	for name, count := range map[string]int{"empty": 0, "sparse": 50, "dense": 10_000} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			s, _ := New[int](10)
+			for i := range count {
+				s.Add(i)
+			}
+
+			// When
+			data, err := s.MarshalBinary()
+			// Then
+			assert.Nil(t, err)
+			if s.IsSparse() {
+				assert.Less(t, len(data), 3+4*count+2)
+			} else {
+				assert.Equal(t, 3+1024, len(data))
+			}
+
+			// When
+			unmarshaled, _ := New[int](4)
+			err = unmarshaled.UnmarshalBinary(data)
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, 10, unmarshaled.Precision())
+			assert.Equal(t, s.IsSparse(), unmarshaled.IsSparse())
+			assert.Equal(t, s.Estimate(), unmarshaled.Estimate())
+			// and elements can still be added
+			unmarshaled.Add(count + 1)
+			s.Add(count + 1)
+			assert.Equal(t, s.Estimate(), unmarshaled.Estimate())
+		})
+	}
+}
+
+func TestShouldFailToUnmarshalInvalidData(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		data []byte
+		err  string
+	}{
+		"too short":       {data: []byte{1, 4}, err: "invalid hyperloglog sketch format: data too short (2 bytes)"},
+		"version":         {data: []byte{2, 4, 0}, err: "invalid hyperloglog sketch format: unsupported version 2"},
+		"precision":       {data: []byte{1, 20, 0}, err: "invalid hyperloglog sketch format: unsupported precision 20"},
+		"encoding":        {data: []byte{1, 4, 2}, err: "invalid hyperloglog sketch format: unknown encoding 2"},
+		"registers":       {data: []byte{1, 4, 0, 1, 2}, err: "invalid hyperloglog sketch format: expected 16 registers but got 2"},
+		"dense value":     {data: append([]byte{1, 4, 0, 62}, make([]byte, 15)...), err: "invalid hyperloglog sketch format: value 62 of register 0 exceeds 61"},
+		"count":           {data: []byte{1, 4, 1, 17}, err: "invalid hyperloglog sketch format: invalid number of used registers"},
+		"truncated":       {data: []byte{1, 4, 1, 2, 3, 1}, err: "invalid hyperloglog sketch format: unexpected end of data"},
+		"unsorted":        {data: []byte{1, 4, 1, 2, 3, 1, 0, 1}, err: "invalid hyperloglog sketch format: invalid register index 3"},
+		"index":           {data: []byte{1, 4, 1, 1, 16, 1}, err: "invalid hyperloglog sketch format: invalid register index 16"},
+		"sparse value":    {data: []byte{1, 4, 1, 1, 3, 0}, err: "invalid hyperloglog sketch format: value 0 of register 3 is not between 1 and 61"},
+		"trailing":        {data: []byte{1, 4, 1, 1, 3, 1, 7}, err: "invalid hyperloglog sketch format: 1 unexpected bytes after used registers"},
+		"empty registers": {data: []byte{1, 4, 1}, err: "invalid hyperloglog sketch format: invalid number of used registers"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			s, _ := New[int](8)
+			s.Add(42)
+
+			// When
+			err := s.UnmarshalBinary(testCase.data)
+
+			// Then
+			assert.True(t, errors.Is(err, ErrInvalidFormat))
+			assert.EqualError(t, err, testCase.err)
+			assert.Equal(t, 8, s.Precision())
+			assert.Equal(t, uint64(1), s.Estimate())
+		})
+	}
+}
+
+func BenchmarkSketchAdd(b *testing.B) {
+	s, _ := New[int](DefaultPrecision)
+	for i := 0; i < b.N; i++ {
+		s.Add(i)
+	}
+}
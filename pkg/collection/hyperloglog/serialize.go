@@ -0,0 +1,100 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// The serialized format starts with the version, the precision and the encoding (one byte each).
+// A dense sketch is followed by one byte per register, a sparse sketch by the number of used registers (uvarint)
+// and for each used register in ascending order the difference to the previous index (uvarint) and the value (one byte).
+const (
+	formatVersion  = 1
+	encodingDense  = 0
+	encodingSparse = 1
+)
+
+// ErrInvalidFormat is returned (wrapped) when unmarshaling data that is not a valid serialized sketch.
+var ErrInvalidFormat = errors.New("invalid hyperloglog sketch format")
+
+// MarshalBinary serializes the sketch, keeping the sparse encoding of sparse sketches.
+// The hash function is not serialized, so the sketch has to be unmarshaled into a sketch using the same hash function.
+func (s *sketch[T]) MarshalBinary() ([]byte, error) {
+	if s.registers != nil {
+		return append([]byte{formatVersion, byte(s.precision), encodingDense}, s.registers...), nil
+	}
+	data := []byte{formatVersion, byte(s.precision), encodingSparse}
+	data = binary.AppendUvarint(data, uint64(len(s.sparse)))
+	previous := uint32(0)
+	for _, index := range slices.Sorted(maps.Keys(s.sparse)) {
+		data = binary.AppendUvarint(data, uint64(index-previous))
+		data = append(data, s.sparse[index])
+		previous = index
+	}
+	return data, nil
+}
+
+// UnmarshalBinary replaces the content of the sketch by the serialized sketch, which may have a different precision.
+// If the data is invalid, an error wrapping ErrInvalidFormat is returned and the sketch remains unchanged.
+func (s *sketch[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return fmt.Errorf("%w: data too short (%d bytes)", ErrInvalidFormat, len(data))
+	}
+	if data[0] != formatVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidFormat, data[0])
+	}
+	precision := int(data[1])
+	if precision < MinPrecision || precision > MaxPrecision {
+		return fmt.Errorf("%w: unsupported precision %d", ErrInvalidFormat, precision)
+	}
+	encoding := data[2]
+	data = data[3:]
+	maxValue := uint8(64 - precision + 1)
+	result := newSketch(precision, s.hashFunc)
+
+	switch encoding {
+	case encodingDense:
+		if len(data) != result.registerCount() {
+			return fmt.Errorf("%w: expected %d registers but got %d", ErrInvalidFormat, result.registerCount(), len(data))
+		}
+		if i := slices.IndexFunc(data, func(value byte) bool { return value > maxValue }); i >= 0 {
+			return fmt.Errorf("%w: value %d of register %d exceeds %d", ErrInvalidFormat, data[i], i, maxValue)
+		}
+		result.registers = slices.Clone(data)
+		result.sparse = nil
+	case encodingSparse:
+		count, n := binary.Uvarint(data)
+		if n <= 0 || count > uint64(result.registerCount()) {
+			return fmt.Errorf("%w: invalid number of used registers", ErrInvalidFormat)
+		}
+		data = data[n:]
+		index := uint64(0)
+		for i := range count {
+			delta, n := binary.Uvarint(data)
+			if n <= 0 || n >= len(data) {
+				return fmt.Errorf("%w: unexpected end of data", ErrInvalidFormat)
+			}
+			index += delta
+			value := data[n]
+			data = data[n+1:]
+			switch {
+			case i > 0 && delta == 0, delta >= uint64(result.registerCount()), index >= uint64(result.registerCount()):
+				return fmt.Errorf("%w: invalid register index %d", ErrInvalidFormat, index)
+			case value == 0 || value > maxValue:
+				return fmt.Errorf("%w: value %d of register %d is not between 1 and %d", ErrInvalidFormat, value, index, maxValue)
+			}
+			result.set(uint32(index), value)
+		}
+		if len(data) > 0 {
+			return fmt.Errorf("%w: %d unexpected bytes after used registers", ErrInvalidFormat, len(data))
+		}
+	default:
+		return fmt.Errorf("%w: unknown encoding %d", ErrInvalidFormat, encoding)
+	}
+
+	*s = *result
+	return nil
+}
@@ -0,0 +1,34 @@
+// The natural order of the ordered kinds of types, shared by the collection packages to iterate, encode and print
+// their elements in a deterministic order.
+package order
+
+import (
+	"cmp"
+	"reflect"
+	"strings"
+)
+
+// Compare returns a function comparing values of type T in their natural order if T is an ordered type
+// (integers, floats and strings, including types based on them), and nil otherwise.
+func Compare[T any]() func(a, b T) int {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String:
+		return func(a, b T) int {
+			return strings.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Int(), reflect.ValueOf(b).Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Uint(), reflect.ValueOf(b).Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(a, b T) int {
+			return cmp.Compare(reflect.ValueOf(a).Float(), reflect.ValueOf(b).Float())
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+// Default encode and decode functions for scalar types, shared by the codecs of the binary data formats (CBOR, MessagePack),
+// and the check for values carrying no information shared by all encodings of sets.
+package scalarcodec
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Writer writes the scalar types of a data format.
+type Writer interface {
+	WriteBool(b bool) error
+	WriteInt(n int64) error
+	WriteUint(n uint64) error
+	WriteFloat(f float64) error
+	WriteString(s string) error
+	WriteBytes(b []byte) error
+}
+
+// Reader reads the scalar types of a data format.
+type Reader interface {
+	Offset() int64
+	ReadBool() (bool, error)
+	ReadInt() (int64, error)
+	ReadUint() (uint64, error)
+	ReadFloat() (float64, error)
+	ReadString() (string, error)
+	ReadBytes() ([]byte, error)
+	Skip() error
+}
+
+// Format describes the parts of a data format which differ between formats.
+type Format[W Writer, R Reader] struct {
+	// Name is used in error messages, e.g. "CBOR".
+	Name string
+	// ErrInvalidFormat is wrapped by errors about invalid data.
+	ErrInvalidFormat error
+	// WriteNull writes the null value of the format, used for empty structs.
+	WriteNull func(w W) error
+}
+
+// Default returns the encode and decode functions for E: booleans, integers, floats, strings and byte slices,
+// including types based on them, are written as the corresponding types of the format. Empty structs are written as null,
+// and any value is accepted when reading them. For all other types, the functions return an error.
+func Default[E any, W Writer, R Reader](format Format[W, R]) (func(W, E) error, func(R) (E, error)) {
+	typ := reflect.TypeFor[E]()
+	switch typ.Kind() {
+	case reflect.Bool:
+		return func(w W, e E) error { return w.WriteBool(reflect.ValueOf(e).Bool()) },
+			func(r R) (E, error) {
+				b, err := r.ReadBool()
+				return convert[E](reflect.ValueOf(b), err)
+			}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(w W, e E) error { return w.WriteInt(reflect.ValueOf(e).Int()) },
+			func(r R) (E, error) {
+				offset := r.Offset()
+				n, err := r.ReadInt()
+				if err == nil && reflect.New(typ).Elem().OverflowInt(n) {
+					err = fmt.Errorf("%w: integer %d at offset %d overflows %v", format.ErrInvalidFormat, n, offset, typ)
+				}
+				return convert[E](reflect.ValueOf(n), err)
+			}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(w W, e E) error { return w.WriteUint(reflect.ValueOf(e).Uint()) },
+			func(r R) (E, error) {
+				offset := r.Offset()
+				n, err := r.ReadUint()
+				if err == nil && reflect.New(typ).Elem().OverflowUint(n) {
+					err = fmt.Errorf("%w: integer %d at offset %d overflows %v", format.ErrInvalidFormat, n, offset, typ)
+				}
+				return convert[E](reflect.ValueOf(n), err)
+			}
+	case reflect.Float32, reflect.Float64:
+		return func(w W, e E) error { return w.WriteFloat(reflect.ValueOf(e).Float()) },
+			func(r R) (E, error) {
+				f, err := r.ReadFloat()
+				return convert[E](reflect.ValueOf(f), err)
+			}
+	case reflect.String:
+		return func(w W, e E) error { return w.WriteString(reflect.ValueOf(e).String()) },
+			func(r R) (E, error) {
+				s, err := r.ReadString()
+				return convert[E](reflect.ValueOf(s), err)
+			}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return func(w W, e E) error { return w.WriteBytes(reflect.ValueOf(e).Bytes()) },
+				func(r R) (E, error) {
+					b, err := r.ReadBytes()
+					return convert[E](reflect.ValueOf(b), err)
+				}
+		}
+	case reflect.Struct:
+		if typ.NumField() == 0 {
+			return func(w W, _ E) error { return format.WriteNull(w) },
+				func(r R) (E, error) {
+					var e E
+					return e, r.Skip()
+				}
+		}
+	}
+	return func(W, E) error {
+			return fmt.Errorf("cannot encode %v as %s, it needs a custom codec", typ, format.Name)
+		},
+		func(R) (E, error) {
+			var e E
+			return e, fmt.Errorf("cannot decode %v from %s, it needs a custom codec", typ, format.Name)
+		}
+}
+
+// HasValues reports whether values of type V carry information, which is not the case for empty structs
+// like set.InternalEmptyType. Sets with such values are encoded without their values.
+func HasValues[V any]() bool {
+	typ := reflect.TypeFor[V]()
+	return typ.Kind() != reflect.Struct || typ.NumField() != 0
+}
+
+// convert converts the read value to E, which is based on the type of the value.
+func convert[E any](v reflect.Value, err error) (E, error) {
+	var e E
+	if err != nil {
+		return e, err
+	}
+	reflect.ValueOf(&e).Elem().Set(v.Convert(reflect.TypeFor[E]()))
+	return e, nil
+}
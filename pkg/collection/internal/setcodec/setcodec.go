@@ -0,0 +1,123 @@
+// Streaming encoding and decoding of sets shared by the binary data formats (CBOR, MessagePack). The formats provide
+// the structure of an encoded set (its start and null value), the elements and values are written and read by codecs.
+package setcodec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// Writer writes the structure of an encoded set in a data format.
+type Writer interface {
+	// WriteNull writes the null value a nil set is encoded as.
+	WriteNull() error
+	// WriteStart writes the start of a set with size elements.
+	WriteStart(size int) error
+	Flush() error
+}
+
+// Start is the start of an encoded set read by a Reader.
+type Start struct {
+	// Null is true if the set is encoded as null, then no elements follow.
+	Null bool
+	// Length is the number of elements, or -1 for an indefinite length ended by a marker (see Reader.AtEnd).
+	Length int
+	// Map is true if each element is followed by its value.
+	Map bool
+}
+
+// Reader reads the structure of an encoded set in a data format.
+type Reader interface {
+	// ReadStart reads the start of the next set. It returns io.EOF if the stream ends before the set.
+	ReadStart() (Start, error)
+	// AtEnd reports whether a set of indefinite length ends at the current position, skipping the end marker if so.
+	AtEnd() (bool, error)
+	// Offset returns the number of bytes read so far.
+	Offset() int64
+}
+
+// Encode writes the set to w with a definite length, a nil set as null. Each element is followed by its value
+// if asMap is true, otherwise the values must not carry information (see scalarcodec.HasValues).
+// The elements are written in the iteration order of the set without collecting them first.
+// It fails if the set is modified while encoding.
+func Encode[T comparable, V any](w Writer, s set.Set[T, V], form fmt.Stringer, asMap bool,
+	encodeElement func(T) error, encodeValue func(V) error) error {
+	if s == nil {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+	if !asMap && scalarcodec.HasValues[V]() {
+		return fmt.Errorf("a set with values cannot be written as %v, use Map", form)
+	}
+
+	size := s.Size()
+	if err := w.WriteStart(size); err != nil {
+		return err
+	}
+	count := 0
+	for elem, value := range s.All() {
+		if count++; count > size {
+			break
+		}
+		if err := encodeElement(elem); err != nil {
+			return err
+		}
+		if asMap {
+			if err := encodeValue(value); err != nil {
+				return err
+			}
+		}
+	}
+	if count != size {
+		return errors.New("set was modified while encoding")
+	}
+	return w.Flush()
+}
+
+// Decode reads the next set from r and adds its elements to the given set. Elements read without value get the zero value.
+// A null leaves the set unchanged. With StrictDuplicates, an element that is already contained in the set counts as duplicate.
+// If an error occurs, the elements decoded so far remain in the set.
+func Decode[T comparable, V any](r Reader, s set.Set[T, V], duplicates set.DuplicateHandling,
+	decodeElement func() (T, error), decodeValue func() (V, error)) error {
+	start, err := r.ReadStart()
+	if err != nil || start.Null {
+		return err
+	}
+	for index := 0; start.Length < 0 || index < start.Length; index++ {
+		if start.Length < 0 {
+			if atEnd, err := r.AtEnd(); err != nil || atEnd {
+				return err
+			}
+		}
+		offset := r.Offset()
+		elem, err := decodeElement()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		var value V
+		if start.Map {
+			if value, err = decodeValue(); err != nil {
+				return unexpectedEOF(err)
+			}
+		}
+		if duplicates == set.StrictDuplicates && s.Contains(elem) {
+			return fmt.Errorf("duplicate element %v at index %d (offset %d)", elem, index, offset)
+		}
+		s.AddWithValue(elem, value)
+	}
+	return nil
+}
+
+// unexpectedEOF converts io.EOF to io.ErrUnexpectedEOF, used when the end of the input is reached within a set.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
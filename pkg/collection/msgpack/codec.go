@@ -0,0 +1,40 @@
+package msgpack
+
+import "github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+
+// Codec writes and reads single elements or values of type E as MessagePack objects.
+// Custom codecs can be used for types not supported by DefaultCodec, e.g. structs.
+type Codec[E any] interface {
+	Encode(w *Writer, e E) error
+	Decode(r *Reader) (E, error)
+}
+
+type funcCodec[E any] struct {
+	encode func(*Writer, E) error
+	decode func(*Reader) (E, error)
+}
+
+// NewCodec creates a new codec from an encode and a decode function.
+func NewCodec[E any](encode func(w *Writer, e E) error, decode func(r *Reader) (E, error)) Codec[E] {
+	return &funcCodec[E]{encode: encode, decode: decode}
+}
+
+// Encode writes e as MessagePack object.
+func (c *funcCodec[E]) Encode(w *Writer, e E) error {
+	return c.encode(w, e)
+}
+
+// Decode reads e from a MessagePack object.
+func (c *funcCodec[E]) Decode(r *Reader) (E, error) {
+	return c.decode(r)
+}
+
+// DefaultCodec returns the codec used if no codec is given. It supports booleans, integers, floats, strings and byte slices,
+// including types based on them, as the corresponding MessagePack types. Empty structs (like set.InternalEmptyType) are written as nil,
+// and any object is accepted when reading them. All other types return an error, they need a custom codec.
+func DefaultCodec[E any]() Codec[E] {
+	return NewCodec(scalarcodec.Default[E](format))
+}
+
+// format describes MessagePack for the default codecs.
+var format = scalarcodec.Format[*Writer, *Reader]{Name: "MessagePack", ErrInvalidFormat: ErrInvalidFormat, WriteNull: (*Writer).WriteNil}
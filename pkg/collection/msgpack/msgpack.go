@@ -0,0 +1,173 @@
+// MessagePack encoding and decoding of sets, streamed to an io.Writer and from an io.Reader.
+package msgpack
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/setcodec"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// Form is the MessagePack representation of a set.
+type Form int
+
+const (
+	// Array is an array of elements, only for sets without values.
+	Array Form = iota
+	// Map is a map from elements to values. Sets without values have nil values.
+	Map
+)
+
+func (f Form) String() string {
+	switch f {
+	case Array:
+		return "array"
+	case Map:
+		return "map"
+	default:
+		return fmt.Sprintf("Form(%d)", int(f))
+	}
+}
+
+// Encoder writes sets as MessagePack to a stream.
+type Encoder[T comparable, V any] struct {
+	w            *Writer
+	form         Form
+	elementCodec Codec[T]
+	valueCodec   Codec[V]
+}
+
+// NewEncoder creates a new encoder writing sets in the given form to w, using the given codecs for the elements and values.
+// If a codec is nil, DefaultCodec is used.
+func NewEncoder[T comparable, V any](w io.Writer, form Form, elementCodec Codec[T], valueCodec Codec[V]) *Encoder[T, V] {
+	if elementCodec == nil {
+		elementCodec = DefaultCodec[T]()
+	}
+	if valueCodec == nil {
+		valueCodec = DefaultCodec[V]()
+	}
+	return &Encoder[T, V]{w: NewWriter(w), form: form, elementCodec: elementCodec, valueCodec: valueCodec}
+}
+
+// Encode writes the set as one MessagePack object, a nil set is written as nil.
+// The elements are written in the iteration order of the set without collecting them first, so huge sets can be streamed.
+// Concurrency-safe sets have to be modified neither during encoding nor between calling Size and iterating them.
+func (e *Encoder[T, V]) Encode(s set.Set[T, V]) error {
+	err := setcodec.Encode(setWriter{w: e.w, form: e.form}, s, e.form, e.form == Map,
+		func(elem T) error { return e.elementCodec.Encode(e.w, elem) },
+		func(value V) error { return e.valueCodec.Encode(e.w, value) })
+	if err != nil {
+		return fmt.Errorf("cannot encode set to MessagePack: %w", err)
+	}
+	return nil
+}
+
+// Decoder reads sets from a stream of MessagePack, adding element by element to the set without reading the whole input first.
+type Decoder[T comparable, V any] struct {
+	r            *Reader
+	elementCodec Codec[T]
+	valueCodec   Codec[V]
+	duplicates   set.DuplicateHandling
+}
+
+// NewDecoder creates a new decoder reading sets from r, using the given codecs for the elements and values.
+// If a codec is nil, DefaultCodec is used. If duplicates is StrictDuplicates, decoding fails when an element occurs more than once.
+func NewDecoder[T comparable, V any](r io.Reader, elementCodec Codec[T], valueCodec Codec[V], duplicates set.DuplicateHandling) *Decoder[T, V] {
+	if elementCodec == nil {
+		elementCodec = DefaultCodec[T]()
+	}
+	if valueCodec == nil {
+		valueCodec = DefaultCodec[V]()
+	}
+	return &Decoder[T, V]{r: NewReader(r), elementCodec: elementCodec, valueCodec: valueCodec, duplicates: duplicates}
+}
+
+// Decode reads the next set from the stream and adds its elements to the given set.
+// Both forms are accepted. Elements read from an array get the zero value, values read from a map are skipped
+// for sets without values (see DefaultCodec). A nil leaves the set unchanged.
+// With StrictDuplicates, an element that is already contained in the set counts as duplicate.
+// If an error occurs, the elements decoded so far remain in the set.
+func (d *Decoder[T, V]) Decode(s set.Set[T, V]) error {
+	err := setcodec.Decode(setReader{r: d.r}, s, d.duplicates,
+		func() (T, error) { return d.elementCodec.Decode(d.r) },
+		func() (V, error) { return d.valueCodec.Decode(d.r) })
+	if err != nil {
+		return fmt.Errorf("cannot decode set from MessagePack: %w", err)
+	}
+	return nil
+}
+
+// Marshal encodes the set as MessagePack in the given form using DefaultCodec, see Encoder.Encode.
+func Marshal[T comparable, V any](s set.Set[T, V], form Form) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder[T, V](&buf, form, nil, nil).Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a set from MessagePack using DefaultCodec and adds its elements to the given set, see Decoder.Decode.
+// Duplicate elements are accepted (the last value wins).
+func Unmarshal[T comparable, V any](data []byte, s set.Set[T, V]) error {
+	return NewDecoder[T, V](bytes.NewReader(data), nil, nil, set.LenientDuplicates).Decode(s)
+}
+
+// setWriter writes the structure of sets in the form of the encoder.
+type setWriter struct {
+	w    *Writer
+	form Form
+}
+
+func (s setWriter) WriteNull() error {
+	return s.w.WriteNil()
+}
+
+func (s setWriter) WriteStart(size int) error {
+	switch s.form {
+	case Array:
+		return s.w.WriteArrayHeader(size)
+	case Map:
+		return s.w.WriteMapHeader(size)
+	default:
+		return fmt.Errorf("unknown form %v", s.form)
+	}
+}
+
+func (s setWriter) Flush() error {
+	return s.w.Flush()
+}
+
+// setReader reads the structure of sets in both forms.
+type setReader struct {
+	r *Reader
+}
+
+func (s setReader) ReadStart() (setcodec.Start, error) {
+	if _, err := s.r.peekByte(); err != nil {
+		return setcodec.Start{}, err
+	}
+	if isNil, err := s.r.IsNil(); err != nil || isNil {
+		return setcodec.Start{Null: isNil}, err
+	}
+
+	start, err := s.r.readHeader()
+	if err != nil {
+		return setcodec.Start{}, unexpectedEOF(err)
+	}
+	if start.kind != kindArray && start.kind != kindMap {
+		return setcodec.Start{}, start.mismatch("array or map")
+	}
+	return setcodec.Start{Length: int(min(start.value, math.MaxInt)), Map: start.kind == kindMap}, nil
+}
+
+// AtEnd always returns false, since MessagePack has no indefinite lengths.
+func (s setReader) AtEnd() (bool, error) {
+	return false, nil
+}
+
+func (s setReader) Offset() int64 {
+	return s.r.Offset()
+}
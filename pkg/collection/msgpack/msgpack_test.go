@@ -0,0 +1,311 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func TestShouldWriteObjectsInShortestForm(t *testing.T) {
+	// Given
+	for expected, write := range map[string]func(w *Writer) error{
+		"00":                              func(w *Writer) error { return w.WriteUint(0) },
+		"7f":                              func(w *Writer) error { return w.WriteInt(127) },
+		"cc80":                            func(w *Writer) error { return w.WriteUint(128) },
+		"cd0100":                          func(w *Writer) error { return w.WriteUint(256) },
+		"ce00010000":                      func(w *Writer) error { return w.WriteInt(65536) },
+		"cf0000000100000000":              func(w *Writer) error { return w.WriteUint(1 << 32) },
+		"ff":                              func(w *Writer) error { return w.WriteInt(-1) },
+		"e0":                              func(w *Writer) error { return w.WriteInt(-32) },
+		"d0df":                            func(w *Writer) error { return w.WriteInt(-33) },
+		"d1ff7f":                          func(w *Writer) error { return w.WriteInt(-129) },
+		"d2ffff7fff":                      func(w *Writer) error { return w.WriteInt(-32769) },
+		"d38000000000000000":              func(w *Writer) error { return w.WriteInt(math.MinInt64) },
+		"ca3fc00000":                      func(w *Writer) error { return w.WriteFloat(1.5) },
+		"cb3ff199999999999a":              func(w *Writer) error { return w.WriteFloat(1.1) },
+		"c0":                              func(w *Writer) error { return w.WriteNil() },
+		"c2":                              func(w *Writer) error { return w.WriteBool(false) },
+		"c3":                              func(w *Writer) error { return w.WriteBool(true) },
+		"a161":                            func(w *Writer) error { return w.WriteString("a") },
+		"d920" + strings.Repeat("61", 32): func(w *Writer) error { return w.WriteString(strings.Repeat("a", 32)) },
+		"c4020102":                        func(w *Writer) error { return w.WriteBytes([]byte{1, 2}) },
+		"93":                              func(w *Writer) error { return w.WriteArrayHeader(3) },
+		"dc0010":                          func(w *Writer) error { return w.WriteArrayHeader(16) },
+		"dd00010000":                      func(w *Writer) error { return w.WriteArrayHeader(65536) },
+		"82":                              func(w *Writer) error { return w.WriteMapHeader(2) },
+		"de0010":                          func(w *Writer) error { return w.WriteMapHeader(16) },
+	} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+
+		// When
+		err := write(w)
+		w.Flush()
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expected, hex.EncodeToString(buf.Bytes()))
+	}
+}
+
+func TestShouldReadObjects(t *testing.T) {
+	// Given
+	r := reader("d38000000000000000" + "d0df" + "cd0100" + "ca3fc00000" + "01" + "a3616263" + "a26869" + "c4020102" +
+		"dc0002" + "d6ff00000000" + "81a0c0" + "c3" + "c0")
+
+	// Expect
+	assertRead(t, int64(math.MinInt64), r.ReadInt)
+	assertRead(t, int64(-33), r.ReadInt)
+	assertRead(t, uint64(256), r.ReadUint)
+	assertRead(t, 1.5, r.ReadFloat)
+	assertRead(t, 1.0, r.ReadFloat)
+	assertRead(t, "abc", r.ReadString)
+	assertRead(t, []byte("hi"), r.ReadBytes)
+	assertRead(t, "\x01\x02", r.ReadString)
+	assertRead(t, 2, r.ReadArrayHeader)
+	assert.Nil(t, r.Skip())
+	assert.Nil(t, r.Skip())
+	assertRead(t, true, r.ReadBool)
+	isNil, err := r.IsNil()
+	assert.Nil(t, err)
+	assert.True(t, isNil)
+	assert.Equal(t, int64(45), r.Offset())
+}
+
+func TestShouldFailToReadUnexpectedObjects(t *testing.T) {
+	// Expect
+	_, err := reader("a161").ReadInt()
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	assert.EqualError(t, err, "invalid MessagePack data: expected integer but found string at offset 0")
+	_, err = reader("cfffffffffffffffff").ReadInt()
+	assert.EqualError(t, err, "invalid MessagePack data: integer 18446744073709551615 at offset 0 overflows int64")
+	_, err = reader("ff").ReadUint()
+	assert.EqualError(t, err, "invalid MessagePack data: negative integer -1 at offset 0")
+	_, err = reader("c0").ReadBool()
+	assert.EqualError(t, err, "invalid MessagePack data: expected boolean but found nil at offset 0")
+	_, err = reader("c1").ReadBool()
+	assert.EqualError(t, err, "invalid MessagePack data: unused format 0xc1 at offset 0")
+	_, err = reader("a461").ReadString()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = reader("ce0001").ReadUint()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.EqualError(t, reader(strings.Repeat("91", 1002)+"00").Skip(), "invalid MessagePack data: nesting deeper than 1000 at offset 1001")
+}
+
+func TestShouldEncodeSetWithoutValuesInAllForms(t *testing.T) {
+	// Given
+	s := set.NewSortedWithoutValues[int]()
+	s.AddWithoutValue(-1)
+	s.AddWithoutValue(2)
+	s.AddWithoutValue(1000)
+
+	for form, expected := range map[Form]string{
+		Array: "93" + "ff" + "02" + "cd03e8",
+		Map:   "83" + "ffc0" + "02c0" + "cd03e8c0",
+	} {
+		// When
+		data, err := Marshal(s, form)
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expected, hex.EncodeToString(data))
+
+		// When
+		decoded := set.NewWithoutValues[int]()
+		err = Unmarshal(data, decoded)
+		// Then
+		assert.Nil(t, err)
+		assert.True(t, s.Equals(decoded))
+	}
+}
+
+func TestShouldRoundTripPrimitiveElementsAndValues(t *testing.T) {
+	// Given
+	type label string
+	labels := set.NewLinkedWithValues[label, []byte]()
+	labels.AddWithValue("new", []byte{1, 2})
+	labels.AddWithValue("", nil)
+	numbers := set.NewWithValues[int16, float64]()
+	numbers.AddWithValue(math.MinInt16, 1.1)
+	numbers.AddWithValue(math.MaxInt16, math.Inf(-1))
+	flags := set.NewWithValues[uint64, bool]()
+	flags.AddWithValue(math.MaxUint64, true)
+	flags.AddWithValue(0, false)
+
+	// When
+	labelsData, err1 := Marshal(labels, Map)
+	numbersData, err2 := Marshal(numbers, Map)
+	flagsData, err3 := Marshal(flags, Map)
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+
+	// When
+	decodedLabels := set.NewLinkedWithValues[label, []byte]()
+	decodedNumbers := set.NewWithValues[int16, float64]()
+	decodedFlags := set.NewWithValues[uint64, bool]()
+	err1 = Unmarshal(labelsData, decodedLabels)
+	err2 = Unmarshal(numbersData, decodedNumbers)
+	err3 = Unmarshal(flagsData, decodedFlags)
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Equal(t, labels.List(), decodedLabels.List())
+	assert.Equal(t, []byte{1, 2}, decodedLabels.GetElements()["new"])
+	assert.True(t, numbers.Equals(decodedNumbers))
+	assert.True(t, flags.Equals(decodedFlags))
+}
+
+func TestShouldEncodeStructsWithCustomCodec(t *testing.T) {
+	// Given a codec writing points as maps with the keys "x" and "y"
+	type point struct{ X, Y int }
+	codec := NewCodec(
+		func(w *Writer, p point) error {
+			w.WriteMapHeader(2)
+			w.WriteString("x")
+			w.WriteInt(int64(p.X))
+			w.WriteString("y")
+			return w.WriteInt(int64(p.Y))
+		},
+		func(r *Reader) (point, error) {
+			var p point
+			n, err := r.ReadMapHeader()
+			for range n {
+				var key string
+				var value int64
+				if key, err = r.ReadString(); err == nil {
+					value, err = r.ReadInt()
+				}
+				if err != nil {
+					return p, err
+				}
+				if key == "x" {
+					p.X = int(value)
+				} else {
+					p.Y = int(value)
+				}
+			}
+			return p, err
+		})
+	s := set.NewWithValues[string, point]()
+	s.AddWithValue("origin", point{})
+	var buf bytes.Buffer
+
+	// When
+	err := NewEncoder[string, point](&buf, Map, nil, codec).Encode(s)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "81"+"a66f726967696e"+"82"+"a17800"+"a17900", hex.EncodeToString(buf.Bytes()))
+
+	// When
+	decoded := set.NewWithValues[string, point]()
+	err = NewDecoder[string, point](&buf, nil, codec, set.StrictDuplicates).Decode(decoded)
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, s.Equals(decoded))
+
+	// When using the default codec
+	_, err = Marshal(s, Map)
+	// Then
+	assert.EqualError(t, err, "cannot encode set to MessagePack: cannot encode msgpack.point as MessagePack, it needs a custom codec")
+}
+
+func TestShouldStreamSets(t *testing.T) {
+	// Given
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		encoder := NewEncoder[int, set.InternalEmptyType](pipeWriter, Array, nil, nil)
+		large := set.NewWithoutValues[int]()
+		for i := range 100_000 {
+			large.AddWithoutValue(i)
+		}
+		encoder.Encode(large)
+		encoder.Encode(nil)
+		small := set.NewWithoutValues[int]()
+		small.AddWithoutValue(1)
+		encoder.Encode(small)
+		pipeWriter.Close()
+	}()
+	decoder := NewDecoder[int, set.InternalEmptyType](pipeReader, nil, nil, set.StrictDuplicates)
+
+	// When
+	large := set.NewWithoutValues[int]()
+	err1 := decoder.Decode(large)
+	unchanged := set.NewWithoutValues[int]()
+	err2 := decoder.Decode(unchanged)
+	small := set.NewWithoutValues[int]()
+	err3 := decoder.Decode(small)
+	err4 := decoder.Decode(small)
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Equal(t, 100_000, large.Size())
+	assert.Nil(t, err2)
+	assert.Equal(t, 0, unchanged.Size())
+	assert.Nil(t, err3)
+	assert.Equal(t, []int{1}, small.List())
+	assert.EqualError(t, err4, "cannot decode set from MessagePack: EOF")
+}
+
+func TestShouldFailToEncodeSetWithValuesAsArray(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+
+	// When
+	_, err := Marshal(s, Array)
+
+	// Then
+	assert.EqualError(t, err, "cannot encode set to MessagePack: a set with values cannot be written as array, use Map")
+}
+
+func TestShouldFailToDecodeInvalidSets(t *testing.T) {
+	for data, expected := range map[string]string{
+		"a161":          "cannot decode set from MessagePack: invalid MessagePack data: expected array or map but found string at offset 0",
+		"92" + "a161":   "cannot decode set from MessagePack: invalid MessagePack data: expected integer but found string at offset 1",
+		"92" + "01":     "cannot decode set from MessagePack: unexpected EOF",
+		"93" + "010201": "cannot decode set from MessagePack: duplicate element 1 at index 2 (offset 3)",
+		"91" + "cd01f4": "cannot decode set from MessagePack: invalid MessagePack data: integer 500 at offset 1 overflows int8",
+		"81" + "01c3":   "",
+	} {
+		// Given
+		s := set.NewWithoutValues[int8]()
+
+		// When
+		err := NewDecoder[int8, set.InternalEmptyType](bytes.NewReader(fromHex(data)), nil, nil, set.StrictDuplicates).Decode(s)
+
+		// Then
+		if expected == "" {
+			// values of a map are skipped for sets without values
+			assert.Nil(t, err)
+			assert.Equal(t, []int8{1}, s.List())
+		} else {
+			assert.EqualError(t, err, expected)
+		}
+	}
+}
+
+func fromHex(hexData string) []byte {
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func reader(hexData string) *Reader {
+	return NewReader(bytes.NewReader(fromHex(hexData)))
+}
+
+func assertRead[E any](t *testing.T, expected E, read func() (E, error)) {
+	actual, err := read()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
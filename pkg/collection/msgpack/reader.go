@@ -0,0 +1,321 @@
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrInvalidFormat is returned (wrapped) when reading data that is not valid MessagePack or does not have the expected type.
+var ErrInvalidFormat = errors.New("invalid MessagePack data")
+
+// maxNesting is the maximum depth of nested arrays and maps that Skip accepts.
+const maxNesting = 1000
+
+// kind is the type of a MessagePack object, independent of the format used to encode it.
+type kind int
+
+const (
+	kindNil kind = iota
+	kindBool
+	kindUint
+	kindInt
+	kindFloat32
+	kindFloat64
+	kindStr
+	kindBin
+	kindArray
+	kindMap
+	kindExt
+)
+
+var kindNames = [...]string{"nil", "boolean", "integer", "integer", "float", "float", "string", "binary", "array", "map", "extension"}
+
+// header is the format of an object together with its value (integers, floats and booleans) or its length (all other types).
+type header struct {
+	kind   kind
+	value  uint64
+	offset int64
+}
+
+// Reader reads single MessagePack objects from an io.Reader. All formats of a type are accepted.
+type Reader struct {
+	r       *bufio.Reader
+	offset  int64
+	scratch [8]byte
+}
+
+// NewReader creates a new reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Offset returns the number of bytes read so far.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+func (r *Reader) peekByte() (byte, error) {
+	b, err := r.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readUint reads a big-endian unsigned integer of n bytes.
+func (r *Reader) readUint(n int) (uint64, error) {
+	clear(r.scratch[:])
+	read, err := io.ReadFull(r.r, r.scratch[8-n:])
+	r.offset += int64(read)
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return binary.BigEndian.Uint64(r.scratch[:]), nil
+}
+
+func (r *Reader) readHeader() (header, error) {
+	h := header{offset: r.offset}
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return h, err
+	}
+	r.offset++
+
+	size := 0
+	switch {
+	case b <= 0x7f:
+		h.kind, h.value = kindUint, uint64(b)
+	case b <= 0x8f:
+		h.kind, h.value = kindMap, uint64(b&0x0f)
+	case b <= 0x9f:
+		h.kind, h.value = kindArray, uint64(b&0x0f)
+	case b <= 0xbf:
+		h.kind, h.value = kindStr, uint64(b&0x1f)
+	case b >= negFix:
+		h.kind, h.value = kindInt, uint64(int64(int8(b)))
+	case b == formatNil:
+		h.kind = kindNil
+	case b == formatFalse, b == formatTrue:
+		h.kind, h.value = kindBool, uint64(b-formatFalse)
+	case b >= formatBin8 && b <= formatBin32:
+		h.kind, size = kindBin, 1<<(b-formatBin8)
+	case b >= formatExt8 && b <= formatExt32:
+		h.kind, size = kindExt, 1<<(b-formatExt8)
+	case b == formatFloat32:
+		h.kind, size = kindFloat32, 4
+	case b == formatFloat64:
+		h.kind, size = kindFloat64, 8
+	case b >= formatUint8 && b <= formatUint64:
+		h.kind, size = kindUint, 1<<(b-formatUint8)
+	case b >= formatInt8 && b <= formatInt64:
+		h.kind, size = kindInt, 1<<(b-formatInt8)
+	case b >= formatFixExt1 && b <= formatFixExt16:
+		h.kind, h.value = kindExt, 1<<(b-formatFixExt1)
+	case b >= formatStr8 && b <= formatStr32:
+		h.kind, size = kindStr, 1<<(b-formatStr8)
+	case b == formatArray16 || b == formatArray32:
+		h.kind, size = kindArray, 2<<(b-formatArray16)
+	case b == formatMap16 || b == formatMap32:
+		h.kind, size = kindMap, 2<<(b-formatMap16)
+	default:
+		return h, fmt.Errorf("%w: unused format 0x%x at offset %d", ErrInvalidFormat, b, h.offset)
+	}
+	if size > 0 {
+		if h.value, err = r.readUint(size); err != nil {
+			return h, err
+		}
+		if h.kind == kindInt {
+			// sign-extend
+			shift := 64 - 8*size
+			h.value = uint64(int64(h.value<<shift) >> shift)
+		}
+	}
+	return h, nil
+}
+
+func (h header) mismatch(expected string) error {
+	return fmt.Errorf("%w: expected %s but found %s at offset %d", ErrInvalidFormat, expected, kindNames[h.kind], h.offset)
+}
+
+// ReadInt reads a signed integer, which may be encoded in any integer format.
+func (r *Reader) ReadInt() (int64, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case h.kind == kindInt:
+		return int64(h.value), nil
+	case h.kind == kindUint && h.value <= math.MaxInt64:
+		return int64(h.value), nil
+	case h.kind == kindUint:
+		return 0, fmt.Errorf("%w: integer %d at offset %d overflows int64", ErrInvalidFormat, h.value, h.offset)
+	default:
+		return 0, h.mismatch("integer")
+	}
+}
+
+// ReadUint reads an unsigned integer, which may be encoded in any integer format.
+func (r *Reader) ReadUint() (uint64, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case h.kind == kindUint, h.kind == kindInt && int64(h.value) >= 0:
+		return h.value, nil
+	case h.kind == kindInt:
+		return 0, fmt.Errorf("%w: negative integer %d at offset %d", ErrInvalidFormat, int64(h.value), h.offset)
+	default:
+		return 0, h.mismatch("integer")
+	}
+}
+
+// ReadFloat reads a floating-point number of single or double precision, or an integer.
+func (r *Reader) ReadFloat() (float64, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	switch h.kind {
+	case kindFloat32:
+		return float64(math.Float32frombits(uint32(h.value))), nil
+	case kindFloat64:
+		return math.Float64frombits(h.value), nil
+	case kindUint:
+		return float64(h.value), nil
+	case kindInt:
+		return float64(int64(h.value)), nil
+	default:
+		return 0, h.mismatch("float")
+	}
+}
+
+// ReadBool reads a boolean.
+func (r *Reader) ReadBool() (bool, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return false, err
+	}
+	if h.kind != kindBool {
+		return false, h.mismatch("boolean")
+	}
+	return h.value == 1, nil
+}
+
+// IsNil reports whether the next object is nil, and skips it if so.
+func (r *Reader) IsNil() (bool, error) {
+	b, err := r.peekByte()
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+	if b != formatNil {
+		return false, nil
+	}
+	r.r.ReadByte()
+	r.offset++
+	return true, nil
+}
+
+// ReadString reads a string, binary data is accepted as well.
+func (r *Reader) ReadString() (string, error) {
+	data, err := r.readRaw("string")
+	return string(data), err
+}
+
+// ReadBytes reads binary data, strings are accepted as well (as written by old versions of MessagePack).
+func (r *Reader) ReadBytes() ([]byte, error) {
+	return r.readRaw("binary")
+}
+
+func (r *Reader) readRaw(expected string) ([]byte, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	if h.kind != kindStr && h.kind != kindBin {
+		return nil, h.mismatch(expected)
+	}
+	var buf bytes.Buffer
+	if err := r.readN(&buf, h.value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readN appends n bytes to buf, growing it while reading, so a corrupt length cannot allocate huge amounts of memory.
+func (r *Reader) readN(buf *bytes.Buffer, n uint64) error {
+	read, err := io.CopyN(buf, r.r, int64(n))
+	r.offset += read
+	return unexpectedEOF(err)
+}
+
+// ReadArrayHeader reads the header of an array and returns its number of elements.
+func (r *Reader) ReadArrayHeader() (int, error) {
+	return r.readContainerHeader(kindArray)
+}
+
+// ReadMapHeader reads the header of a map and returns its number of pairs.
+func (r *Reader) ReadMapHeader() (int, error) {
+	return r.readContainerHeader(kindMap)
+}
+
+func (r *Reader) readContainerHeader(k kind) (int, error) {
+	h, err := r.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	if h.kind != k {
+		return 0, h.mismatch(kindNames[k])
+	}
+	if h.value > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: length %d at offset %d too large", ErrInvalidFormat, h.value, h.offset)
+	}
+	return int(h.value), nil
+}
+
+// Skip skips the next object including all nested objects.
+func (r *Reader) Skip() error {
+	return r.skip(0)
+}
+
+func (r *Reader) skip(depth int) error {
+	if depth > maxNesting {
+		return fmt.Errorf("%w: nesting deeper than %d at offset %d", ErrInvalidFormat, maxNesting, r.offset)
+	}
+	h, err := r.readHeader()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	switch h.kind {
+	case kindStr, kindBin:
+		return r.readN(&bytes.Buffer{}, h.value)
+	case kindExt:
+		// the type byte precedes the data
+		return r.readN(&bytes.Buffer{}, h.value+1)
+	case kindArray, kindMap:
+		items := h.value
+		if h.kind == kindMap {
+			items *= 2
+		}
+		for range items {
+			if err := r.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unexpectedEOF converts io.EOF to io.ErrUnexpectedEOF, used when the end of the input is reached within an object.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package msgpack
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Format bytes of MessagePack (see https://github.com/msgpack/msgpack/blob/master/spec.md).
+const (
+	formatNil      = 0xc0
+	formatFalse    = 0xc2
+	formatTrue     = 0xc3
+	formatBin8     = 0xc4
+	formatBin16    = 0xc5
+	formatBin32    = 0xc6
+	formatExt8     = 0xc7
+	formatExt16    = 0xc8
+	formatExt32    = 0xc9
+	formatFloat32  = 0xca
+	formatFloat64  = 0xcb
+	formatUint8    = 0xcc
+	formatUint16   = 0xcd
+	formatUint32   = 0xce
+	formatUint64   = 0xcf
+	formatInt8     = 0xd0
+	formatInt16    = 0xd1
+	formatInt32    = 0xd2
+	formatInt64    = 0xd3
+	formatFixExt1  = 0xd4
+	formatFixExt16 = 0xd8
+	formatStr8     = 0xd9
+	formatStr16    = 0xda
+	formatStr32    = 0xdb
+	formatArray16  = 0xdc
+	formatArray32  = 0xdd
+	formatMap16    = 0xde
+	formatMap32    = 0xdf
+
+	fixMap   = 0x80
+	fixArray = 0x90
+	fixStr   = 0xa0
+	negFix   = 0xe0
+)
+
+// Writer writes single MessagePack objects to an io.Writer in their shortest form.
+// The output is buffered, call Flush after writing.
+type Writer struct {
+	w       *bufio.Writer
+	scratch [9]byte
+}
+
+// NewWriter creates a new writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) write(b []byte) error {
+	_, err := w.w.Write(b)
+	return err
+}
+
+// WriteNil writes nil.
+func (w *Writer) WriteNil() error {
+	return w.w.WriteByte(formatNil)
+}
+
+// WriteBool writes a boolean.
+func (w *Writer) WriteBool(b bool) error {
+	if b {
+		return w.w.WriteByte(formatTrue)
+	}
+	return w.w.WriteByte(formatFalse)
+}
+
+// WriteUint writes an unsigned integer.
+func (w *Writer) WriteUint(n uint64) error {
+	b := w.scratch[:0]
+	switch {
+	case n <= math.MaxInt8:
+		b = append(b, byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, formatUint8, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, formatUint16), uint16(n))
+	case n <= math.MaxUint32:
+		b = binary.BigEndian.AppendUint32(append(b, formatUint32), uint32(n))
+	default:
+		b = binary.BigEndian.AppendUint64(append(b, formatUint64), n)
+	}
+	return w.write(b)
+}
+
+// WriteInt writes a signed integer.
+func (w *Writer) WriteInt(n int64) error {
+	if n >= 0 {
+		return w.WriteUint(uint64(n))
+	}
+	b := w.scratch[:0]
+	switch {
+	case n >= -32:
+		b = append(b, byte(n))
+	case n >= math.MinInt8:
+		b = append(b, formatInt8, byte(n))
+	case n >= math.MinInt16:
+		b = binary.BigEndian.AppendUint16(append(b, formatInt16), uint16(n))
+	case n >= math.MinInt32:
+		b = binary.BigEndian.AppendUint32(append(b, formatInt32), uint32(n))
+	default:
+		b = binary.BigEndian.AppendUint64(append(b, formatInt64), uint64(n))
+	}
+	return w.write(b)
+}
+
+// WriteFloat writes a floating-point number, as float 32 if that is lossless and as float 64 otherwise.
+func (w *Writer) WriteFloat(f float64) error {
+	b := w.scratch[:0]
+	if f32 := float32(f); float64(f32) == f {
+		b = binary.BigEndian.AppendUint32(append(b, formatFloat32), math.Float32bits(f32))
+	} else {
+		b = binary.BigEndian.AppendUint64(append(b, formatFloat64), math.Float64bits(f))
+	}
+	return w.write(b)
+}
+
+// WriteString writes a string.
+func (w *Writer) WriteString(s string) error {
+	if err := w.writeHeader(len(s), fixStr, 32, formatStr8, formatStr16, formatStr32); err != nil {
+		return err
+	}
+	_, err := w.w.WriteString(s)
+	return err
+}
+
+// WriteBytes writes a byte slice as binary.
+func (w *Writer) WriteBytes(data []byte) error {
+	if err := w.writeHeader(len(data), 0, 0, formatBin8, formatBin16, formatBin32); err != nil {
+		return err
+	}
+	return w.write(data)
+}
+
+// WriteArrayHeader writes the header of an array with n elements, which have to be written next.
+func (w *Writer) WriteArrayHeader(n int) error {
+	return w.writeHeader(n, fixArray, 16, 0, formatArray16, formatArray32)
+}
+
+// WriteMapHeader writes the header of a map with n pairs, whose keys and values have to be written next (alternately).
+func (w *Writer) WriteMapHeader(n int) error {
+	return w.writeHeader(n, fixMap, 16, 0, formatMap16, formatMap32)
+}
+
+// writeHeader writes a length in the shortest of the given formats, a limit or format of 0 means that the format does not exist.
+func (w *Writer) writeHeader(n int, fix byte, fixLimit int, format8, format16, format32 byte) error {
+	b := w.scratch[:0]
+	switch {
+	case n < 0 || uint64(n) > math.MaxUint32:
+		return fmt.Errorf("cannot write length %d as MessagePack", n)
+	case n < fixLimit:
+		b = append(b, fix|byte(n))
+	case format8 != 0 && n <= math.MaxUint8:
+		b = append(b, format8, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, format16), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, format32), uint32(n))
+	}
+	return w.write(b)
+}
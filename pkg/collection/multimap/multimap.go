@@ -0,0 +1,330 @@
+// A map from keys to sets of values (multimap).
+package multimap
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/order"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// MultiMap maps keys of type K to sets of values of type V. Each pair of a key and one of its values is an entry.
+// Sets of values are created when the first value of a key is put and removed together with the last value of a key,
+// so a key is contained in the multimap if and only if it has at least one value.
+// A MultiMap is not safe for concurrent use.
+type MultiMap[K comparable, V comparable] interface {
+	Put(K, V) bool
+	Remove(K, V) bool
+	RemoveAll(K) set.Set[V, set.InternalEmptyType]
+	Clear()
+
+	Get(K) set.Set[V, set.InternalEmptyType]
+	ContainsKey(K) bool
+	ContainsEntry(K, V) bool
+	Size() int
+	KeyCount() int
+	Keys() iter.Seq[K]
+	All() iter.Seq2[K, V]
+	Equals(MultiMap[K, V]) bool
+	String() string
+
+	Inverse() MultiMap[V, K]
+	Copy() MultiMap[K, V]
+	Filter(func(K, V) bool) MultiMap[K, V]
+}
+
+type multiMap[K comparable, V comparable] struct {
+	groups map[K]set.Set[V, set.InternalEmptyType]
+	// size is the number of entries
+	size int
+}
+
+// New creates a new, empty multimap.
+func New[K comparable, V comparable]() MultiMap[K, V] {
+	return newMultiMap[K, V]()
+}
+
+func newMultiMap[K comparable, V comparable]() *multiMap[K, V] {
+	return &multiMap[K, V]{groups: make(map[K]set.Set[V, set.InternalEmptyType])}
+}
+
+// Put adds the value to the values of the key and returns true, or returns false if the entry is already contained.
+func (m *multiMap[K, V]) Put(key K, value V) bool {
+	group, ok := m.groups[key]
+	if !ok {
+		group = set.NewWithoutValues[V]()
+		m.groups[key] = group
+	} else if group.Contains(value) {
+		return false
+	}
+	group.AddWithoutValue(value)
+	m.size++
+	return true
+}
+
+// Remove removes the value from the values of the key and returns true, or returns false if the entry is not contained.
+// The key is removed together with its last value.
+func (m *multiMap[K, V]) Remove(key K, value V) bool {
+	group, ok := m.groups[key]
+	if !ok || !group.Contains(value) {
+		return false
+	}
+	group.Remove(value)
+	m.size--
+	if group.Size() == 0 {
+		delete(m.groups, key)
+	}
+	return true
+}
+
+// RemoveAll removes the key with all its values and returns the removed values (an empty set if the key is not contained).
+func (m *multiMap[K, V]) RemoveAll(key K) set.Set[V, set.InternalEmptyType] {
+	group, ok := m.groups[key]
+	if !ok {
+		return set.NewWithoutValues[V]()
+	}
+	delete(m.groups, key)
+	m.size -= group.Size()
+	return group
+}
+
+// Clear removes all entries from the multimap.
+func (m *multiMap[K, V]) Clear() {
+	clear(m.groups)
+	m.size = 0
+}
+
+// Get returns a new set containing the values of the key, which is empty if the key is not contained.
+// Changing the returned set does not change the multimap.
+func (m *multiMap[K, V]) Get(key K) set.Set[V, set.InternalEmptyType] {
+	if group, ok := m.groups[key]; ok {
+		return group.Copy()
+	}
+	return set.NewWithoutValues[V]()
+}
+
+// ContainsKey checks if the key has at least one value.
+func (m *multiMap[K, V]) ContainsKey(key K) bool {
+	_, ok := m.groups[key]
+	return ok
+}
+
+// ContainsEntry checks if the value is one of the values of the key.
+func (m *multiMap[K, V]) ContainsEntry(key K, value V) bool {
+	group, ok := m.groups[key]
+	return ok && group.Contains(value)
+}
+
+// Size returns the number of entries, i.e. the sum of the numbers of values of all keys.
+func (m *multiMap[K, V]) Size() int {
+	return m.size
+}
+
+// KeyCount returns the number of keys.
+func (m *multiMap[K, V]) KeyCount() int {
+	return len(m.groups)
+}
+
+// Keys returns an iterator over the keys. The order is not defined.
+func (m *multiMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range m.groups {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over all entries, yielding each key once for each of its values.
+// The values of a key are yielded one after the other, the order of the keys and of the values is not defined.
+func (m *multiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, group := range m.groups {
+			for value := range group.Elements() {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Equals checks if both multimaps contain the same entries. If otherMultiMap is nil, true is returned if this multimap is empty.
+func (m *multiMap[K, V]) Equals(otherMultiMap MultiMap[K, V]) bool {
+	return equals[K, V](m, otherMultiMap)
+}
+
+// String returns a string representation of the multimap in the form {a: {1, 2}, b: {3}}.
+// Keys and values are sorted if their types are ordered, the order is not defined otherwise.
+func (m *multiMap[K, V]) String() string {
+	return toString[K, V](m)
+}
+
+// Inverse returns a view of the multimap mapping each value to the set of its keys. The view is backed by this multimap:
+// changes of the multimap are visible in the view, and changes of the view (like Put and Remove) change the multimap.
+// Get, ContainsKey and RemoveAll of the view take time proportional to the number of keys of this multimap,
+// KeyCount and Keys to the number of its entries. The inverse of the view is this multimap.
+func (m *multiMap[K, V]) Inverse() MultiMap[V, K] {
+	return &inverse[V, K]{original: m}
+}
+
+// Copy returns a new multimap containing all entries of this multimap.
+func (m *multiMap[K, V]) Copy() MultiMap[K, V] {
+	return filter[K, V](m, nil)
+}
+
+// Filter returns a new multimap containing the entries for which filterFunc returns true. The multimap is not changed.
+func (m *multiMap[K, V]) Filter(filterFunc func(K, V) bool) MultiMap[K, V] {
+	return filter[K, V](m, filterFunc)
+}
+
+// inverse is a view of the original multimap with keys and values swapped.
+type inverse[K comparable, V comparable] struct {
+	original *multiMap[V, K]
+}
+
+func (i *inverse[K, V]) Put(key K, value V) bool {
+	return i.original.Put(value, key)
+}
+
+func (i *inverse[K, V]) Remove(key K, value V) bool {
+	return i.original.Remove(value, key)
+}
+
+func (i *inverse[K, V]) RemoveAll(key K) set.Set[V, set.InternalEmptyType] {
+	removed := i.Get(key)
+	for value := range removed.Elements() {
+		i.original.Remove(value, key)
+	}
+	return removed
+}
+
+func (i *inverse[K, V]) Clear() {
+	i.original.Clear()
+}
+
+func (i *inverse[K, V]) Get(key K) set.Set[V, set.InternalEmptyType] {
+	values := set.NewWithoutValues[V]()
+	for value, group := range i.original.groups {
+		if group.Contains(key) {
+			values.AddWithoutValue(value)
+		}
+	}
+	return values
+}
+
+func (i *inverse[K, V]) ContainsKey(key K) bool {
+	for _, group := range i.original.groups {
+		if group.Contains(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *inverse[K, V]) ContainsEntry(key K, value V) bool {
+	return i.original.ContainsEntry(value, key)
+}
+
+func (i *inverse[K, V]) Size() int {
+	return i.original.size
+}
+
+func (i *inverse[K, V]) KeyCount() int {
+	count := 0
+	for range i.Keys() {
+		count++
+	}
+	return count
+}
+
+func (i *inverse[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		seen := make(map[K]struct{})
+		for _, group := range i.original.groups {
+			for key := range group.Elements() {
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (i *inverse[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for value, key := range i.original.All() {
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+func (i *inverse[K, V]) Equals(otherMultiMap MultiMap[K, V]) bool {
+	return equals[K, V](i, otherMultiMap)
+}
+
+func (i *inverse[K, V]) String() string {
+	return toString[K, V](i)
+}
+
+func (i *inverse[K, V]) Inverse() MultiMap[V, K] {
+	return i.original
+}
+
+func (i *inverse[K, V]) Copy() MultiMap[K, V] {
+	return filter[K, V](i, nil)
+}
+
+func (i *inverse[K, V]) Filter(filterFunc func(K, V) bool) MultiMap[K, V] {
+	return filter[K, V](i, filterFunc)
+}
+
+// equals checks if both multimaps contain the same entries, a nil multimap is treated as empty.
+func equals[K comparable, V comparable](m, otherMultiMap MultiMap[K, V]) bool {
+	if otherMultiMap == nil {
+		return m.Size() == 0
+	}
+	if m.Size() != otherMultiMap.Size() {
+		return false
+	}
+	for key, value := range m.All() {
+		if !otherMultiMap.ContainsEntry(key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns a new multimap containing the entries of m for which filterFunc returns true (all entries if it is nil).
+func filter[K comparable, V comparable](m MultiMap[K, V], filterFunc func(K, V) bool) MultiMap[K, V] {
+	result := newMultiMap[K, V]()
+	for key, value := range m.All() {
+		if filterFunc == nil || filterFunc(key, value) {
+			result.Put(key, value)
+		}
+	}
+	return result
+}
+
+// toString returns the string representation of the multimap, see the String method.
+func toString[K comparable, V comparable](m MultiMap[K, V]) string {
+	keys := slices.Collect(m.Keys())
+	if compare := order.Compare[K](); compare != nil {
+		slices.SortFunc(keys, compare)
+	}
+	strGroups := make([]string, 0, len(keys))
+	for _, key := range keys {
+		strGroups = append(strGroups, fmt.Sprintf("%v: %v", key, m.Get(key)))
+	}
+	return "{" + strings.Join(strGroups, ", ") + "}"
+}
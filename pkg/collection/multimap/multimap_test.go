@@ -0,0 +1,177 @@
+package multimap
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func newTestMultiMap() MultiMap[string, int] {
+	m := New[string, int]()
+	m.Put("a", 1)
+	m.Put("a", 2)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	return m
+}
+
+func TestShouldPutAndGetValues(t *testing.T) {
+	// Given
+	m := New[string, int]()
+
+	// When
+	added1 := m.Put("a", 1)
+	added2 := m.Put("a", 2)
+	added3 := m.Put("a", 1)
+	added4 := m.Put("b", 3)
+
+	// Then
+	assert.True(t, added1)
+	assert.True(t, added2)
+	assert.False(t, added3)
+	assert.True(t, added4)
+	assert.Equal(t, 3, m.Size())
+	assert.Equal(t, 2, m.KeyCount())
+	assert.Equal(t, []int{1, 2}, slices.Sorted(m.Get("a").Elements()))
+	assert.Equal(t, 0, m.Get("x").Size())
+	assert.True(t, m.ContainsKey("a"))
+	assert.False(t, m.ContainsKey("x"))
+	assert.True(t, m.ContainsEntry("a", 2))
+	assert.False(t, m.ContainsEntry("b", 2))
+	assert.Equal(t, "{a: {1, 2}, b: {3}}", m.String())
+}
+
+func TestShouldNotChangeMultiMapByReturnedSet(t *testing.T) {
+	// Given
+	m := newTestMultiMap()
+
+	// When
+	values := m.Get("a")
+	values.AddWithoutValue(99)
+	values.Remove(1)
+
+	// Then
+	assert.Equal(t, []int{1, 2}, slices.Sorted(m.Get("a").Elements()))
+	assert.Equal(t, 4, m.Size())
+}
+
+func TestShouldRemoveEmptyKeys(t *testing.T) {
+	// Given
+	m := newTestMultiMap()
+
+	// When
+	removed1 := m.Remove("b", 2)
+	removed2 := m.Remove("b", 2)
+	removed3 := m.Remove("a", 3)
+
+	// Then
+	assert.True(t, removed1)
+	assert.False(t, removed2)
+	assert.False(t, removed3)
+	assert.False(t, m.ContainsKey("b"))
+	assert.Equal(t, 2, m.KeyCount())
+	assert.Equal(t, 3, m.Size())
+
+	// When
+	values := m.RemoveAll("a")
+	none := m.RemoveAll("x")
+	// Then
+	assert.Equal(t, []int{1, 2}, slices.Sorted(values.Elements()))
+	assert.Equal(t, 0, none.Size())
+	assert.Equal(t, "{c: {3}}", m.String())
+	assert.Equal(t, 1, m.Size())
+
+	// When
+	m.Clear()
+	// Then
+	assert.Equal(t, 0, m.Size())
+	assert.Equal(t, 0, m.KeyCount())
+}
+
+func TestShouldIterateOverKeysAndEntries(t *testing.T) {
+	// Given
+	m := newTestMultiMap()
+
+	// When
+	keys := slices.Sorted(m.Keys())
+	entries := make(map[[2]any]bool)
+	for key, value := range m.All() {
+		entries[[2]any{key, value}] = true
+	}
+
+	// Then
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.Equal(t, map[[2]any]bool{{"a", 1}: true, {"a", 2}: true, {"b", 2}: true, {"c", 3}: true}, entries)
+}
+
+func TestShouldProvideLiveInverseView(t *testing.T) {
+	// Given
+	m := newTestMultiMap()
+
+	// When
+	inverse := m.Inverse()
+
+	// Then
+	assert.Equal(t, "{1: {a}, 2: {a, b}, 3: {c}}", inverse.String())
+	assert.Equal(t, 4, inverse.Size())
+	assert.Equal(t, 3, inverse.KeyCount())
+	assert.Equal(t, []int{1, 2, 3}, slices.Sorted(inverse.Keys()))
+	assert.True(t, inverse.ContainsKey(2))
+	assert.False(t, inverse.ContainsKey(4))
+	assert.True(t, inverse.ContainsEntry(2, "b"))
+	assert.True(t, inverse.Inverse().Equals(m))
+
+	// When the multimap is changed
+	m.Put("d", 1)
+	// Then the view reflects the change
+	assert.Equal(t, []string{"a", "d"}, slices.Sorted(inverse.Get(1).Elements()))
+
+	// When the view is changed
+	inverse.Put(4, "a")
+	removed := inverse.RemoveAll(2)
+	// Then the multimap is changed
+	assert.Equal(t, []string{"a", "b"}, slices.Sorted(removed.Elements()))
+	assert.Equal(t, "{a: {1, 4}, c: {3}, d: {1}}", m.String())
+	assert.True(t, inverse.Remove(3, "c"))
+	assert.False(t, m.ContainsKey("c"))
+	assert.Equal(t, map[int]string{1: "d", 4: "a"}, maps.Collect(inverse.Filter(func(k int, v string) bool { return v != "a" || k == 4 }).All()))
+}
+
+func TestShouldFilterAndCopyIntoNewMultiMaps(t *testing.T) {
+	// Given
+	m := newTestMultiMap()
+
+	// When
+	even := m.Filter(func(_ string, value int) bool { return value%2 == 0 })
+	copied := m.Copy()
+	copied.Put("x", 0)
+
+	// Then
+	assert.Equal(t, "{a: {2}, b: {2}}", even.String())
+	assert.Equal(t, 2, even.Size())
+	assert.Equal(t, 4, m.Size())
+	assert.False(t, m.Equals(copied))
+	copied.RemoveAll("x")
+	assert.True(t, m.Equals(copied))
+	assert.False(t, m.Equals(nil))
+	assert.True(t, New[string, int]().Equals(nil))
+	assert.True(t, New[string, int]().Inverse().Equals(nil))
+}
+
+func TestShouldGroupValuesIntoSets(t *testing.T) {
+	// Given
+	words := []string{"apple", "avocado", "banana", "apple"}
+	m := New[byte, string]()
+
+	// When
+	for _, word := range words {
+		m.Put(word[0], word)
+	}
+
+	// Then
+	assert.True(t, m.Get('a').Equals(set.Collect(slices.Values([]string{"apple", "avocado"}))))
+	assert.Equal(t, 3, m.Size())
+}
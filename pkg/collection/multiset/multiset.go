@@ -0,0 +1,295 @@
+// A multiset (bag) of elements having multiplicities, with the operations of bag algebra.
+package multiset
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/order"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// Multiset is a collection of elements of type T, where each element can occur more than once.
+// The number of occurrences of an element is its count (multiplicity), elements with count 0 are not contained in the multiset.
+// Unlike a Set using its values as hand-maintained counters, the operations of a Multiset combine the counts:
+// Unite takes the maximum, Sum adds, Intersect takes the minimum and Subtract subtracts the counts (down to 0).
+// A Multiset is not safe for concurrent use.
+type Multiset[T comparable] interface {
+	Add(T, int)
+	Remove(T, int) int
+	Clear()
+
+	Count(T) int
+	Contains(T) bool
+	Size() int
+	Distinct() int
+	Support() set.Set[T, set.InternalEmptyType]
+	All() iter.Seq2[T, int]
+	Elements() iter.Seq[T]
+	MostCommon(int) []Entry[T]
+	Equals(Multiset[T]) bool
+	IsSubBag(Multiset[T]) bool
+	String() string
+
+	Copy() Multiset[T]
+	Unite(Multiset[T]) Multiset[T]
+	Sum(Multiset[T]) Multiset[T]
+	Intersect(Multiset[T]) Multiset[T]
+	Subtract(Multiset[T]) Multiset[T]
+}
+
+// Entry is an element of a multiset together with its count.
+type Entry[T comparable] struct {
+	Element T
+	Count   int
+}
+
+type multiset[T comparable] struct {
+	counts map[T]int
+	// size is the sum of all counts
+	size int
+}
+
+// New creates a new, empty multiset.
+func New[T comparable]() Multiset[T] {
+	return &multiset[T]{counts: make(map[T]int)}
+}
+
+// FromSlice creates a new multiset containing each element of the slice as often as it occurs in the slice.
+func FromSlice[T comparable](elements []T) Multiset[T] {
+	m := New[T]()
+	for _, elem := range elements {
+		m.Add(elem, 1)
+	}
+	return m
+}
+
+// FromSet creates a new multiset containing each element of the set once (ignoring the values).
+// A nil set is treated as an empty set.
+func FromSet[T comparable, V any](s set.Set[T, V]) Multiset[T] {
+	m := New[T]()
+	if s != nil {
+		for elem := range s.Elements() {
+			m.Add(elem, 1)
+		}
+	}
+	return m
+}
+
+// FromCounts creates a new multiset containing each key of the map as often as its value says.
+// It panics if a count is negative.
+func FromCounts[T comparable](counts map[T]int) Multiset[T] {
+	m := New[T]()
+	for elem, n := range counts {
+		m.Add(elem, n)
+	}
+	return m
+}
+
+// Add adds n occurrences of the element to the multiset. It panics if n is negative.
+func (m *multiset[T]) Add(elem T, n int) {
+	if n < 0 {
+		panic(fmt.Sprintf("cannot add %v %d times to multiset, count must not be negative", elem, n))
+	}
+	if n == 0 {
+		return
+	}
+	m.counts[elem] += n
+	m.size += n
+}
+
+// Remove removes up to n occurrences of the element from the multiset and returns the number of removed occurrences,
+// which is less than n if the element occurs less than n times. It panics if n is negative.
+func (m *multiset[T]) Remove(elem T, n int) int {
+	if n < 0 {
+		panic(fmt.Sprintf("cannot remove %v %d times from multiset, count must not be negative", elem, n))
+	}
+	count := m.counts[elem]
+	removed := min(n, count)
+	if removed == count {
+		delete(m.counts, elem)
+	} else {
+		m.counts[elem] = count - removed
+	}
+	m.size -= removed
+	return removed
+}
+
+// Clear removes all elements from the multiset.
+func (m *multiset[T]) Clear() {
+	clear(m.counts)
+	m.size = 0
+}
+
+// Count returns the number of occurrences of the element, 0 if it is not contained in the multiset.
+func (m *multiset[T]) Count(elem T) int {
+	return m.counts[elem]
+}
+
+// Contains checks if the element occurs at least once in the multiset.
+func (m *multiset[T]) Contains(elem T) bool {
+	_, ok := m.counts[elem]
+	return ok
+}
+
+// Size returns the number of elements of the multiset counting all occurrences, i.e. the sum of all counts.
+func (m *multiset[T]) Size() int {
+	return m.size
+}
+
+// Distinct returns the number of distinct elements of the multiset.
+func (m *multiset[T]) Distinct() int {
+	return len(m.counts)
+}
+
+// Support returns a new set containing the distinct elements of the multiset.
+func (m *multiset[T]) Support() set.Set[T, set.InternalEmptyType] {
+	support := set.NewWithoutValues[T]()
+	for elem := range m.counts {
+		support.AddWithoutValue(elem)
+	}
+	return support
+}
+
+// All returns an iterator over the distinct elements of the multiset and their counts. The order is not defined.
+func (m *multiset[T]) All() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		for elem, count := range m.counts {
+			if !yield(elem, count) {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over the elements of the multiset, yielding each element as often as it occurs.
+// The occurrences of an element are yielded one after the other, the order of the elements is not defined.
+func (m *multiset[T]) Elements() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem, count := range m.counts {
+			for range count {
+				if !yield(elem) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MostCommon returns the k elements with the highest counts in descending order of their counts, or all elements if k is
+// negative or greater than the number of distinct elements. Elements having the same count are in ascending order
+// if T is an ordered type, and in an undefined order otherwise.
+func (m *multiset[T]) MostCommon(k int) []Entry[T] {
+	entries := m.entries()
+	slices.SortStableFunc(entries, func(a, b Entry[T]) int { return cmp.Compare(b.Count, a.Count) })
+	if k >= 0 && k < len(entries) {
+		entries = entries[:k]
+	}
+	return entries
+}
+
+// entries returns the elements and their counts, sorted by the elements if T is an ordered type.
+func (m *multiset[T]) entries() []Entry[T] {
+	entries := make([]Entry[T], 0, len(m.counts))
+	for elem, count := range m.counts {
+		entries = append(entries, Entry[T]{Element: elem, Count: count})
+	}
+	if compare := order.Compare[T](); compare != nil {
+		slices.SortFunc(entries, func(a, b Entry[T]) int { return compare(a.Element, b.Element) })
+	}
+	return entries
+}
+
+// Equals checks if both multisets contain the same elements with the same counts.
+// If otherMultiset is nil, true is returned if this multiset is empty.
+func (m *multiset[T]) Equals(otherMultiset Multiset[T]) bool {
+	if otherMultiset == nil {
+		return m.size == 0
+	}
+	return m.size == otherMultiset.Size() && m.Distinct() == otherMultiset.Distinct() && m.IsSubBag(otherMultiset)
+}
+
+// IsSubBag checks if this multiset is a sub-bag of otherMultiset, i.e. no element occurs more often in this multiset
+// than in otherMultiset. If otherMultiset is nil, true is returned if this multiset is empty.
+func (m *multiset[T]) IsSubBag(otherMultiset Multiset[T]) bool {
+	if otherMultiset == nil {
+		return m.size == 0
+	}
+	if m.size > otherMultiset.Size() {
+		return false
+	}
+	for elem, count := range m.counts {
+		if count > otherMultiset.Count(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the multiset in the form {a: 2, b: 1}.
+// The elements are sorted if T is an ordered type, the order is not defined otherwise.
+func (m *multiset[T]) String() string {
+	strElems := make([]string, 0, len(m.counts))
+	for _, e := range m.entries() {
+		strElems = append(strElems, fmt.Sprintf("%v: %d", e.Element, e.Count))
+	}
+	return "{" + strings.Join(strElems, ", ") + "}"
+}
+
+// Copy returns a new multiset containing all elements of this multiset with the same counts.
+func (m *multiset[T]) Copy() Multiset[T] {
+	return &multiset[T]{counts: maps.Clone(m.counts), size: m.size}
+}
+
+// Unite returns a new multiset containing the elements of both multisets, each with the maximum of its counts.
+// If otherMultiset is nil, a copy of this multiset is returned. Neither this multiset nor otherMultiset are changed.
+func (m *multiset[T]) Unite(otherMultiset Multiset[T]) Multiset[T] {
+	return m.combine(otherMultiset, func(a, b int) int { return max(a, b) })
+}
+
+// Sum returns a new multiset containing the elements of both multisets, each with the sum of its counts.
+// If otherMultiset is nil, a copy of this multiset is returned. Neither this multiset nor otherMultiset are changed.
+func (m *multiset[T]) Sum(otherMultiset Multiset[T]) Multiset[T] {
+	return m.combine(otherMultiset, func(a, b int) int { return a + b })
+}
+
+// Intersect returns a new multiset containing the elements occurring in both multisets, each with the minimum of its counts.
+// If otherMultiset is nil, a new empty multiset is returned. Neither this multiset nor otherMultiset are changed.
+func (m *multiset[T]) Intersect(otherMultiset Multiset[T]) Multiset[T] {
+	if otherMultiset == nil {
+		return New[T]()
+	}
+	return m.combine(otherMultiset, func(a, b int) int { return min(a, b) })
+}
+
+// Subtract returns a new multiset containing the elements of this multiset, each with its count reduced by its count
+// in otherMultiset. Elements occurring at least as often in otherMultiset are not contained in the result.
+// If otherMultiset is nil, a copy of this multiset is returned. Neither this multiset nor otherMultiset are changed.
+func (m *multiset[T]) Subtract(otherMultiset Multiset[T]) Multiset[T] {
+	return m.combine(otherMultiset, func(a, b int) int { return max(a-b, 0) })
+}
+
+// combine returns a new multiset containing each element of both multisets with the count computed by combine
+// from its counts in this multiset and otherMultiset (0 if it is not contained). A nil otherMultiset is treated as empty.
+func (m *multiset[T]) combine(otherMultiset Multiset[T], combine func(int, int) int) Multiset[T] {
+	result := New[T]()
+	for elem, count := range m.counts {
+		other := 0
+		if otherMultiset != nil {
+			other = otherMultiset.Count(elem)
+		}
+		result.Add(elem, combine(count, other))
+	}
+	if otherMultiset != nil {
+		for elem, count := range otherMultiset.All() {
+			if !m.Contains(elem) {
+				result.Add(elem, combine(0, count))
+			}
+		}
+	}
+	return result
+}
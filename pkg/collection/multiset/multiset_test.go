@@ -0,0 +1,176 @@
+package multiset
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func TestShouldAddAndRemoveOccurrences(t *testing.T) {
+	// Given
+	m := New[string]()
+
+	// When
+	m.Add("apple", 3)
+	m.Add("banana", 1)
+	m.Add("apple", 2)
+	m.Add("cherry", 0)
+
+	// Then
+	assert.Equal(t, 5, m.Count("apple"))
+	assert.Equal(t, 1, m.Count("banana"))
+	assert.Equal(t, 0, m.Count("cherry"))
+	assert.False(t, m.Contains("cherry"))
+	assert.Equal(t, 6, m.Size())
+	assert.Equal(t, 2, m.Distinct())
+
+	// When
+	removed1 := m.Remove("apple", 2)
+	removed2 := m.Remove("banana", 5)
+	removed3 := m.Remove("cherry", 1)
+
+	// Then
+	assert.Equal(t, 2, removed1)
+	assert.Equal(t, 1, removed2)
+	assert.Equal(t, 0, removed3)
+	assert.Equal(t, 3, m.Count("apple"))
+	assert.False(t, m.Contains("banana"))
+	assert.Equal(t, 3, m.Size())
+	assert.Equal(t, 1, m.Distinct())
+	assert.Equal(t, "{apple: 3}", m.String())
+
+	// When
+	m.Clear()
+	// Then
+	assert.Equal(t, 0, m.Size())
+	assert.Equal(t, "{}", m.String())
+}
+
+func TestShouldPanicOnNegativeCounts(t *testing.T) {
+	// Given
+	m := New[int]()
+
+	// Expect
+	assert.PanicsWithValue(t, "cannot add 1 -2 times to multiset, count must not be negative", func() { m.Add(1, -2) })
+	assert.PanicsWithValue(t, "cannot remove 1 -1 times from multiset, count must not be negative", func() { m.Remove(1, -1) })
+}
+
+func TestShouldCreateMultisetsFromSlicesSetsAndCounts(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+	s.AddWithValue("a", 10)
+	s.AddWithValue("b", 20)
+
+	// When
+	fromSlice := FromSlice([]string{"a", "b", "a", "c", "a"})
+	fromSet := FromSet(s)
+	fromCounts := FromCounts(map[string]int{"a": 3, "b": 1, "c": 1, "d": 0})
+
+	// Then
+	assert.Equal(t, "{a: 3, b: 1, c: 1}", fromSlice.String())
+	assert.Equal(t, "{a: 1, b: 1}", fromSet.String())
+	assert.True(t, fromSlice.Equals(fromCounts))
+	assert.Equal(t, 0, FromSet[string, int](nil).Size())
+}
+
+func TestShouldIterateOverOccurrences(t *testing.T) {
+	// Given
+	m := FromCounts(map[string]int{"a": 2, "b": 1})
+
+	// When
+	elements := slices.Sorted(m.Elements())
+	counts := make(map[string]int)
+	for elem, count := range m.All() {
+		counts[elem] = count
+	}
+
+	// Then
+	assert.Equal(t, []string{"a", "a", "b"}, elements)
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, counts)
+	assert.True(t, m.Support().Equals(set.Collect(slices.Values([]string{"a", "b"}))))
+	// and iterating stops early
+	yielded := 0
+	for range m.Elements() {
+		if yielded++; yielded == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, yielded)
+}
+
+func TestShouldCombineMultisetsWithBagSemantics(t *testing.T) {
+	// Given
+	m1 := FromCounts(map[string]int{"a": 3, "b": 1, "c": 2})
+	m2 := FromCounts(map[string]int{"a": 1, "b": 4, "d": 1})
+
+	// When
+	union := m1.Unite(m2)
+	sum := m1.Sum(m2)
+	intersection := m1.Intersect(m2)
+	difference := m1.Subtract(m2)
+
+	// Then
+	assert.Equal(t, "{a: 3, b: 4, c: 2, d: 1}", union.String())
+	assert.Equal(t, "{a: 4, b: 5, c: 2, d: 1}", sum.String())
+	assert.Equal(t, "{a: 1, b: 1}", intersection.String())
+	assert.Equal(t, "{a: 2, c: 2}", difference.String())
+	assert.Equal(t, 10, union.Size())
+	assert.Equal(t, 4, difference.Size())
+	// and the multisets are not changed
+	assert.Equal(t, "{a: 3, b: 1, c: 2}", m1.String())
+	assert.Equal(t, "{a: 1, b: 4, d: 1}", m2.String())
+}
+
+func TestShouldCombineWithNilMultiset(t *testing.T) {
+	// Given
+	m := FromSlice([]int{1, 1, 2})
+
+	// Expect
+	assert.True(t, m.Unite(nil).Equals(m))
+	assert.True(t, m.Sum(nil).Equals(m))
+	assert.Equal(t, 0, m.Intersect(nil).Size())
+	assert.True(t, m.Subtract(nil).Equals(m))
+	assert.False(t, m.Equals(nil))
+	assert.True(t, New[int]().Equals(nil))
+	assert.True(t, New[int]().IsSubBag(nil))
+}
+
+func TestShouldCheckSubBags(t *testing.T) {
+	// Given
+	bag := FromCounts(map[string]int{"a": 2, "b": 1})
+
+	// Expect
+	assert.True(t, FromCounts(map[string]int{"a": 2}).IsSubBag(bag))
+	assert.True(t, bag.IsSubBag(bag))
+	assert.False(t, FromCounts(map[string]int{"a": 3}).IsSubBag(bag))
+	assert.False(t, FromCounts(map[string]int{"c": 1}).IsSubBag(bag))
+	assert.False(t, bag.IsSubBag(FromCounts(map[string]int{"a": 2})))
+	assert.False(t, bag.Equals(FromCounts(map[string]int{"a": 2, "c": 1})))
+}
+
+func TestShouldReturnMostCommonElements(t *testing.T) {
+	// Given
+	words := FromSlice([]string{"the", "cat", "and", "the", "dog", "and", "the", "bird"})
+
+	// Expect
+	assert.Equal(t, []Entry[string]{{"the", 3}, {"and", 2}, {"bird", 1}}, words.MostCommon(3))
+	assert.Equal(t, 5, len(words.MostCommon(-1)))
+	assert.Equal(t, 5, len(words.MostCommon(10)))
+	assert.Empty(t, words.MostCommon(0))
+}
+
+func TestShouldCopyMultiset(t *testing.T) {
+	// Given
+	m := FromSlice([]int{1, 2, 2})
+
+	// When
+	c := m.Copy()
+	c.Add(3, 1)
+
+	// Then
+	assert.Equal(t, "{1: 1, 2: 2}", m.String())
+	assert.Equal(t, "{1: 1, 2: 2, 3: 1}", c.String())
+	assert.Equal(t, 4, c.Size())
+}
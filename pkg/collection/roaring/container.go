@@ -0,0 +1,350 @@
+package roaring
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+const (
+	// arrayMaxSize is the maximum cardinality of an array container, containers with more values are bitmap containers.
+	arrayMaxSize = 4096
+	// bitmapWords is the number of 64-bit words of a bitmap container (2^16 bits).
+	bitmapWords = 1024
+)
+
+// container holds the lower 16 bits of all values sharing the same upper 16 bits.
+// Containers returned by add, remove and the binary operations may be of a different type than the receiver,
+// nil is returned for empty containers by the binary operations.
+type container interface {
+	cardinality() int
+	contains(uint16) bool
+	add(uint16) container
+	remove(uint16) container
+	all(yield func(uint16) bool) bool
+	clone() container
+}
+
+// arrayContainer stores up to arrayMaxSize values as a sorted slice.
+type arrayContainer struct {
+	values []uint16
+}
+
+// bitmapContainer stores values as a bitmap of 2^16 bits.
+type bitmapContainer struct {
+	words []uint64
+	card  int
+}
+
+// interval is a run of consecutive values from start to last (inclusive).
+type interval struct {
+	start, last uint16
+}
+
+// runContainer stores values as sorted, non-overlapping, non-adjacent runs of consecutive values.
+type runContainer struct {
+	runs []interval
+}
+
+func newBitmapContainer() *bitmapContainer {
+	return &bitmapContainer{words: make([]uint64, bitmapWords)}
+}
+
+func (a *arrayContainer) cardinality() int {
+	return len(a.values)
+}
+
+func (a *arrayContainer) contains(v uint16) bool {
+	_, found := slices.BinarySearch(a.values, v)
+	return found
+}
+
+func (a *arrayContainer) add(v uint16) container {
+	i, found := slices.BinarySearch(a.values, v)
+	if found {
+		return a
+	}
+	if len(a.values) >= arrayMaxSize {
+		return toBitmap(a).add(v)
+	}
+	a.values = slices.Insert(a.values, i, v)
+	return a
+}
+
+func (a *arrayContainer) remove(v uint16) container {
+	if i, found := slices.BinarySearch(a.values, v); found {
+		a.values = slices.Delete(a.values, i, i+1)
+	}
+	return a
+}
+
+func (a *arrayContainer) all(yield func(uint16) bool) bool {
+	for _, v := range a.values {
+		if !yield(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *arrayContainer) clone() container {
+	return &arrayContainer{values: slices.Clone(a.values)}
+}
+
+func (b *bitmapContainer) cardinality() int {
+	return b.card
+}
+
+func (b *bitmapContainer) contains(v uint16) bool {
+	return b.words[v/64]&(1<<(v%64)) != 0
+}
+
+func (b *bitmapContainer) add(v uint16) container {
+	if !b.contains(v) {
+		b.words[v/64] |= 1 << (v % 64)
+		b.card++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(v uint16) container {
+	if b.contains(v) {
+		b.words[v/64] &^= 1 << (v % 64)
+		b.card--
+	}
+	return normalize(b)
+}
+
+func (b *bitmapContainer) all(yield func(uint16) bool) bool {
+	for i, word := range b.words {
+		for word != 0 {
+			if !yield(uint16(i*64 + bits.TrailingZeros64(word))) {
+				return false
+			}
+			word &= word - 1
+		}
+	}
+	return true
+}
+
+func (b *bitmapContainer) clone() container {
+	return &bitmapContainer{words: slices.Clone(b.words), card: b.card}
+}
+
+// countCardinality recalculates the cardinality from the words.
+func (b *bitmapContainer) countCardinality() {
+	b.card = 0
+	for _, word := range b.words {
+		b.card += bits.OnesCount64(word)
+	}
+}
+
+func (r *runContainer) cardinality() int {
+	card := 0
+	for _, run := range r.runs {
+		card += int(run.last-run.start) + 1
+	}
+	return card
+}
+
+func (r *runContainer) contains(v uint16) bool {
+	// index of the first run starting after v
+	i := sort.Search(len(r.runs), func(i int) bool { return r.runs[i].start > v })
+	return i > 0 && r.runs[i-1].last >= v
+}
+
+// add and remove convert the run container to an array or bitmap container first,
+// run containers are only created by RunOptimize.
+func (r *runContainer) add(v uint16) container {
+	if r.contains(v) {
+		return r
+	}
+	return fromValues(r).add(v)
+}
+
+func (r *runContainer) remove(v uint16) container {
+	if !r.contains(v) {
+		return r
+	}
+	return fromValues(r).remove(v)
+}
+
+func (r *runContainer) all(yield func(uint16) bool) bool {
+	for _, run := range r.runs {
+		for v := int(run.start); v <= int(run.last); v++ {
+			if !yield(uint16(v)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *runContainer) clone() container {
+	return &runContainer{runs: slices.Clone(r.runs)}
+}
+
+func isRun(c container) bool {
+	_, ok := c.(*runContainer)
+	return ok
+}
+
+// toBitmap returns the values of the container as a new bitmap container.
+func toBitmap(c container) *bitmapContainer {
+	b := newBitmapContainer()
+	c.all(func(v uint16) bool {
+		b.words[v/64] |= 1 << (v % 64)
+		return true
+	})
+	b.card = c.cardinality()
+	return b
+}
+
+// toArray returns the values of the container as a new array container.
+func toArray(c container) *arrayContainer {
+	values := make([]uint16, 0, c.cardinality())
+	c.all(func(v uint16) bool {
+		values = append(values, v)
+		return true
+	})
+	return &arrayContainer{values: values}
+}
+
+// fromValues returns the values of the container as an array or a bitmap container depending on the cardinality.
+func fromValues(c container) container {
+	if c.cardinality() > arrayMaxSize {
+		return toBitmap(c)
+	}
+	return toArray(c)
+}
+
+// toRuns returns the values of the container as a new run container.
+func toRuns(c container) *runContainer {
+	r := &runContainer{}
+	c.all(func(v uint16) bool {
+		if n := len(r.runs); n > 0 && int(r.runs[n-1].last)+1 == int(v) {
+			r.runs[n-1].last = v
+		} else {
+			r.runs = append(r.runs, interval{start: v, last: v})
+		}
+		return true
+	})
+	return r
+}
+
+// countRuns returns the number of runs of consecutive values in the container.
+func countRuns(c container) int {
+	if r, ok := c.(*runContainer); ok {
+		return len(r.runs)
+	}
+	runs := 0
+	previous := -2
+	c.all(func(v uint16) bool {
+		if int(v) != previous+1 {
+			runs++
+		}
+		previous = int(v)
+		return true
+	})
+	return runs
+}
+
+// normalize converts bitmap containers with small cardinality to array containers,
+// returns nil for empty containers and the container itself otherwise.
+func normalize(c container) container {
+	switch {
+	case c.cardinality() == 0:
+		return nil
+	case c.cardinality() <= arrayMaxSize:
+		if b, ok := c.(*bitmapContainer); ok {
+			return toArray(b)
+		}
+	}
+	return c
+}
+
+// wordOp combines two containers word by word as bitmaps.
+func wordOp(a, b container, op func(x, y uint64) uint64) container {
+	ba, bb := toBitmap(a), toBitmap(b)
+	for i := range ba.words {
+		ba.words[i] = op(ba.words[i], bb.words[i])
+	}
+	ba.countCardinality()
+	return normalize(ba)
+}
+
+// filter returns the values of the array container for which keep returns true.
+func filter(a *arrayContainer, keep func(uint16) bool) container {
+	values := make([]uint16, 0, len(a.values))
+	for _, v := range a.values {
+		if keep(v) {
+			values = append(values, v)
+		}
+	}
+	return normalize(&arrayContainer{values: values})
+}
+
+// mergeArrays merges two sorted arrays keeping the values for which keep(inA, inB) returns true.
+func mergeArrays(a, b []uint16, keep func(inA, inB bool) bool) container {
+	values := make([]uint16, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j >= len(b) || (i < len(a) && a[i] < b[j]):
+			if keep(true, false) {
+				values = append(values, a[i])
+			}
+			i++
+		case i >= len(a) || a[i] > b[j]:
+			if keep(false, true) {
+				values = append(values, b[j])
+			}
+			j++
+		default:
+			if keep(true, true) {
+				values = append(values, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	return normalize(fromValues(&arrayContainer{values: values}))
+}
+
+func andContainers(a, b container) container {
+	if arrA, ok := a.(*arrayContainer); ok {
+		if arrB, ok := b.(*arrayContainer); ok {
+			return mergeArrays(arrA.values, arrB.values, func(inA, inB bool) bool { return inA && inB })
+		}
+		return filter(arrA, b.contains)
+	}
+	if arrB, ok := b.(*arrayContainer); ok {
+		return filter(arrB, a.contains)
+	}
+	return wordOp(a, b, func(x, y uint64) uint64 { return x & y })
+}
+
+func orContainers(a, b container) container {
+	arrA, okA := a.(*arrayContainer)
+	arrB, okB := b.(*arrayContainer)
+	if okA && okB {
+		return mergeArrays(arrA.values, arrB.values, func(inA, inB bool) bool { return true })
+	}
+	return wordOp(a, b, func(x, y uint64) uint64 { return x | y })
+}
+
+func xorContainers(a, b container) container {
+	arrA, okA := a.(*arrayContainer)
+	arrB, okB := b.(*arrayContainer)
+	if okA && okB {
+		return mergeArrays(arrA.values, arrB.values, func(inA, inB bool) bool { return inA != inB })
+	}
+	return wordOp(a, b, func(x, y uint64) uint64 { return x ^ y })
+}
+
+func andNotContainers(a, b container) container {
+	if arrA, ok := a.(*arrayContainer); ok {
+		return filter(arrA, func(v uint16) bool { return !b.contains(v) })
+	}
+	return wordOp(a, b, func(x, y uint64) uint64 { return x &^ y })
+}
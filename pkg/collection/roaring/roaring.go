@@ -0,0 +1,282 @@
+// A compressed bitmap for sets of uint32 values (roaring bitmap).
+package roaring
+
+import (
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// Bitmap is a compressed set of uint32 values (a roaring bitmap).
+// The values are partitioned by their upper 16 bits into chunks, and each chunk is stored in the container type
+// needing the least memory: a sorted array for sparse chunks, a bitmap for dense chunks, or runs of consecutive values
+// (after calling RunOptimize). This makes a Bitmap orders of magnitude smaller and faster than a Set[uint32, InternalEmptyType]
+// for large sets of IDs, while the set operations And, Or, Xor and AndNot work chunk by chunk on whole words.
+type Bitmap interface {
+	Add(uint32)
+	Remove(uint32)
+	Clear()
+	RunOptimize()
+
+	Size() int
+	List() []uint32
+	Elements() iter.Seq[uint32]
+	Contains(uint32) bool
+	Equals(Bitmap) bool
+	String() string
+
+	Copy() Bitmap
+	And(Bitmap) Bitmap
+	Or(Bitmap) Bitmap
+	Xor(Bitmap) Bitmap
+	AndNot(Bitmap) Bitmap
+
+	ToSet() set.Set[uint32, set.InternalEmptyType]
+
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+type bitmap struct {
+	// keys are the upper 16 bits of the values in ascending order, containers[i] holds the lower 16 bits for keys[i]
+	keys       []uint16
+	containers []container
+}
+
+// New creates a new, empty bitmap.
+func New() Bitmap {
+	return &bitmap{}
+}
+
+// Of creates a new bitmap containing the given values.
+func Of(values ...uint32) Bitmap {
+	bm := &bitmap{}
+	for _, v := range values {
+		bm.Add(v)
+	}
+	return bm
+}
+
+// FromSet creates a new bitmap containing all elements of the given set (ignoring the values).
+// If the set is nil, an empty bitmap is returned.
+func FromSet[V any](s set.Set[uint32, V]) Bitmap {
+	bm := &bitmap{}
+	if s == nil {
+		return bm
+	}
+	for elem := range s.Elements() {
+		bm.Add(elem)
+	}
+	return bm
+}
+
+// asBitmap returns the internal representation of other, or an empty bitmap if other is nil.
+func asBitmap(other Bitmap) *bitmap {
+	if other == nil {
+		return &bitmap{}
+	}
+	return other.(*bitmap)
+}
+
+func split(v uint32) (uint16, uint16) {
+	return uint16(v >> 16), uint16(v)
+}
+
+// index returns the index of the container for the given key and whether it exists.
+func (b *bitmap) index(key uint16) (int, bool) {
+	return slices.BinarySearch(b.keys, key)
+}
+
+// Add adds a value to the bitmap.
+func (b *bitmap) Add(value uint32) {
+	key, low := split(value)
+	i, found := b.index(key)
+	if !found {
+		b.keys = slices.Insert(b.keys, i, key)
+		b.containers = slices.Insert(b.containers, i, container(&arrayContainer{values: []uint16{low}}))
+		return
+	}
+	b.containers[i] = b.containers[i].add(low)
+}
+
+// Remove removes a value from the bitmap.
+func (b *bitmap) Remove(value uint32) {
+	key, low := split(value)
+	i, found := b.index(key)
+	if !found {
+		return
+	}
+	c := b.containers[i].remove(low)
+	if c == nil || c.cardinality() == 0 {
+		b.keys = slices.Delete(b.keys, i, i+1)
+		b.containers = slices.Delete(b.containers, i, i+1)
+		return
+	}
+	b.containers[i] = c
+}
+
+// Clear removes all values from the bitmap.
+func (b *bitmap) Clear() {
+	b.keys = nil
+	b.containers = nil
+}
+
+// RunOptimize converts every container to a run container if that needs less memory,
+// which is the case for chunks consisting of long runs of consecutive values.
+// Run containers that have become inefficient are converted back.
+func (b *bitmap) RunOptimize() {
+	for i, c := range b.containers {
+		// serialized sizes in bytes: 2 bytes per value of an array, 8 KiB for a bitmap and 2+4 bytes per run
+		runSize := 2 + 4*countRuns(c)
+		otherSize := min(2*c.cardinality(), 8*bitmapWords)
+		switch {
+		case runSize < otherSize && !isRun(c):
+			b.containers[i] = toRuns(c)
+		case runSize >= otherSize && isRun(c):
+			b.containers[i] = fromValues(c)
+		}
+	}
+}
+
+// Size returns the number of values in the bitmap.
+// It is calculated from the cardinalities of the containers without materializing the values.
+func (b *bitmap) Size() int {
+	size := 0
+	for _, c := range b.containers {
+		size += c.cardinality()
+	}
+	return size
+}
+
+// List returns all values of the bitmap as a slice in ascending order.
+func (b *bitmap) List() []uint32 {
+	return slices.AppendSeq(make([]uint32, 0, b.Size()), b.Elements())
+}
+
+// Elements returns an iterator over all values of the bitmap in ascending order.
+// The bitmap must not be modified while iterating.
+func (b *bitmap) Elements() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range b.containers {
+			high := uint32(b.keys[i]) << 16
+			if !c.all(func(low uint16) bool { return yield(high | uint32(low)) }) {
+				return
+			}
+		}
+	}
+}
+
+// Contains checks whether or not the given value exists in the bitmap.
+func (b *bitmap) Contains(value uint32) bool {
+	key, low := split(value)
+	i, found := b.index(key)
+	return found && b.containers[i].contains(low)
+}
+
+// Equals checks if this bitmap contains exactly the same values as other.
+// A nil other is treated as an empty bitmap.
+func (b *bitmap) Equals(other Bitmap) bool {
+	o := asBitmap(other)
+	if !slices.Equal(b.keys, o.keys) {
+		return false
+	}
+	for i, c := range b.containers {
+		oc := o.containers[i]
+		if c.cardinality() != oc.cardinality() {
+			return false
+		}
+		if common := andContainers(c, oc); common == nil || common.cardinality() != c.cardinality() {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the bitmap.
+// The values are separated by commas and given in ascending order.
+// If the bitmap is empty, an empty string is returned.
+func (b *bitmap) String() string {
+	strValues := make([]string, 0, b.Size())
+	for v := range b.Elements() {
+		strValues = append(strValues, strconv.FormatUint(uint64(v), 10))
+	}
+	return strings.Join(strValues, ", ")
+}
+
+// Copy returns a new bitmap containing all values of this bitmap.
+func (b *bitmap) Copy() Bitmap {
+	newBitmap := &bitmap{
+		keys:       slices.Clone(b.keys),
+		containers: make([]container, len(b.containers)),
+	}
+	for i, c := range b.containers {
+		newBitmap.containers[i] = c.clone()
+	}
+	return newBitmap
+}
+
+// combine merges the containers of this bitmap and other by key.
+// Containers existing in only one of the bitmaps are passed to onlyThis or onlyOther (if not nil),
+// containers existing in both bitmaps are passed to both. Nil results are dropped.
+func (b *bitmap) combine(other Bitmap, both func(a, b container) container, onlyThis, onlyOther bool) Bitmap {
+	o := asBitmap(other)
+	result := &bitmap{}
+	appendContainer := func(key uint16, c container) {
+		if c != nil {
+			result.keys = append(result.keys, key)
+			result.containers = append(result.containers, c)
+		}
+	}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(o.keys) {
+		switch {
+		case j >= len(o.keys) || (i < len(b.keys) && b.keys[i] < o.keys[j]):
+			if onlyThis {
+				appendContainer(b.keys[i], b.containers[i].clone())
+			}
+			i++
+		case i >= len(b.keys) || b.keys[i] > o.keys[j]:
+			if onlyOther {
+				appendContainer(o.keys[j], o.containers[j].clone())
+			}
+			j++
+		default:
+			appendContainer(b.keys[i], both(b.containers[i], o.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// And returns a new bitmap containing only values that are in both, this bitmap and other (intersection).
+// A nil other is treated as an empty bitmap.
+func (b *bitmap) And(other Bitmap) Bitmap {
+	return b.combine(other, andContainers, false, false)
+}
+
+// Or returns a new bitmap containing all values of both, this bitmap and other (union).
+// A nil other is treated as an empty bitmap.
+func (b *bitmap) Or(other Bitmap) Bitmap {
+	return b.combine(other, orContainers, true, true)
+}
+
+// Xor returns a new bitmap containing all values that are in either this bitmap or other, but not in both (symmetric difference).
+// A nil other is treated as an empty bitmap.
+func (b *bitmap) Xor(other Bitmap) Bitmap {
+	return b.combine(other, xorContainers, true, true)
+}
+
+// AndNot returns a new bitmap containing all values that are in this bitmap but not in other (difference).
+// A nil other is treated as an empty bitmap.
+func (b *bitmap) AndNot(other Bitmap) Bitmap {
+	return b.combine(other, andNotContainers, true, false)
+}
+
+// ToSet returns a new set (without values) containing all values of this bitmap.
+func (b *bitmap) ToSet() set.Set[uint32, set.InternalEmptyType] {
+	return set.Collect(b.Elements())
+}
@@ -0,0 +1,331 @@
+package roaring
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// containerTypes returns the type of each container of the bitmap as a short name.
+func containerTypes(bm Bitmap) []string {
+	types := []string{}
+	for _, c := range bm.(*bitmap).containers {
+		switch c.(type) {
+		case *arrayContainer:
+			types = append(types, "array")
+		case *bitmapContainer:
+			types = append(types, "bitmap")
+		case *runContainer:
+			types = append(types, "run")
+		}
+	}
+	return types
+}
+
+func rangeOf(from, to uint32) []uint32 {
+	values := []uint32{}
+	for v := from; v < to; v++ {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestShouldAddAndRemoveValues(t *testing.T) {
+	// Given
+	bm := New()
+
+	// Expect
+	assert.Equal(t, 0, bm.Size())
+	assert.False(t, bm.Contains(0))
+
+	// When
+	bm.Add(1)
+	bm.Add(70_000)
+	bm.Add(4_000_000_000)
+	bm.Add(1)
+	// Then
+	assert.Equal(t, 3, bm.Size())
+	assert.Equal(t, []uint32{1, 70_000, 4_000_000_000}, bm.List())
+	assert.True(t, bm.Contains(70_000))
+	assert.False(t, bm.Contains(70_001))
+	assert.Equal(t, "1, 70000, 4000000000", bm.String())
+	assert.Equal(t, []string{"array", "array", "array"}, containerTypes(bm))
+
+	// When
+	bm.Remove(70_000)
+	bm.Remove(12)
+	bm.Remove(99_999)
+	// Then
+	assert.Equal(t, []uint32{1, 4_000_000_000}, bm.List())
+	assert.Equal(t, []uint16{0, 61035}, bm.(*bitmap).keys)
+
+	// When
+	bm.Clear()
+	// Then
+	assert.Equal(t, 0, bm.Size())
+	assert.Equal(t, "", bm.String())
+}
+
+func TestShouldSwitchBetweenArrayAndBitmapContainers(t *testing.T) {
+	// Given
+	bm := New()
+	for v := range uint32(arrayMaxSize) {
+		bm.Add(v * 2)
+	}
+
+	// Expect
+	assert.Equal(t, []string{"array"}, containerTypes(bm))
+
+	// When
+	bm.Add(1)
+	// Then
+	assert.Equal(t, []string{"bitmap"}, containerTypes(bm))
+	assert.Equal(t, arrayMaxSize+1, bm.Size())
+	assert.True(t, bm.Contains(1))
+	assert.True(t, bm.Contains(8190))
+
+	// When
+	bm.Remove(8190)
+	// Then
+	assert.Equal(t, []string{"array"}, containerTypes(bm))
+	assert.Equal(t, arrayMaxSize, bm.Size())
+	assert.False(t, bm.Contains(8190))
+}
+
+func TestShouldOptimizeRuns(t *testing.T) {
+	// Given
+	bm := Of(rangeOf(0, 10_000)...)
+	bm.Add(100_000)
+	bm.Add(100_002)
+
+	// Expect
+	assert.Equal(t, []string{"bitmap", "array"}, containerTypes(bm))
+
+	// When
+	bm.RunOptimize()
+	// Then the dense range becomes a run, the sparse values stay an array
+	assert.Equal(t, []string{"run", "array"}, containerTypes(bm))
+	assert.Equal(t, 10_002, bm.Size())
+	assert.True(t, bm.Contains(9_999))
+	assert.False(t, bm.Contains(10_000))
+
+	// When modifying a run container
+	bm.Remove(5_000)
+	// Then it is converted back
+	assert.Equal(t, []string{"bitmap", "array"}, containerTypes(bm))
+	assert.Equal(t, 10_001, bm.Size())
+	assert.False(t, bm.Contains(5_000))
+
+	// When
+	for v := uint32(0); v < 10_000; v += 2 {
+		bm.Remove(v)
+	}
+	bm.RunOptimize()
+	// Then alternating values are not worth a run container
+	assert.Equal(t, []string{"bitmap", "array"}, containerTypes(bm))
+}
+
+func TestShouldBreakOutOfIterationEarly(t *testing.T) {
+	// Given
+	bm := Of(5, 3, 100_000)
+
+	// When
+	values := []uint32{}
+	for v := range bm.Elements() {
+		values = append(values, v)
+		if len(values) == 2 {
+			break
+		}
+	}
+
+	// Then
+	assert.Equal(t, []uint32{3, 5}, values)
+}
+
+func TestShouldCalculateSetAlgebraOfBitmaps(t *testing.T) {
+	// Given
+	bm1 := Of(1, 2, 3, 70_000, 200_000)
+	bm2 := Of(2, 3, 4, 200_000, 300_000)
+
+	// Expect
+	assert.Equal(t, []uint32{2, 3, 200_000}, bm1.And(bm2).List())
+	assert.Equal(t, []uint32{1, 2, 3, 4, 70_000, 200_000, 300_000}, bm1.Or(bm2).List())
+	assert.Equal(t, []uint32{1, 4, 70_000, 300_000}, bm1.Xor(bm2).List())
+	assert.Equal(t, []uint32{1, 70_000}, bm1.AndNot(bm2).List())
+	assert.Equal(t, []uint32{4, 300_000}, bm2.AndNot(bm1).List())
+	assert.Equal(t, []uint32{}, bm1.And(nil).List())
+	assert.Equal(t, bm1.List(), bm1.Or(nil).List())
+	assert.True(t, bm1.Equals(bm1.Copy()))
+	assert.False(t, bm1.Equals(bm2))
+	assert.False(t, bm1.Equals(nil))
+	assert.True(t, New().Equals(nil))
+
+	// and the operands remain unchanged
+	assert.Equal(t, []uint32{1, 2, 3, 70_000, 200_000}, bm1.List())
+	assert.Equal(t, []uint32{2, 3, 4, 200_000, 300_000}, bm2.List())
+
+	// and emptied containers are dropped
+	assert.Equal(t, []uint16{1, 3}, bm1.AndNot(Of(1, 2, 3)).(*bitmap).keys)
+}
+
+func TestBitmapShouldMatchSet(t *testing.T) {
+	// Given sets covering sparse, dense and run containers
+	rnd := rand.New(rand.NewPCG(8, 8))
+	set1 := set.NewWithoutValues[uint32]()
+	set2 := set.NewWithoutValues[uint32]()
+	for range 3000 {
+		set1.AddWithoutValue(rnd.Uint32N(1 << 20))
+		set2.AddWithoutValue(rnd.Uint32N(1 << 20))
+	}
+	for range 20_000 {
+		set1.AddWithoutValue(1<<20 + rnd.Uint32N(30_000))
+		set2.AddWithoutValue(1<<20 + rnd.Uint32N(30_000))
+	}
+	for v := range uint32(20_000) {
+		set1.AddWithoutValue(2<<20 + v)
+		set2.AddWithoutValue(2<<20 + 10_000 + v)
+	}
+
+	// When
+	bm1 := FromSet(set1)
+	bm2 := FromSet(set2)
+	bm1.RunOptimize()
+
+	// Then
+	sorted := func(s set.Set[uint32, set.InternalEmptyType]) []uint32 {
+		return slices.Sorted(s.Elements())
+	}
+	assert.Contains(t, containerTypes(bm1), "run")
+	assert.Contains(t, containerTypes(bm1), "bitmap")
+	assert.Contains(t, containerTypes(bm1), "array")
+	assert.Equal(t, set1.Size(), bm1.Size())
+	assert.Equal(t, sorted(set1), bm1.List())
+	assert.Equal(t, sorted(set1.Intersect(set2)), bm1.And(bm2).List())
+	assert.Equal(t, sorted(set1.Unite(set2)), bm1.Or(bm2).List())
+	assert.Equal(t, sorted(set1.UniteDisjunctively(set2)), bm1.Xor(bm2).List())
+	assert.Equal(t, sorted(set1.Subtract(set2)), bm1.AndNot(bm2).List())
+	assert.Equal(t, sorted(set2.Subtract(set1)), bm2.AndNot(bm1).List())
+	assert.True(t, bm1.ToSet().Equals(set1))
+	assert.True(t, bm1.Equals(FromSet(set1)))
+	assert.Equal(t, 0, FromSet[string](nil).Size())
+}
+
+func TestShouldMarshalAndUnmarshalBitmap(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		bitmap      Bitmap
+		optimize    bool
+		cookie      uint32
+		withOffsets bool
+	}{
+		"empty":                  {bitmap: New(), cookie: serialCookieNoRunContainer, withOffsets: true},
+		"arrays and bitmaps":     {bitmap: Of(append(rangeOf(0, 5000), 1<<31, 4_000_000_000)...), cookie: serialCookieNoRunContainer, withOffsets: true},
+		"runs without offsets":   {bitmap: Of(append(rangeOf(0, 3000), 1<<31)...), optimize: true, cookie: serialCookie | 1<<16},
+		"runs with offsets":      {bitmap: Of(append(rangeOf(0, 3000), 1<<20, 1<<21, 1<<22)...), optimize: true, cookie: serialCookie | 3<<16, withOffsets: true},
+		"full container as runs": {bitmap: Of(rangeOf(1<<16, 2<<16)...), optimize: true, cookie: serialCookie},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			if testCase.optimize {
+				testCase.bitmap.RunOptimize()
+			}
+
+			// When
+			data, err := testCase.bitmap.MarshalBinary()
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.cookie, binary.LittleEndian.Uint32(data))
+
+			// When
+			bm := Of(42)
+			err = bm.UnmarshalBinary(data)
+			// Then
+			assert.Nil(t, err)
+			assert.True(t, testCase.bitmap.Equals(bm))
+			assert.Equal(t, containerTypes(testCase.bitmap), containerTypes(bm))
+
+			// and the offset header points to the containers
+			n := len(bm.(*bitmap).keys)
+			if testCase.withOffsets && n > 0 {
+				headerSize := 8 + 4*n
+				if testCase.cookie != serialCookieNoRunContainer {
+					headerSize = 4 + (n+7)/8 + 4*n
+				}
+				assert.Equal(t, uint32(headerSize+4*n), binary.LittleEndian.Uint32(data[headerSize:]))
+			}
+		})
+	}
+}
+
+func TestShouldUnmarshalReferenceData(t *testing.T) {
+	// Given the bitmap {1, 2, 3, 65536} serialized by the reference implementation
+	data := []byte{
+		0x3a, 0x30, 0, 0, 2, 0, 0, 0, // cookie without runs, 2 containers
+		0, 0, 2, 0, 1, 0, 0, 0, // keys and cardinalities-1
+		24, 0, 0, 0, 30, 0, 0, 0, // offsets
+		1, 0, 2, 0, 3, 0, // container 0
+		0, 0, // container 1
+	}
+
+	// When
+	bm := New()
+	err := bm.UnmarshalBinary(data)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{1, 2, 3, 65536}, bm.List())
+
+	// When
+	marshaled, err := bm.MarshalBinary()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, data, marshaled)
+}
+
+func TestShouldFailToUnmarshalInvalidData(t *testing.T) {
+	// Given
+	valid, _ := Of(1, 2, 3, 70_000).MarshalBinary()
+	withRuns := Of(rangeOf(0, 100)...)
+	withRuns.RunOptimize()
+	validRuns, _ := withRuns.MarshalBinary()
+
+	for name, data := range map[string][]byte{
+		"empty":               {},
+		"unknown cookie":      {1, 2, 3, 4, 0, 0, 0, 0},
+		"truncated":           valid[:len(valid)-1],
+		"unsorted keys":       slices.Concat(valid[:8], []byte{1, 0, 2, 0, 0, 0, 0, 0}, valid[16:]),
+		"unsorted values":     slices.Concat(valid[:24], []byte{2, 0, 1, 0}, valid[28:]),
+		"cardinality":         slices.Concat(valid[:10], []byte{3, 0}, valid[12:]),
+		"run exceeds":         slices.Concat(validRuns[:len(validRuns)-4], []byte{0xff, 0xff, 1, 0}),
+		"too many containers": {0x3a, 0x30, 0, 0, 0, 0, 2, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			bm := Of(42)
+
+			// When
+			err := bm.UnmarshalBinary(data)
+
+			// Then
+			assert.True(t, errors.Is(err, ErrInvalidFormat), "error %v", err)
+			assert.Equal(t, []uint32{42}, bm.List())
+		})
+	}
+}
+
+func BenchmarkBitmapAnd(b *testing.B) {
+	bm1 := New()
+	bm2 := New()
+	for i := range uint32(100_000) {
+		bm1.Add(i * 3)
+		bm2.Add(i * 5)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm1.And(bm2)
+	}
+}
@@ -0,0 +1,201 @@
+package roaring
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// The serialized format is the portable roaring bitmap format shared by the Java, C and Go implementations,
+// see https://github.com/RoaringBitmap/RoaringFormatSpec.
+const (
+	serialCookieNoRunContainer = 12346
+	serialCookie               = 12347
+	noOffsetThreshold          = 4
+)
+
+// ErrInvalidFormat is returned when unmarshaling data that is not a valid serialized roaring bitmap.
+var ErrInvalidFormat = errors.New("invalid roaring bitmap format")
+
+// MarshalBinary serializes the bitmap into the portable roaring bitmap format, so it can be read by
+// other roaring bitmap implementations (e.g. in Java or C) as well.
+func (b *bitmap) MarshalBinary() ([]byte, error) {
+	n := len(b.containers)
+	hasRuns := slices.ContainsFunc(b.containers, isRun)
+
+	var data []byte
+	if hasRuns {
+		data = binary.LittleEndian.AppendUint32(data, serialCookie|uint32(n-1)<<16)
+		runFlags := make([]byte, (n+7)/8)
+		for i, c := range b.containers {
+			if isRun(c) {
+				runFlags[i/8] |= 1 << (i % 8)
+			}
+		}
+		data = append(data, runFlags...)
+	} else {
+		data = binary.LittleEndian.AppendUint32(data, serialCookieNoRunContainer)
+		data = binary.LittleEndian.AppendUint32(data, uint32(n))
+	}
+
+	for i, c := range b.containers {
+		data = binary.LittleEndian.AppendUint16(data, b.keys[i])
+		data = binary.LittleEndian.AppendUint16(data, uint16(c.cardinality()-1))
+	}
+
+	withOffsets := !hasRuns || n >= noOffsetThreshold
+	offsetsStart := len(data)
+	if withOffsets {
+		data = append(data, make([]byte, 4*n)...)
+	}
+
+	for i, c := range b.containers {
+		if withOffsets {
+			binary.LittleEndian.PutUint32(data[offsetsStart+4*i:], uint32(len(data)))
+		}
+		switch {
+		case isRun(c):
+			runs := c.(*runContainer).runs
+			data = binary.LittleEndian.AppendUint16(data, uint16(len(runs)))
+			for _, run := range runs {
+				data = binary.LittleEndian.AppendUint16(data, run.start)
+				data = binary.LittleEndian.AppendUint16(data, run.last-run.start)
+			}
+		case c.cardinality() > arrayMaxSize:
+			for _, word := range toBitmap(c).words {
+				data = binary.LittleEndian.AppendUint64(data, word)
+			}
+		default:
+			c.all(func(v uint16) bool {
+				data = binary.LittleEndian.AppendUint16(data, v)
+				return true
+			})
+		}
+	}
+	return data, nil
+}
+
+// reader reads little endian numbers from a byte slice and remembers the first error.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("%w: unexpected end of data at offset %d", ErrInvalidFormat, r.pos)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// UnmarshalBinary replaces the content of the bitmap by the bitmap serialized in the portable roaring bitmap format.
+// If the data is invalid, an error wrapping ErrInvalidFormat is returned and the bitmap remains unchanged.
+func (b *bitmap) UnmarshalBinary(data []byte) error {
+	r := &reader{data: data}
+	cookie := r.uint32()
+	var n int
+	var runFlags []byte
+	switch {
+	case r.err != nil:
+		return r.err
+	case cookie&0xffff == serialCookie:
+		n = int(cookie>>16) + 1
+		runFlags = r.bytes((n + 7) / 8)
+	case cookie == serialCookieNoRunContainer:
+		n = int(r.uint32())
+	default:
+		return fmt.Errorf("%w: unknown cookie %d", ErrInvalidFormat, cookie)
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if n > 1<<16 {
+		return fmt.Errorf("%w: too many containers (%d)", ErrInvalidFormat, n)
+	}
+
+	keys := make([]uint16, n)
+	cardinalities := make([]int, n)
+	for i := range n {
+		keys[i] = r.uint16()
+		cardinalities[i] = int(r.uint16()) + 1
+		if i > 0 && r.err == nil && keys[i] <= keys[i-1] {
+			return fmt.Errorf("%w: keys are not sorted", ErrInvalidFormat)
+		}
+	}
+	if runFlags == nil || n >= noOffsetThreshold {
+		// the offsets are redundant since the containers are stored consecutively
+		r.bytes(4 * n)
+	}
+
+	containers := make([]container, n)
+	for i := range n {
+		switch {
+		case runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0:
+			runs := make([]interval, int(r.uint16()))
+			for j := range runs {
+				start := r.uint16()
+				length := r.uint16()
+				if int(start)+int(length) > 0xffff || (j > 0 && start <= runs[j-1].last) {
+					return fmt.Errorf("%w: invalid run in container %d", ErrInvalidFormat, i)
+				}
+				runs[j] = interval{start: start, last: start + length}
+			}
+			containers[i] = &runContainer{runs: runs}
+		case cardinalities[i] > arrayMaxSize:
+			bc := newBitmapContainer()
+			for j := range bc.words {
+				bc.words[j] = r.uint64()
+			}
+			bc.countCardinality()
+			containers[i] = bc
+		default:
+			values := make([]uint16, cardinalities[i])
+			for j := range values {
+				values[j] = r.uint16()
+				if j > 0 && r.err == nil && values[j] <= values[j-1] {
+					return fmt.Errorf("%w: values of container %d are not sorted", ErrInvalidFormat, i)
+				}
+			}
+			containers[i] = &arrayContainer{values: values}
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if containers[i].cardinality() != cardinalities[i] {
+			return fmt.Errorf("%w: cardinality of container %d does not match header", ErrInvalidFormat, i)
+		}
+	}
+
+	b.keys = keys
+	b.containers = containers
+	return nil
+}
@@ -0,0 +1,303 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+)
+
+// The binary format is: magic "TZSET" | version (1 byte) | header length (uvarint) | header | number of elements (uvarint) |
+// elements, each as length (uvarint) | encoded element [| length (uvarint) | encoded value] | CRC-32C of all previous bytes (uint32),
+// all little endian. The header currently consists of one flags byte, bit 0 is set if the elements are followed by values.
+// Decoders skip unknown header bytes and ignore unknown flags, so the header can be extended without a new version.
+// The format does not depend on the set implementation: data encoded from any set can be decoded into any other set.
+const (
+	binaryMagic         = "TZSET"
+	binaryFormatVersion = 1
+	binaryFlagValues    = 1 << 0
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrInvalidFormat is returned (wrapped) when decoding data that is not a valid binary encoded set.
+var ErrInvalidFormat = errors.New("invalid binary set format")
+
+// EncodeBinary encodes the set in the binary format, using the given codecs for the elements and values.
+// If a codec is nil, DefaultCodec is used. Values are only encoded if V is not InternalEmptyType.
+// The elements are sorted if T is an ordered type (linked and sorted sets keep their own order), so the output is deterministic.
+func EncodeBinary[T comparable, V any](set Set[T, V], elementCodec Codec[T], valueCodec Codec[V]) ([]byte, error) {
+	if elementCodec == nil {
+		elementCodec = DefaultCodec[T]()
+	}
+	if valueCodec == nil {
+		valueCodec = DefaultCodec[V]()
+	}
+	withValues := scalarcodec.HasValues[V]()
+	flags := byte(0)
+	if withValues {
+		flags |= binaryFlagValues
+	}
+
+	data := append([]byte(binaryMagic), binaryFormatVersion, 1, flags)
+	entries := orderedEntries(set)
+	data = binary.AppendUvarint(data, uint64(len(entries)))
+	var buf []byte
+	var err error
+	for _, e := range entries {
+		if buf, err = elementCodec.Encode(buf[:0], e.element); err != nil {
+			return nil, fmt.Errorf("cannot encode set to binary, element %v: %w", e.element, err)
+		}
+		data = binary.AppendUvarint(data, uint64(len(buf)))
+		data = append(data, buf...)
+		if !withValues {
+			continue
+		}
+		if buf, err = valueCodec.Encode(buf[:0], e.value); err != nil {
+			return nil, fmt.Errorf("cannot encode set to binary, value of element %v: %w", e.element, err)
+		}
+		data = binary.AppendUvarint(data, uint64(len(buf)))
+		data = append(data, buf...)
+	}
+	return binary.LittleEndian.AppendUint32(data, crc32.Checksum(data, crcTable)), nil
+}
+
+// DecodeBinary decodes a binary encoded set using the given codecs and adds its elements to the given set.
+// If a codec is nil, DefaultCodec is used. If the data contains values but V is InternalEmptyType, the values are skipped,
+// if it contains no values but V is another type, the elements get the zero value.
+// If duplicates is StrictDuplicates, decoding fails when an element occurs more than once, including elements already contained in the set.
+// If an error occurs, the set remains unchanged. Errors about invalid data wrap ErrInvalidFormat.
+func DecodeBinary[T comparable, V any](data []byte, set Set[T, V], elementCodec Codec[T], valueCodec Codec[V], duplicates DuplicateHandling) error {
+	decoded, err := decodeBinary(data, set, elementCodec, valueCodec, duplicates)
+	if err != nil {
+		return err
+	}
+	for elem, value := range decoded.All() {
+		set.AddWithValue(elem, value)
+	}
+	return nil
+}
+
+// decodeBinary decodes a binary encoded set into a new linked set keeping the order of the input.
+// Elements contained in existing count as duplicates with StrictDuplicates, existing may be nil.
+func decodeBinary[T comparable, V any](data []byte, existing Set[T, V], elementCodec Codec[T], valueCodec Codec[V], duplicates DuplicateHandling) (*linkedSet[T, V], error) {
+	if elementCodec == nil {
+		elementCodec = DefaultCodec[T]()
+	}
+	if valueCodec == nil {
+		valueCodec = DefaultCodec[V]()
+	}
+	if len(data) < len(binaryMagic)+1+4 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidFormat)
+	}
+	body, checksum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.Checksum(body, crcTable) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidFormat)
+	}
+	body = body[len(binaryMagic):]
+	if body[0] != binaryFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidFormat, body[0])
+	}
+	body = body[1:]
+
+	header, err := readChunk(&body)
+	if err != nil || len(header) == 0 {
+		return nil, fmt.Errorf("%w: invalid header", ErrInvalidFormat)
+	}
+	dataHasValues := header[0]&binaryFlagValues != 0
+	withValues := scalarcodec.HasValues[V]()
+
+	count, n := binary.Uvarint(body)
+	// every element takes at least one byte for its length
+	if n <= 0 || count > uint64(len(body)-n) {
+		return nil, fmt.Errorf("%w: invalid number of elements", ErrInvalidFormat)
+	}
+	body = body[n:]
+	decoded := newLinkedSet[T, V]()
+	for i := range count {
+		chunk, err := readChunk(&body)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := elementCodec.Decode(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot decode element at index %d: %w", ErrInvalidFormat, i, err)
+		}
+		var value V
+		if dataHasValues {
+			if chunk, err = readChunk(&body); err != nil {
+				return nil, err
+			}
+			if withValues {
+				if value, err = valueCodec.Decode(chunk); err != nil {
+					return nil, fmt.Errorf("%w: cannot decode value of element %v at index %d: %w", ErrInvalidFormat, elem, i, err)
+				}
+			}
+		}
+		if duplicates == StrictDuplicates && (decoded.Contains(elem) || (existing != nil && existing.Contains(elem))) {
+			return nil, fmt.Errorf("cannot decode set from binary, duplicate element %v at index %d", elem, i)
+		}
+		decoded.AddWithValue(elem, value)
+	}
+	if len(body) > 0 {
+		return nil, fmt.Errorf("%w: %d unexpected bytes after elements", ErrInvalidFormat, len(body))
+	}
+	return decoded, nil
+}
+
+// readChunk reads a length-prefixed chunk from the start of data and advances data behind it.
+func readChunk(data *[]byte) ([]byte, error) {
+	length, n := binary.Uvarint(*data)
+	if n <= 0 || length > uint64(len(*data)-n) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidFormat)
+	}
+	chunk := (*data)[n : n+int(length)]
+	*data = (*data)[n+int(length):]
+	return chunk, nil
+}
+
+// unmarshalBinaryInto replaces the content of the set by the binary encoded set, decoded (leniently) with the default codecs.
+// If the data is invalid, the set remains unchanged.
+func unmarshalBinaryInto[T comparable, V any](set Set[T, V], data []byte) error {
+	decoded, err := decodeBinary[T, V](data, nil, nil, nil, LenientDuplicates)
+	if err != nil {
+		return err
+	}
+	replaceContent(set, decoded)
+	return nil
+}
+
+// GobSet wraps a set to encode it with encoding/gob as a field of type Set, which gob cannot encode itself
+// since the GobEncoder and GobDecoder methods of sets are not part of the Set interface.
+type GobSet[T comparable, V any] struct {
+	Set Set[T, V]
+}
+
+// GobEncode implements gob.GobEncoder in the format of MarshalBinary. A missing set is encoded as an empty set.
+func (g GobSet[T, V]) GobEncode() ([]byte, error) {
+	if g.Set == nil {
+		return EncodeBinary(NewLinkedWithValues[T, V](), nil, nil)
+	}
+	return EncodeBinary(g.Set, nil, nil)
+}
+
+// GobDecode implements gob.GobDecoder, replacing the content of the wrapped set by the decoded set like UnmarshalBinary.
+// If no set is wrapped, a new insertion-ordered set is created.
+func (g *GobSet[T, V]) GobDecode(data []byte) error {
+	decoded, err := decodeBinary[T, V](data, nil, nil, nil, LenientDuplicates)
+	if err != nil {
+		return err
+	}
+	if g.Set == nil {
+		g.Set = decoded
+		return nil
+	}
+	replaceContent(g.Set, decoded)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the set in a compact binary format with a version header
+// and a checksum, using DefaultCodec for the elements and values. Use EncodeBinary for custom codecs.
+// The elements are sorted if T is an ordered type, so the output is deterministic.
+func (s *tzSet[T, V]) MarshalBinary() ([]byte, error) {
+	return EncodeBinary[T, V](s, nil, nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the set by the decoded set.
+// Duplicate elements are accepted (the last value wins), use DecodeBinary to reject them.
+// If the data is invalid, an error is returned and the set remains unchanged.
+func (s *tzSet[T, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto[T, V](s, data)
+}
+
+// GobEncode implements gob.GobEncoder in the format of MarshalBinary.
+func (s *tzSet[T, V]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder like UnmarshalBinary.
+func (s *tzSet[T, V]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding a snapshot of the set taken under the read lock.
+func (s *syncSet[T, V]) MarshalBinary() ([]byte, error) {
+	return EncodeBinary[T, V](s, nil, nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the set atomically.
+func (s *syncSet[T, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto[T, V](s, data)
+}
+
+// GobEncode implements gob.GobEncoder in the format of MarshalBinary.
+func (s *syncSet[T, V]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder like UnmarshalBinary.
+func (s *syncSet[T, V]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding a snapshot of all shards taken at the same time.
+func (s *shardedSet[T, V]) MarshalBinary() ([]byte, error) {
+	return EncodeBinary[T, V](s, nil, nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of all shards atomically.
+func (s *shardedSet[T, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto[T, V](s, data)
+}
+
+// GobEncode implements gob.GobEncoder in the format of MarshalBinary.
+func (s *shardedSet[T, V]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder like UnmarshalBinary.
+func (s *shardedSet[T, V]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the elements in insertion order.
+func (s *linkedSet[T, V]) MarshalBinary() ([]byte, error) {
+	return EncodeBinary[T, V](s, nil, nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the set by the decoded elements in the order of the input.
+func (s *linkedSet[T, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto[T, V](s, data)
+}
+
+// GobEncode implements gob.GobEncoder in the format of MarshalBinary.
+func (s *linkedSet[T, V]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder like UnmarshalBinary.
+func (s *linkedSet[T, V]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the elements in ascending order.
+func (s *sortedSet[T, V]) MarshalBinary() ([]byte, error) {
+	return EncodeBinary[T, V](s, nil, nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the content of the set by the decoded elements.
+func (s *sortedSet[T, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinaryInto[T, V](s, data)
+}
+
+// GobEncode implements gob.GobEncoder in the format of MarshalBinary.
+func (s *sortedSet[T, V]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder like UnmarshalBinary.
+func (s *sortedSet[T, V]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
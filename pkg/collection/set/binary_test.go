@@ -0,0 +1,325 @@
+package set
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// marshalBinary encodes the set using its encoding.BinaryMarshaler implementation.
+func marshalBinary[T comparable, V any](set Set[T, V]) ([]byte, error) {
+	return set.(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+// unmarshalBinary decodes the data into the set using its encoding.BinaryUnmarshaler implementation.
+func unmarshalBinary[T comparable, V any](set Set[T, V], data []byte) error {
+	return set.(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+}
+
+func TestShouldEncodeSetInCompactBinaryFormat(t *testing.T) {
+	// Given
+	set := NewWithoutValues[string]()
+	set.AddWithoutValue("b")
+	set.AddWithoutValue("a")
+
+	// When
+	data, err := marshalBinary(set)
+
+	// Then the elements are sorted and length-prefixed
+	assert.Nil(t, err)
+	body := []byte{'T', 'Z', 'S', 'E', 'T', 1, 1, 0, 2, 1, 'a', 1, 'b'}
+	assert.Equal(t, binary.LittleEndian.AppendUint32(body, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli))), data)
+}
+
+func TestShouldMarshalAndUnmarshalAllKindsOfSetsBinary(t *testing.T) {
+	// Given
+	sets := map[string]func() Set[int, string]{
+		"set":       NewWithValues[int, string],
+		"sync set":  func() Set[int, string] { return NewSyncWithValues[int, string]() },
+		"sharded":   func() Set[int, string] { return NewShardedWithValues[int, string](4, nil) },
+		"linked":    func() Set[int, string] { return NewLinkedWithValues[int, string]() },
+		"sorted":    func() Set[int, string] { return NewSortedWithValues[int, string]() },
+		"zero set":  func() Set[int, string] { return &tzSet[int, string]{} },
+		"sync zero": func() Set[int, string] { return newSyncSet[int, string](nil) },
+	}
+	for name, newSet := range sets {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			set := newSet()
+			if name != "zero set" && name != "sync zero" {
+				set.AddWithValue(3, "three")
+				set.AddWithValue(-1, "minus one")
+				set.AddWithValue(2, "two")
+			}
+
+			// When
+			data, err := marshalBinary(set)
+			// Then
+			assert.Nil(t, err)
+
+			// When
+			decoded := newSet()
+			if set.Size() > 0 {
+				decoded.AddWithValue(42, "stale")
+			}
+			err = unmarshalBinary(decoded, data)
+			// Then
+			assert.Nil(t, err)
+			assert.True(t, set.Equals(decoded))
+			if name == "linked" || name == "sorted" {
+				assert.Equal(t, set.List(), decoded.List())
+			}
+
+			// and the data can be decoded into any other kind of set
+			for _, newOther := range sets {
+				other := newOther()
+				assert.Nil(t, unmarshalBinary(other, data))
+				assert.True(t, set.Equals(other))
+			}
+		})
+	}
+}
+
+func TestShouldEncodeWithGob(t *testing.T) {
+	// Given
+	type point struct{ X, Y int }
+	set := NewLinkedWithValues[point, []string]()
+	set.AddWithValue(point{X: 1, Y: 2}, []string{"a", "b"})
+	set.AddWithValue(point{X: 0, Y: 0}, nil)
+	var buf bytes.Buffer
+
+	// When
+	err := gob.NewEncoder(&buf).Encode(set)
+	// Then
+	assert.Nil(t, err)
+
+	// When
+	decoded := NewLinkedWithValues[point, []string]()
+	err = gob.NewDecoder(&buf).Decode(decoded)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, set.List(), decoded.List())
+	assert.Equal(t, []string{"a", "b"}, decoded.GetElements()[point{X: 1, Y: 2}])
+}
+
+func TestShouldEncodeSetFieldsWithGob(t *testing.T) {
+	// Given
+	type cache struct {
+		Name   string
+		Labels GobSet[string, InternalEmptyType]
+		Counts GobSet[string, int]
+	}
+	original := cache{Name: "labels", Labels: GobSet[string, InternalEmptyType]{Set: NewWithoutValues[string]()},
+		Counts: GobSet[string, int]{Set: NewWithValues[string, int]()}}
+	original.Labels.Set.AddWithoutValue("new")
+	original.Labels.Set.AddWithoutValue("sale")
+	original.Counts.Set.AddWithValue("new", 3)
+	var buf bytes.Buffer
+
+	// When
+	err1 := gob.NewEncoder(&buf).Encode(original)
+	decoded := cache{Labels: GobSet[string, InternalEmptyType]{Set: NewSortedWithoutValues[string]()}}
+	err2 := gob.NewDecoder(&buf).Decode(&decoded)
+
+	// Then the initialized field keeps its kind of set, a set is created for the other one
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, "labels", decoded.Name)
+	assert.Equal(t, []string{"new", "sale"}, decoded.Labels.Set.List())
+	assert.True(t, original.Counts.Set.Equals(decoded.Counts.Set))
+}
+
+func TestShouldEncodeWithCustomCodecs(t *testing.T) {
+	// Given
+	set := NewSortedWithValues[int, float64]()
+	set.AddWithValue(10, 1.5)
+	set.AddWithValue(2, 0.25)
+	decimal := NewCodec(
+		func(data []byte, e int) ([]byte, error) { return strconv.AppendInt(data, int64(e), 10), nil },
+		func(data []byte) (int, error) { return strconv.Atoi(string(data)) })
+	text := NewCodec(
+		func(data []byte, f float64) ([]byte, error) { return strconv.AppendFloat(data, f, 'g', -1, 64), nil },
+		func(data []byte) (float64, error) { return strconv.ParseFloat(string(data), 64) })
+
+	// When
+	data, err := EncodeBinary(set, decimal, text)
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(data, []byte{1, '2', 4, '0', '.', '2', '5', 2, '1', '0', 3, '1', '.', '5'}))
+
+	// When
+	decoded := NewWithValues[int, float64]()
+	err = DecodeBinary(data, decoded, decimal, text, StrictDuplicates)
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, set.Equals(decoded))
+
+	// When decoding with the wrong codecs
+	err = DecodeBinary(data, NewWithValues[int, float64](), nil, nil, LenientDuplicates)
+	// Then
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	assert.EqualError(t, err, "invalid binary set format: cannot decode value of element 25 at index 0: cannot decode float64 from 4 bytes")
+}
+
+func TestShouldFailToEncodeWithFailingCodec(t *testing.T) {
+	// Given
+	set := NewWithoutValues[int]()
+	set.AddWithoutValue(1)
+	failing := NewCodec(
+		func([]byte, int) ([]byte, error) { return nil, fmt.Errorf("not supported") },
+		func([]byte) (int, error) { return 0, nil })
+
+	// When
+	data, err := EncodeBinary(set, failing, nil)
+
+	// Then
+	assert.Nil(t, data)
+	assert.EqualError(t, err, "cannot encode set to binary, element 1: not supported")
+}
+
+func TestShouldDecodeSetsWithAndWithoutValues(t *testing.T) {
+	// Given
+	withValues := NewWithValues[string, int]()
+	withValues.AddWithValue("a", 1)
+	withoutValues := NewWithoutValues[string]()
+	withoutValues.AddWithoutValue("b")
+	data1, _ := marshalBinary(withValues)
+	data2, _ := marshalBinary(withoutValues)
+
+	// When
+	labels := NewWithoutValues[string]()
+	err1 := unmarshalBinary(labels, data1)
+	counts := NewWithValues[string, int]()
+	err2 := unmarshalBinary(counts, data2)
+
+	// Then values are skipped or zero
+	assert.Nil(t, err1)
+	assert.Equal(t, []string{"a"}, labels.List())
+	assert.Nil(t, err2)
+	assert.Equal(t, "b (0)", counts.StringWithValues())
+}
+
+func TestShouldRejectDuplicatesInBinaryStrictly(t *testing.T) {
+	// Given
+	set := NewWithoutValues[int]()
+	set.AddWithoutValue(1)
+	set.AddWithoutValue(2)
+	data, _ := marshalBinary(set)
+	target := NewWithoutValues[int]()
+	target.AddWithoutValue(2)
+
+	// When
+	err := DecodeBinary(data, target, nil, nil, StrictDuplicates)
+	// Then the set remains unchanged
+	assert.EqualError(t, err, "cannot decode set from binary, duplicate element 2 at index 1")
+	assert.Equal(t, []int{2}, target.List())
+
+	// When
+	err = DecodeBinary(data, target, nil, nil, LenientDuplicates)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 2, target.Size())
+}
+
+func TestShouldSkipUnknownHeaderFields(t *testing.T) {
+	// Given a header extended by a future encoder with unknown flags and fields
+	body := []byte{'T', 'Z', 'S', 'E', 'T', 1, 3, 0x80, 7, 7, 1, 1, 'a'}
+	data := binary.LittleEndian.AppendUint32(body, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli)))
+
+	// When
+	set := NewWithoutValues[string]()
+	err := unmarshalBinary(set, data)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, set.List())
+}
+
+func TestShouldFailToUnmarshalInvalidBinaryData(t *testing.T) {
+	// Given
+	withChecksum := func(body ...byte) []byte {
+		body = append([]byte("TZSET"), body...)
+		return binary.LittleEndian.AppendUint32(body, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli)))
+	}
+	valid := withChecksum(1, 1, 0, 1, 1, 'a')
+	corrupted := bytes.Clone(valid)
+	corrupted[10] = 'b'
+
+	for name, testCase := range map[string]struct {
+		data []byte
+		err  string
+	}{
+		"empty":           {data: nil, err: "invalid binary set format: missing header"},
+		"magic":           {data: append([]byte("TZSEX"), valid[5:]...), err: "invalid binary set format: missing header"},
+		"checksum":        {data: corrupted, err: "invalid binary set format: checksum mismatch"},
+		"version":         {data: withChecksum(2, 1, 0, 0), err: "invalid binary set format: unsupported version 2"},
+		"header":          {data: withChecksum(1, 0, 0), err: "invalid binary set format: invalid header"},
+		"missing count":   {data: withChecksum(1, 1, 0), err: "invalid binary set format: invalid number of elements"},
+		"too many":        {data: withChecksum(1, 1, 0, 5, 1, 'a'), err: "invalid binary set format: invalid number of elements"},
+		"truncated":       {data: withChecksum(1, 1, 0, 1, 3, 'a'), err: "invalid binary set format: unexpected end of data"},
+		"missing value":   {data: withChecksum(1, 1, 1, 1, 1, 'a'), err: "invalid binary set format: unexpected end of data"},
+		"trailing":        {data: withChecksum(1, 1, 0, 1, 1, 'a', 0), err: "invalid binary set format: 1 unexpected bytes after elements"},
+		"invalid element": {data: withChecksum(1, 1, 0, 1, 0), err: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			set := NewWithoutValues[string]()
+			set.AddWithoutValue("existing")
+			numbers := NewWithoutValues[int8]()
+
+			// When
+			var err error
+			if name == "invalid element" {
+				err = unmarshalBinary(numbers, testCase.data)
+				testCase.err = "invalid binary set format: cannot decode element at index 0: cannot decode int8 from 0 bytes"
+			} else {
+				err = unmarshalBinary(set, testCase.data)
+			}
+
+			// Then the set remains unchanged
+			assert.True(t, errors.Is(err, ErrInvalidFormat))
+			assert.EqualError(t, err, testCase.err)
+			assert.Equal(t, []string{"existing"}, set.List())
+		})
+	}
+}
+
+func TestShouldEncodeBasicTypesWithDefaultCodec(t *testing.T) {
+	type label string
+	// Expect
+	assertRoundTrip(t, label("sale"), 4)
+	assertRoundTrip(t, int8(-64), 1)
+	assertRoundTrip(t, int64(-1)<<40, 6)
+	assertRoundTrip(t, uint16(300), 2)
+	assertRoundTrip(t, 1.5, 8)
+	assertRoundTrip(t, float32(-0.5), 8)
+	assertRoundTrip(t, complex(1, -1), 16)
+	assertRoundTrip(t, true, 1)
+	assertRoundTrip(t, InternalEmptyType{}, 0)
+	assertRoundTrip(t, [2]string{"a", "b"}, -1)
+
+	// and overflows are detected
+	data, _ := DefaultCodec[int]().Encode(nil, 1000)
+	_, err := DefaultCodec[int8]().Decode(data)
+	assert.EqualError(t, err, "cannot decode int8 from 2 bytes")
+}
+
+func assertRoundTrip[E comparable](t *testing.T, e E, size int) {
+	codec := DefaultCodec[E]()
+	data, err := codec.Encode(nil, e)
+	assert.Nil(t, err)
+	if size >= 0 {
+		assert.Len(t, data, size)
+	}
+	decoded, err := codec.Decode(data)
+	assert.Nil(t, err)
+	assert.Equal(t, e, decoded)
+}
@@ -0,0 +1,326 @@
+package set
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// BitSet is a set of small non-negative integers stored as a bit vector packed into 64-bit words.
+// It requires one bit per integer in the range [0, largest element], so it is much more compact and faster
+// than a Set[int, InternalEmptyType] for elements from a dense range (like indices, enum ordinals or shard numbers).
+// The set-algebra methods use the same vocabulary as Set, the ...InPlace variants modify the bit set instead of creating a new one.
+type BitSet interface {
+	Add(int)
+	Remove(int)
+	Clear()
+
+	Size() int
+	List() []int
+	Elements() iter.Seq[int]
+	NextSetBit(int) (int, bool)
+	Contains(int) bool
+	ContainsAny(...int) bool
+	Equals(BitSet) bool
+	IsSubset(BitSet) bool
+	String() string
+
+	Copy() BitSet
+	Intersect(BitSet) BitSet
+	Unite(BitSet) BitSet
+	UniteDisjunctively(BitSet) BitSet
+	Subtract(BitSet) BitSet
+
+	IntersectInPlace(BitSet)
+	UniteInPlace(BitSet)
+	UniteDisjunctivelyInPlace(BitSet)
+	SubtractInPlace(BitSet)
+
+	ToSet() Set[int, InternalEmptyType]
+}
+
+const wordSize = 64
+
+type bitSet struct {
+	words []uint64
+}
+
+// NewBitSet creates a new, empty bit set with room for the elements 0 to capacity-1 without reallocation.
+// The bit set grows automatically if larger elements are added.
+func NewBitSet(capacity int) BitSet {
+	return &bitSet{words: make([]uint64, 0, (max(capacity, 0)+wordSize-1)/wordSize)}
+}
+
+// BitSetFromSet creates a new bit set containing all elements of the given set (ignoring the values).
+// If the set contains negative elements, an error is returned.
+// If set is nil, an empty bit set is returned.
+func BitSetFromSet[V any](set Set[int, V]) (BitSet, error) {
+	bs := &bitSet{}
+	if set == nil {
+		return bs, nil
+	}
+	for elem := range set.Elements() {
+		if elem < 0 {
+			return nil, fmt.Errorf("cannot add %d to bit set, elements must not be negative", elem)
+		}
+		bs.Add(elem)
+	}
+	return bs, nil
+}
+
+// asBitSet returns the internal representation of otherSet, or an empty bit set if otherSet is nil.
+func asBitSet(otherSet BitSet) *bitSet {
+	if otherSet == nil {
+		return &bitSet{}
+	}
+	return otherSet.(*bitSet)
+}
+
+// trim removes trailing zero words.
+func (b *bitSet) trim() {
+	n := len(b.words)
+	for n > 0 && b.words[n-1] == 0 {
+		n--
+	}
+	b.words = b.words[:n]
+}
+
+// Add adds a non-negative integer to the bit set.
+// Panics if the element is negative.
+func (b *bitSet) Add(element int) {
+	if element < 0 {
+		panic(fmt.Sprintf("cannot add %d to bit set, elements must not be negative", element))
+	}
+	index := element / wordSize
+	if index >= len(b.words) {
+		if index < cap(b.words) {
+			// words beyond the length may contain stale bits
+			n := len(b.words)
+			b.words = b.words[:index+1]
+			clear(b.words[n:])
+		} else {
+			b.words = append(b.words, make([]uint64, index+1-len(b.words))...)
+		}
+	}
+	b.words[index] |= 1 << (uint(element) % wordSize)
+}
+
+// Remove removes an element from the bit set.
+func (b *bitSet) Remove(element int) {
+	if !b.Contains(element) {
+		return
+	}
+	b.words[element/wordSize] &^= 1 << (uint(element) % wordSize)
+	b.trim()
+}
+
+// Clear removes all elements from the bit set.
+func (b *bitSet) Clear() {
+	clear(b.words)
+	b.words = b.words[:0]
+}
+
+// Size returns the number of elements in the bit set, calculated by counting the set bits (population count).
+func (b *bitSet) Size() int {
+	size := 0
+	for _, word := range b.words {
+		size += bits.OnesCount64(word)
+	}
+	return size
+}
+
+// List returns all elements of the bit set as a slice in ascending order.
+// The returned slice is a copy, changes to that copy do not interfere with the original bit set.
+func (b *bitSet) List() []int {
+	elements := make([]int, 0, b.Size())
+	for elem := range b.Elements() {
+		elements = append(elements, elem)
+	}
+	return elements
+}
+
+// Elements returns an iterator over all elements of the bit set in ascending order.
+func (b *bitSet) Elements() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for elem, ok := b.NextSetBit(0); ok; elem, ok = b.NextSetBit(elem + 1) {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// NextSetBit returns the smallest element of the bit set being greater than or equal to from, and true.
+// If there is no such element, 0 and false are returned.
+func (b *bitSet) NextSetBit(from int) (int, bool) {
+	from = max(from, 0)
+	index := from / wordSize
+	if index >= len(b.words) {
+		return 0, false
+	}
+	word := b.words[index] >> (uint(from) % wordSize)
+	if word != 0 {
+		return from + bits.TrailingZeros64(word), true
+	}
+	for index++; index < len(b.words); index++ {
+		if b.words[index] != 0 {
+			return index*wordSize + bits.TrailingZeros64(b.words[index]), true
+		}
+	}
+	return 0, false
+}
+
+// Contains checks whether or not the given element exists in the bit set.
+func (b *bitSet) Contains(element int) bool {
+	if element < 0 || element/wordSize >= len(b.words) {
+		return false
+	}
+	return b.words[element/wordSize]&(1<<(uint(element)%wordSize)) != 0
+}
+
+// ContainsAny checks if the bit set contains at least one of the given elements.
+func (b *bitSet) ContainsAny(elements ...int) bool {
+	for _, elem := range elements {
+		if b.Contains(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equals checks if this bit set contains exactly the same elements as otherSet.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) Equals(otherSet BitSet) bool {
+	other := asBitSet(otherSet)
+	if len(b.words) != len(other.words) {
+		return false
+	}
+	for i, word := range b.words {
+		if word != other.words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset checks if this bit set is a subset of otherSet.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) IsSubset(otherSet BitSet) bool {
+	other := asBitSet(otherSet)
+	if len(b.words) > len(other.words) {
+		return false
+	}
+	for i, word := range b.words {
+		if word&^other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the bit set.
+// The elements are separated by commas and given in ascending order.
+// If the bit set is empty, an empty string is returned.
+func (b *bitSet) String() string {
+	strElems := make([]string, 0, b.Size())
+	for elem := range b.Elements() {
+		strElems = append(strElems, strconv.Itoa(elem))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// Copy returns a new bit set containing all elements of this bit set.
+func (b *bitSet) Copy() BitSet {
+	return &bitSet{words: append([]uint64(nil), b.words...)}
+}
+
+// Intersect returns a new bit set containing only elements that are in both, this bit set and otherSet.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) Intersect(otherSet BitSet) BitSet {
+	newSet := b.Copy()
+	newSet.IntersectInPlace(otherSet)
+	return newSet
+}
+
+// Unite returns a new bit set containing all elements of both, this bit set and otherSet.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) Unite(otherSet BitSet) BitSet {
+	newSet := b.Copy()
+	newSet.UniteInPlace(otherSet)
+	return newSet
+}
+
+// UniteDisjunctively returns a new bit set containing all elements that are in either this bit set or otherSet, but not in both (symmetric difference).
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) UniteDisjunctively(otherSet BitSet) BitSet {
+	newSet := b.Copy()
+	newSet.UniteDisjunctivelyInPlace(otherSet)
+	return newSet
+}
+
+// Subtract returns a new bit set containing all elements that are in this bit set but not in otherSet.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) Subtract(otherSet BitSet) BitSet {
+	newSet := b.Copy()
+	newSet.SubtractInPlace(otherSet)
+	return newSet
+}
+
+// IntersectInPlace removes all elements from this bit set that are not in otherSet.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) IntersectInPlace(otherSet BitSet) {
+	other := asBitSet(otherSet)
+	b.words = b.words[:min(len(b.words), len(other.words))]
+	for i := range b.words {
+		b.words[i] &= other.words[i]
+	}
+	b.trim()
+}
+
+// UniteInPlace adds all elements of otherSet to this bit set.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) UniteInPlace(otherSet BitSet) {
+	other := asBitSet(otherSet)
+	for i, word := range other.words {
+		if i < len(b.words) {
+			b.words[i] |= word
+		} else {
+			b.words = append(b.words, word)
+		}
+	}
+}
+
+// UniteDisjunctivelyInPlace keeps only the elements that are in either this bit set or otherSet, but not in both.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) UniteDisjunctivelyInPlace(otherSet BitSet) {
+	other := asBitSet(otherSet)
+	for i, word := range other.words {
+		if i < len(b.words) {
+			b.words[i] ^= word
+		} else {
+			b.words = append(b.words, word)
+		}
+	}
+	b.trim()
+}
+
+// SubtractInPlace removes all elements of otherSet from this bit set.
+// A nil otherSet is treated as an empty bit set.
+func (b *bitSet) SubtractInPlace(otherSet BitSet) {
+	other := asBitSet(otherSet)
+	for i := range min(len(b.words), len(other.words)) {
+		b.words[i] &^= other.words[i]
+	}
+	b.trim()
+}
+
+// ToSet returns a new set (without values) containing all elements of this bit set.
+func (b *bitSet) ToSet() Set[int, InternalEmptyType] {
+	newSet := &tzSet[int, InternalEmptyType]{elements: make(map[int]InternalEmptyType, b.Size())}
+	for elem := range b.Elements() {
+		newSet.elements[elem] = internalEmptyValue
+	}
+	return newSet
+}
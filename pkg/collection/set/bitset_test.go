@@ -0,0 +1,210 @@
+package set
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBitSetOf(elements ...int) BitSet {
+	bs := NewBitSet(0)
+	for _, elem := range elements {
+		bs.Add(elem)
+	}
+	return bs
+}
+
+func TestShouldAddAndRemoveElementsOfBitSet(t *testing.T) {
+	// Given
+	bs := NewBitSet(128)
+
+	// Expect
+	assert.Equal(t, 0, bs.Size())
+	assert.False(t, bs.Contains(0))
+	assert.False(t, bs.Contains(-1))
+
+	// When
+	bs.Add(0)
+	bs.Add(63)
+	bs.Add(64)
+	bs.Add(1000)
+	bs.Add(64)
+	// Then
+	assert.Equal(t, 4, bs.Size())
+	assert.Equal(t, []int{0, 63, 64, 1000}, bs.List())
+	assert.True(t, bs.Contains(63))
+	assert.True(t, bs.ContainsAny(5, 1000))
+	assert.False(t, bs.ContainsAny(5, 999, 1001))
+	assert.Equal(t, "0, 63, 64, 1000", bs.String())
+
+	// When
+	bs.Remove(1000)
+	bs.Remove(5000)
+	bs.Remove(-1)
+	// Then
+	assert.Equal(t, []int{0, 63, 64}, bs.List())
+
+	// When
+	bs.Clear()
+	// Then
+	assert.Equal(t, 0, bs.Size())
+	assert.Equal(t, "", bs.String())
+
+	// Expect
+	assert.Panics(t, func() { bs.Add(-1) })
+}
+
+func TestShouldFindNextSetBit(t *testing.T) {
+	// Given
+	bs := newBitSetOf(3, 64, 200)
+
+	// Expect
+	for _, testCase := range []struct {
+		from, next int
+		found      bool
+	}{
+		{from: -5, next: 3, found: true},
+		{from: 0, next: 3, found: true},
+		{from: 3, next: 3, found: true},
+		{from: 4, next: 64, found: true},
+		{from: 65, next: 200, found: true},
+		{from: 201, found: false},
+		{from: 10_000, found: false},
+	} {
+		next, found := bs.NextSetBit(testCase.from)
+		assert.Equal(t, testCase.found, found, "from %d", testCase.from)
+		assert.Equal(t, testCase.next, next, "from %d", testCase.from)
+	}
+
+	// When breaking out of the loop early
+	elements := []int{}
+	for elem := range bs.Elements() {
+		elements = append(elements, elem)
+		break
+	}
+	// Then
+	assert.Equal(t, []int{3}, elements)
+}
+
+func TestShouldCalculateSetAlgebraOfBitSets(t *testing.T) {
+	// Given
+	bs1 := newBitSetOf(1, 2, 3, 100, 200)
+	bs2 := newBitSetOf(2, 3, 4, 200, 300)
+
+	// Expect
+	assert.Equal(t, []int{2, 3, 200}, bs1.Intersect(bs2).List())
+	assert.Equal(t, []int{1, 2, 3, 4, 100, 200, 300}, bs1.Unite(bs2).List())
+	assert.Equal(t, []int{1, 4, 100, 300}, bs1.UniteDisjunctively(bs2).List())
+	assert.Equal(t, []int{1, 100}, bs1.Subtract(bs2).List())
+	assert.Equal(t, []int{4, 300}, bs2.Subtract(bs1).List())
+	assert.Equal(t, []int{}, bs1.Intersect(nil).List())
+	assert.Equal(t, bs1.List(), bs1.Unite(nil).List())
+	assert.True(t, bs1.Intersect(bs2).IsSubset(bs1))
+	assert.False(t, bs1.IsSubset(bs2))
+	assert.False(t, bs1.IsSubset(nil))
+	assert.True(t, NewBitSet(10).IsSubset(nil))
+	assert.True(t, bs1.Equals(bs1.Copy()))
+	assert.False(t, bs1.Equals(bs2))
+
+	// and the operands remain unchanged
+	assert.Equal(t, []int{1, 2, 3, 100, 200}, bs1.List())
+	assert.Equal(t, []int{2, 3, 4, 200, 300}, bs2.List())
+
+	// and bit sets are equal regardless of their capacity
+	assert.True(t, newBitSetOf(1, 2).Equals(newBitSetOf(1, 2, 3000).Subtract(newBitSetOf(3000))))
+}
+
+func TestShouldCalculateSetAlgebraOfBitSetsInPlace(t *testing.T) {
+	// Given
+	bs := newBitSetOf(1, 2, 3, 100, 200)
+
+	// When
+	bs.IntersectInPlace(newBitSetOf(1, 2, 3, 4))
+	// Then
+	assert.Equal(t, []int{1, 2, 3}, bs.List())
+
+	// When adding an element after words have been dropped by intersecting
+	bs.Add(150)
+	// Then no stale bits reappear
+	assert.Equal(t, []int{1, 2, 3, 150}, bs.List())
+
+	// When
+	bs.UniteInPlace(newBitSetOf(3, 500))
+	// Then
+	assert.Equal(t, []int{1, 2, 3, 150, 500}, bs.List())
+
+	// When
+	bs.UniteDisjunctivelyInPlace(newBitSetOf(1, 500, 600))
+	// Then
+	assert.Equal(t, []int{2, 3, 150, 600}, bs.List())
+
+	// When
+	bs.SubtractInPlace(newBitSetOf(2, 600))
+	// Then
+	assert.Equal(t, []int{3, 150}, bs.List())
+	assert.True(t, bs.Equals(newBitSetOf(150, 3)))
+}
+
+func TestBitSetShouldMatchSet(t *testing.T) {
+	// Given
+	rnd := rand.New(rand.NewPCG(7, 7))
+	set1 := NewWithoutValues[int]()
+	set2 := NewWithoutValues[int]()
+	for range 500 {
+		set1.AddWithoutValue(rnd.IntN(2000))
+		set2.AddWithoutValue(rnd.IntN(2000))
+	}
+
+	// When
+	bs1, err1 := BitSetFromSet(set1)
+	bs2, err2 := BitSetFromSet(set2)
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	sorted := func(set Set[int, InternalEmptyType]) []int {
+		return slices.Sorted(set.Elements())
+	}
+	assert.Equal(t, set1.Size(), bs1.Size())
+	assert.Equal(t, sorted(set1), bs1.List())
+	assert.Equal(t, sorted(set1.Intersect(set2)), bs1.Intersect(bs2).List())
+	assert.Equal(t, sorted(set1.Unite(set2)), bs1.Unite(bs2).List())
+	assert.Equal(t, sorted(set1.UniteDisjunctively(set2)), bs1.UniteDisjunctively(bs2).List())
+	assert.Equal(t, sorted(set1.Subtract(set2)), bs1.Subtract(bs2).List())
+	assert.True(t, bs1.ToSet().Equals(set1))
+}
+
+func TestShouldConvertSetWithNegativeElementsToBitSet(t *testing.T) {
+	// Given
+	set := NewWithValues[int, string]()
+	set.AddWithValue(1, "one")
+	set.AddWithValue(-1, "minus one")
+
+	// When
+	bs, err := BitSetFromSet(set)
+
+	// Then
+	assert.Nil(t, bs)
+	assert.EqualError(t, err, "cannot add -1 to bit set, elements must not be negative")
+
+	// When
+	bs, err = BitSetFromSet[string](nil)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 0, bs.Size())
+}
+
+func BenchmarkBitSetIntersect(b *testing.B) {
+	bs1 := NewBitSet(20_000)
+	bs2 := NewBitSet(20_000)
+	for i := range 10_000 {
+		bs1.Add(i)
+		bs2.Add(i * 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bs1.Intersect(bs2)
+	}
+}
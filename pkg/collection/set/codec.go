@@ -0,0 +1,209 @@
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Codec encodes and decodes single elements or values of type E to and from bytes, e.g. for the binary encoding of sets.
+type Codec[E any] interface {
+	// Encode appends the encoding of e to data and returns the extended slice.
+	Encode(data []byte, e E) ([]byte, error)
+	// Decode decodes e from data, which is exactly what Encode appended.
+	Decode(data []byte) (E, error)
+}
+
+type funcCodec[E any] struct {
+	encode func([]byte, E) ([]byte, error)
+	decode func([]byte) (E, error)
+}
+
+// NewCodec creates a new codec from an encode and a decode function.
+func NewCodec[E any](encode func(data []byte, e E) ([]byte, error), decode func(data []byte) (E, error)) Codec[E] {
+	return &funcCodec[E]{encode: encode, decode: decode}
+}
+
+// Encode appends the encoding of e to data and returns the extended slice.
+func (c *funcCodec[E]) Encode(data []byte, e E) ([]byte, error) {
+	return c.encode(data, e)
+}
+
+// Decode decodes e from data.
+func (c *funcCodec[E]) Decode(data []byte) (E, error) {
+	return c.decode(data)
+}
+
+// DefaultCodec returns the codec used if no codec is given: strings are encoded as their bytes, signed integers as zig-zag varints,
+// unsigned integers as varints, floats and complex numbers as IEEE 754 bits (little endian), booleans as one byte
+// and empty structs (like InternalEmptyType) as nothing, including types based on them.
+// All other types are encoded with encoding/gob.
+func DefaultCodec[E any]() Codec[E] {
+	typ := reflect.TypeFor[E]()
+	switch typ.Kind() {
+	case reflect.String:
+		return NewCodec(
+			func(data []byte, e E) ([]byte, error) {
+				return append(data, reflect.ValueOf(e).String()...), nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				reflect.ValueOf(&e).Elem().SetString(string(data))
+				return e, nil
+			})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewCodec(
+			func(data []byte, e E) ([]byte, error) {
+				return binary.AppendVarint(data, reflect.ValueOf(e).Int()), nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				x, n := binary.Varint(data)
+				v := reflect.ValueOf(&e).Elem()
+				if n <= 0 || n != len(data) || v.OverflowInt(x) {
+					return e, fmt.Errorf("cannot decode %v from %d bytes", typ, len(data))
+				}
+				v.SetInt(x)
+				return e, nil
+			})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewCodec(
+			func(data []byte, e E) ([]byte, error) {
+				return binary.AppendUvarint(data, reflect.ValueOf(e).Uint()), nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				x, n := binary.Uvarint(data)
+				v := reflect.ValueOf(&e).Elem()
+				if n <= 0 || n != len(data) || v.OverflowUint(x) {
+					return e, fmt.Errorf("cannot decode %v from %d bytes", typ, len(data))
+				}
+				v.SetUint(x)
+				return e, nil
+			})
+	case reflect.Float32, reflect.Float64:
+		return NewCodec(
+			func(data []byte, e E) ([]byte, error) {
+				return binary.LittleEndian.AppendUint64(data, math.Float64bits(reflect.ValueOf(e).Float())), nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				if len(data) != 8 {
+					return e, fmt.Errorf("cannot decode %v from %d bytes", typ, len(data))
+				}
+				reflect.ValueOf(&e).Elem().SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+				return e, nil
+			})
+	case reflect.Complex64, reflect.Complex128:
+		return NewCodec(
+			func(data []byte, e E) ([]byte, error) {
+				c := reflect.ValueOf(e).Complex()
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(real(c)))
+				return binary.LittleEndian.AppendUint64(data, math.Float64bits(imag(c))), nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				if len(data) != 16 {
+					return e, fmt.Errorf("cannot decode %v from %d bytes", typ, len(data))
+				}
+				re := math.Float64frombits(binary.LittleEndian.Uint64(data))
+				im := math.Float64frombits(binary.LittleEndian.Uint64(data[8:]))
+				reflect.ValueOf(&e).Elem().SetComplex(complex(re, im))
+				return e, nil
+			})
+	case reflect.Bool:
+		return NewCodec(
+			func(data []byte, e E) ([]byte, error) {
+				if reflect.ValueOf(e).Bool() {
+					return append(data, 1), nil
+				}
+				return append(data, 0), nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				if len(data) != 1 || data[0] > 1 {
+					return e, fmt.Errorf("cannot decode %v from %d bytes", typ, len(data))
+				}
+				reflect.ValueOf(&e).Elem().SetBool(data[0] == 1)
+				return e, nil
+			})
+	}
+	if typ.Kind() == reflect.Struct && typ.NumField() == 0 {
+		return NewCodec(
+			func(data []byte, _ E) ([]byte, error) {
+				return data, nil
+			},
+			func(data []byte) (E, error) {
+				var e E
+				if len(data) != 0 {
+					return e, fmt.Errorf("cannot decode %v from %d bytes", typ, len(data))
+				}
+				return e, nil
+			})
+	}
+	return NewCodec(
+		func(data []byte, e E) ([]byte, error) {
+			buf := bytes.NewBuffer(data)
+			if err := gob.NewEncoder(buf).Encode(&e); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		func(data []byte) (E, error) {
+			var e E
+			err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e)
+			return e, err
+		})
+}
+
+// replaceContent replaces the content of the set by the content of the decoded set, atomically for the concurrency-safe sets.
+// Sets implemented outside this package are cleared and filled instead.
+func replaceContent[T comparable, V any](set Set[T, V], decoded *linkedSet[T, V]) {
+	if r, ok := set.(interface{ replace(*linkedSet[T, V]) }); ok {
+		r.replace(decoded)
+		return
+	}
+	set.Clear()
+	set.AddAll(decoded)
+}
+
+// replace replaces the content of the set by the content of the decoded set.
+func (s *tzSet[T, V]) replace(decoded *linkedSet[T, V]) {
+	s.elements = decoded.GetElements()
+}
+
+// replace atomically replaces the content of the set by the content of the decoded set.
+func (s *syncSet[T, V]) replace(decoded *linkedSet[T, V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elements = decoded.GetElements()
+}
+
+// replace atomically replaces the content of the set by the content of the decoded set.
+func (s *shardedSet[T, V]) replace(decoded *linkedSet[T, V]) {
+	unlock := s.lockAll(true)
+	defer unlock()
+	for _, sh := range s.shards {
+		clear(sh.elements)
+	}
+	for elem, value := range decoded.All() {
+		s.put(elem, value)
+	}
+}
+
+// replace replaces the content of the set by the content of the decoded set, keeping the order of the decoded set.
+func (s *linkedSet[T, V]) replace(decoded *linkedSet[T, V]) {
+	s.Clear()
+	for elem, value := range decoded.All() {
+		s.AddWithValue(elem, value)
+	}
+}
+
+// replace replaces the content of the set by the content of the decoded set.
+func (s *sortedSet[T, V]) replace(decoded *linkedSet[T, V]) {
+	s.Clear()
+	s.AddAll(decoded)
+}
@@ -0,0 +1,192 @@
+package set
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+)
+
+// CSVOptions defines how the rows of a CSV file are mapped to the elements and values of a set.
+// Without header, the element is in the first column and the value in the second one (sets without values have only one column).
+type CSVOptions[T comparable, V any] struct {
+	// Header contains the names of the element column and the value column (only the element column for sets without values).
+	// If set, WriteCSV writes a header row, and ReadCSV expects one and finds the columns by their names, ignoring other columns.
+	Header []string
+	// Comma is the field delimiter, ',' if not set.
+	Comma rune
+	// ParseElement and ParseValue parse a field, strings, booleans and numbers are parsed with strconv if not set
+	// (types implementing encoding.TextUnmarshaler with UnmarshalText).
+	ParseElement func(string) (T, error)
+	ParseValue   func(string) (V, error)
+	// FormatElement and FormatValue format a field, fmt.Sprint is used if not set
+	// (types implementing encoding.TextMarshaler with MarshalText).
+	FormatElement func(T) (string, error)
+	FormatValue   func(V) (string, error)
+	// Duplicates defines whether rows with the same element are accepted (the last value wins) or rejected by ReadCSV.
+	Duplicates DuplicateHandling
+}
+
+func (o CSVOptions[T, V]) withDefaults() CSVOptions[T, V] {
+	if o.Comma == 0 {
+		o.Comma = ','
+	}
+	if o.ParseElement == nil {
+		o.ParseElement = parseText[T]
+	}
+	if o.ParseValue == nil {
+		o.ParseValue = parseText[V]
+	}
+	if o.FormatElement == nil {
+		o.FormatElement = formatText[T]
+	}
+	if o.FormatValue == nil {
+		o.FormatValue = formatText[V]
+	}
+	return o
+}
+
+// columns returns the number of columns of a set with values of type V.
+func columns[V any]() int {
+	if scalarcodec.HasValues[V]() {
+		return 2
+	}
+	return 1
+}
+
+// ReadCSV reads the rows of a CSV file and adds their elements and values to the given set.
+// Errors in the input are reported as ParseError with the line and column, also for elements occurring in more than one row
+// if options.Duplicates is StrictDuplicates (including elements already contained in the set).
+// If an error occurs, the set remains unchanged.
+func ReadCSV[T comparable, V any](r io.Reader, set Set[T, V], options CSVOptions[T, V]) error {
+	decoded, err := readCSV(r, set, options.withDefaults())
+	if err != nil {
+		return fmt.Errorf("cannot read set from CSV: %w", err)
+	}
+	set.AddAll(decoded)
+	return nil
+}
+
+func readCSV[T comparable, V any](r io.Reader, set Set[T, V], options CSVOptions[T, V]) (*linkedSet[T, V], error) {
+	reader := csv.NewReader(r)
+	reader.Comma = options.Comma
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	indexes := []int{0, 1}[:columns[V]()]
+	if options.Header != nil {
+		if len(options.Header) != len(indexes) {
+			return nil, fmt.Errorf("expected %d column names in header but got %d", len(indexes), len(options.Header))
+		}
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header row")
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		for i, name := range options.Header {
+			if indexes[i] = slices.Index(header, name); indexes[i] < 0 {
+				line, _ := reader.FieldPos(0)
+				return nil, &ParseError{Line: line, Column: 1, Err: fmt.Errorf("column %q not found in header", name)}
+			}
+		}
+	}
+
+	decoded := newLinkedSet[T, V]()
+	lines := make(map[T]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return decoded, nil
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		fields := make([]string, len(indexes))
+		for i, index := range indexes {
+			if index >= len(record) {
+				return nil, fieldError(reader, len(record)-1, fmt.Errorf("expected at least %d fields but found %d", index+1, len(record)))
+			}
+			fields[i] = record[index]
+		}
+
+		elem, err := options.ParseElement(fields[0])
+		if err != nil {
+			return nil, fieldError(reader, indexes[0], err)
+		}
+		var value V
+		if len(fields) > 1 {
+			if value, err = options.ParseValue(fields[1]); err != nil {
+				return nil, fieldError(reader, indexes[1], err)
+			}
+		}
+		if options.Duplicates == StrictDuplicates {
+			if first, ok := lines[elem]; ok {
+				return nil, fieldError(reader, indexes[0], fmt.Errorf("duplicate element %v, first in line %d", elem, first))
+			}
+			if set.Contains(elem) {
+				return nil, fieldError(reader, indexes[0], fmt.Errorf("duplicate element %v, already contained in the set", elem))
+			}
+			lines[elem], _ = reader.FieldPos(indexes[0])
+		}
+		decoded.AddWithValue(elem, value)
+	}
+}
+
+// fieldError returns a ParseError for the field with the given index of the record read last.
+func fieldError(reader *csv.Reader, index int, err error) error {
+	line, column := reader.FieldPos(index)
+	return &ParseError{Line: line, Column: column, Err: err}
+}
+
+// csvError converts an error of the CSV reader to a ParseError.
+func csvError(err error) error {
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return &ParseError{Line: csvErr.Line, Column: csvErr.Column, Err: csvErr.Err}
+	}
+	return err
+}
+
+// WriteCSV writes the elements and values of the set as rows of a CSV file, preceded by a header row if options.Header is set.
+// The elements are sorted if T is an ordered type (linked and sorted sets keep their own order), so the output is deterministic.
+func WriteCSV[T comparable, V any](w io.Writer, set Set[T, V], options CSVOptions[T, V]) error {
+	if err := writeCSV(w, set, options.withDefaults()); err != nil {
+		return fmt.Errorf("cannot write set to CSV: %w", err)
+	}
+	return nil
+}
+
+func writeCSV[T comparable, V any](w io.Writer, set Set[T, V], options CSVOptions[T, V]) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = options.Comma
+	record := make([]string, columns[V]())
+	if options.Header != nil {
+		if len(options.Header) != len(record) {
+			return fmt.Errorf("expected %d column names in header but got %d", len(record), len(options.Header))
+		}
+		if err := writer.Write(options.Header); err != nil {
+			return err
+		}
+	}
+	var err error
+	for _, e := range orderedEntries(set) {
+		if record[0], err = options.FormatElement(e.element); err != nil {
+			return fmt.Errorf("element %v: %w", e.element, err)
+		}
+		if len(record) > 1 {
+			if record[1], err = options.FormatValue(e.value); err != nil {
+				return fmt.Errorf("value of element %v: %w", e.element, err)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,161 @@
+package set
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldWriteAndReadCSV(t *testing.T) {
+	// Given
+	prices := NewWithValues[string, float64]()
+	prices.AddWithValue("banana", 0.25)
+	prices.AddWithValue("apple, red", 1.5)
+	options := CSVOptions[string, float64]{Header: []string{"fruit", "price"}}
+	var buf bytes.Buffer
+
+	// When
+	err := WriteCSV(&buf, prices, options)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "fruit,price\n\"apple, red\",1.5\nbanana,0.25\n", buf.String())
+
+	// When
+	decoded := NewWithValues[string, float64]()
+	err = ReadCSV(&buf, decoded, options)
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, prices.Equals(decoded))
+}
+
+func TestShouldReadColumnsByHeaderName(t *testing.T) {
+	// Given a CSV file with additional columns in another order
+	data := "id;price;name\n1;1.5;apple\n2;0.25;banana\n"
+	prices := NewLinkedWithValues[string, float64]()
+
+	// When
+	err := ReadCSV(strings.NewReader(data), prices, CSVOptions[string, float64]{Header: []string{"name", "price"}, Comma: ';'})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "apple (1.5), banana (0.25)", prices.StringWithValues())
+}
+
+func TestShouldWriteAndReadCSVWithoutValuesAndHeader(t *testing.T) {
+	// Given
+	numbers := NewWithoutValues[int]()
+	numbers.AddWithoutValue(10)
+	numbers.AddWithoutValue(-2)
+	var buf bytes.Buffer
+
+	// When
+	err := WriteCSV(&buf, numbers, CSVOptions[int, InternalEmptyType]{})
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "-2\n10\n", buf.String())
+
+	// When
+	decoded := NewWithoutValues[int]()
+	err = ReadCSV(&buf, decoded, CSVOptions[int, InternalEmptyType]{})
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, numbers.Equals(decoded))
+}
+
+func TestShouldUseParseAndFormatFunctions(t *testing.T) {
+	// Given
+	deadlines := NewSortedWithValues[string, time.Time]()
+	deadlines.AddWithValue("report", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	options := CSVOptions[string, time.Time]{
+		FormatElement: func(s string) (string, error) { return strings.ToUpper(s), nil },
+		FormatValue:   func(t time.Time) (string, error) { return t.Format(time.DateOnly), nil },
+		ParseElement:  func(s string) (string, error) { return strings.ToLower(s), nil },
+		ParseValue:    func(s string) (time.Time, error) { return time.Parse(time.DateOnly, s) },
+	}
+	var buf bytes.Buffer
+
+	// When
+	err1 := WriteCSV(&buf, deadlines, options)
+	data := buf.String()
+	decoded := NewSortedWithValues[string, time.Time]()
+	err2 := ReadCSV(&buf, decoded, options)
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Equal(t, "REPORT,2024-03-01\n", data)
+	assert.Nil(t, err2)
+	assert.True(t, deadlines.Equals(decoded))
+}
+
+func TestShouldReportDuplicateRowsWithRowNumber(t *testing.T) {
+	// Given
+	data := "name,count\napple,1\nbanana,2\napple,3\n"
+	counts := NewWithValues[string, int]()
+	options := CSVOptions[string, int]{Header: []string{"name", "count"}, Duplicates: StrictDuplicates}
+
+	// When
+	err := ReadCSV(strings.NewReader(data), counts, options)
+	// Then the set remains unchanged
+	assert.EqualError(t, err, "cannot read set from CSV: line 4, column 1: duplicate element apple, first in line 2")
+	assert.Equal(t, 0, counts.Size())
+
+	// When
+	counts.AddWithValue("banana", 0)
+	err = ReadCSV(strings.NewReader("name,count\nbanana,2\n"), counts, options)
+	// Then
+	assert.EqualError(t, err, "cannot read set from CSV: line 2, column 1: duplicate element banana, already contained in the set")
+
+	// When reading leniently
+	options.Duplicates = LenientDuplicates
+	err = ReadCSV(strings.NewReader(data), counts, options)
+	// Then the last value wins
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"apple": 3, "banana": 2}, counts.GetElements())
+}
+
+func TestShouldFailToReadInvalidCSV(t *testing.T) {
+	for data, expected := range map[string]string{
+		"a,1\nb,x\n":      "cannot read set from CSV: line 2, column 3: strconv.ParseInt: parsing \"x\": invalid syntax",
+		"a,1\nb\n":        "cannot read set from CSV: line 2, column 1: expected at least 2 fields but found 1",
+		"a,1\n\"b,2\n":    "cannot read set from CSV: line 2, column 6: extraneous or missing \" in quoted-field",
+		"a,1\nb,\"2\"x\n": "cannot read set from CSV: line 2, column 5: extraneous or missing \" in quoted-field",
+	} {
+		// Given
+		set := NewWithValues[string, int]()
+
+		// When
+		err := ReadCSV(strings.NewReader(data), set, CSVOptions[string, int]{})
+
+		// Then
+		assert.EqualError(t, err, expected)
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, 2, parseErr.Line)
+	}
+
+	// Expect
+	err := ReadCSV(strings.NewReader("name,price\n"), NewWithValues[string, int](), CSVOptions[string, int]{Header: []string{"name", "count"}})
+	assert.EqualError(t, err, "cannot read set from CSV: line 1, column 1: column \"count\" not found in header")
+	err = ReadCSV(strings.NewReader(""), NewWithValues[string, int](), CSVOptions[string, int]{Header: []string{"name", "count"}})
+	assert.EqualError(t, err, "cannot read set from CSV: missing header row")
+	err = ReadCSV(strings.NewReader(""), NewWithValues[string, int](), CSVOptions[string, int]{Header: []string{"name"}})
+	assert.EqualError(t, err, "cannot read set from CSV: expected 2 column names in header but got 1")
+}
+
+func TestShouldFailToWriteCSV(t *testing.T) {
+	// Given
+	set := NewWithValues[int, int]()
+	set.AddWithValue(1, 2)
+	options := CSVOptions[int, int]{FormatValue: func(int) (string, error) { return "", strconv.ErrRange }}
+
+	// When
+	err := WriteCSV(&bytes.Buffer{}, set, options)
+
+	// Then
+	assert.EqualError(t, err, "cannot write set to CSV: value of element 1: value out of range")
+}
@@ -0,0 +1,206 @@
+package set
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Flag is a set of elements given as text, e.g. by a command-line flag like -exclude=a,b,c or an environment variable like REGIONS=eu,us.
+// It implements flag.Value (and flag.Getter), encoding.TextMarshaler and encoding.TextUnmarshaler, so it can be used with
+// flag.Var and flag.TextVar. The zero value is an empty set with the default separator.
+//
+// The elements are separated by Separator. Spaces around the elements are ignored. A backslash escapes the following character,
+// so elements can contain the separator (a\,b), a backslash (a\\b) or leading and trailing spaces (\ a).
+type Flag[T comparable] struct {
+	// Elements is the set of elements, a new insertion-ordered set is created if not set.
+	Elements Set[T, InternalEmptyType]
+	// Separator separates the elements, "," if not set. It must not contain a backslash.
+	Separator string
+	// Parse parses an element, strings, booleans and numbers are parsed with strconv if not set
+	// (types implementing encoding.TextUnmarshaler with UnmarshalText).
+	Parse func(string) (T, error)
+	// Format formats an element, fmt.Sprint is used if not set (types implementing encoding.TextMarshaler with MarshalText).
+	Format func(T) (string, error)
+	// changed is set by the first call of Set, which replaces the default elements.
+	changed bool
+}
+
+func (f *Flag[T]) separator() string {
+	if f.Separator == "" {
+		return ","
+	}
+	return f.Separator
+}
+
+// String returns the elements separated by the separator and escaped, see MarshalText. Elements that cannot be formatted are skipped.
+func (f *Flag[T]) String() string {
+	if f == nil || f.Elements == nil {
+		return ""
+	}
+	texts, _ := f.format(true)
+	return strings.Join(texts, f.separator())
+}
+
+// Set adds the elements of the text to the set. Each flag given on the command line calls Set, so repeated flags accumulate
+// (-exclude=a,b -exclude=c gives a, b and c). The first call replaces the elements the set contains before (the default of the flag).
+// If the text is invalid, an error is returned and the set remains unchanged.
+func (f *Flag[T]) Set(text string) error {
+	decoded, err := f.parse(text)
+	if err != nil {
+		return err
+	}
+	switch {
+	case f.Elements == nil:
+		f.Elements = decoded
+	case !f.changed:
+		replaceContent(f.Elements, decoded)
+	default:
+		f.Elements.AddAll(decoded)
+	}
+	f.changed = true
+	return nil
+}
+
+// Get returns the set of elements, implementing flag.Getter.
+func (f *Flag[T]) Get() any {
+	return f.Elements
+}
+
+// MarshalText returns the elements separated by the separator, in the order of the set if it keeps one and sorted otherwise
+// (if T is an ordered type). Backslashes, characters of the separator and leading and trailing spaces are escaped with a backslash.
+func (f *Flag[T]) MarshalText() ([]byte, error) {
+	if f.Elements == nil {
+		return []byte{}, nil
+	}
+	texts, err := f.format(false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode set to text: %w", err)
+	}
+	return []byte(strings.Join(texts, f.separator())), nil
+}
+
+// UnmarshalText replaces the elements of the set by the elements of the text, e.g. read from an environment variable.
+// It does not count as a call of Set, so flags given later still replace the elements.
+// If the text is invalid, an error is returned and the set remains unchanged.
+func (f *Flag[T]) UnmarshalText(text []byte) error {
+	decoded, err := f.parse(string(text))
+	if err != nil {
+		return fmt.Errorf("cannot decode set from text: %w", err)
+	}
+	if f.Elements == nil {
+		f.Elements = decoded
+	} else {
+		replaceContent(f.Elements, decoded)
+	}
+	return nil
+}
+
+func (f *Flag[T]) format(skipInvalid bool) ([]string, error) {
+	format := f.Format
+	if format == nil {
+		format = formatText[T]
+	}
+	separator := f.separator()
+	entries := orderedEntries(f.Elements)
+	texts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		text, err := format(e.element)
+		if err == nil && text == "" {
+			err = errors.New("empty elements cannot be represented")
+		}
+		if err != nil {
+			if skipInvalid {
+				continue
+			}
+			return nil, fmt.Errorf("element %v: %w", e.element, err)
+		}
+		texts = append(texts, escapeFlagElement(text, separator))
+	}
+	return texts, nil
+}
+
+func (f *Flag[T]) parse(text string) (*linkedSet[T, InternalEmptyType], error) {
+	separator := f.separator()
+	if strings.Contains(separator, `\`) {
+		return nil, fmt.Errorf("invalid separator %q, it must not contain a backslash", separator)
+	}
+	parse := f.Parse
+	if parse == nil {
+		parse = parseText[T]
+	}
+	decoded := newLinkedSet[T, InternalEmptyType]()
+	if strings.TrimSpace(text) == "" {
+		return decoded, nil
+	}
+	fields, err := splitFlagElements(text, separator)
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range fields {
+		if field == "" {
+			return nil, fmt.Errorf("element %d is empty", i+1)
+		}
+		elem, err := parse(field)
+		if err != nil {
+			var numErr *strconv.NumError
+			if errors.As(err, &numErr) {
+				err = numErr.Err
+			}
+			return nil, fmt.Errorf("element %d %q is not a valid %v: %w", i+1, field, reflect.TypeFor[T](), err)
+		}
+		decoded.AddWithoutValue(elem)
+	}
+	return decoded, nil
+}
+
+// splitFlagElements splits text at the separator, removes unescaped spaces around the fields and unescapes them.
+func splitFlagElements(text, separator string) ([]string, error) {
+	var fields []string
+	var b strings.Builder
+	// length is the length of the field without trailing unescaped spaces
+	length := 0
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '\\':
+			if i+1 >= len(text) {
+				return nil, fmt.Errorf("unterminated escape at the end of %q", text)
+			}
+			_, size := utf8.DecodeRuneInString(text[i+1:])
+			b.WriteString(text[i+1 : i+1+size])
+			length = b.Len()
+			i += 1 + size
+		case strings.HasPrefix(text[i:], separator):
+			fields = append(fields, b.String()[:length])
+			b.Reset()
+			length = 0
+			i += len(separator)
+		case isSpace(text[i]):
+			if b.Len() > 0 {
+				b.WriteByte(text[i])
+			}
+			i++
+		default:
+			b.WriteByte(text[i])
+			length = b.Len()
+			i++
+		}
+	}
+	return append(fields, b.String()[:length]), nil
+}
+
+// escapeFlagElement escapes backslashes, characters of the separator and leading and trailing spaces of text with a backslash.
+func escapeFlagElement(text, separator string) string {
+	var b strings.Builder
+	for i, r := range text {
+		first, last := i == 0, i+utf8.RuneLen(r) == len(text)
+		if r == '\\' || strings.ContainsRune(separator, r) || ((first || last) && r < utf8.RuneSelf && isSpace(byte(r))) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package set
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldAccumulateRepeatedFlags(t *testing.T) {
+	// Given
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	var exclude Flag[string]
+	flags.Var(&exclude, "exclude", "elements to exclude")
+
+	// When
+	err := flags.Parse([]string{"-exclude=a, b", "-exclude", "c,a"})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, exclude.Elements.List())
+	assert.Equal(t, "a,b,c", exclude.String())
+}
+
+func TestShouldReplaceDefaultsByFlags(t *testing.T) {
+	// Given
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	ports := Flag[int]{Elements: NewSortedWithoutValues[int](), Separator: ";"}
+	ports.Elements.AddWithoutValue(80)
+	flags.Var(&ports, "ports", "ports to listen on")
+	var usage strings.Builder
+	flags.SetOutput(&usage)
+
+	// When
+	flags.PrintDefaults()
+	// Then
+	assert.Contains(t, usage.String(), "(default 80)")
+
+	// When
+	err := flags.Parse([]string{"-ports=8443;443", "-ports=8080"})
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []int{443, 8080, 8443}, ports.Elements.List())
+	assert.Equal(t, ports.Elements, flags.Lookup("ports").Value.(flag.Getter).Get())
+}
+
+func TestShouldUnescapeAndEscapeElements(t *testing.T) {
+	// Given
+	var f Flag[string]
+
+	// When
+	err := f.Set(`a\,b, c\\d ,\ e\ ,f::g`)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a,b", `c\d`, " e ", "f::g"}, f.Elements.List())
+
+	// When
+	text, err := f.MarshalText()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `a\,b,c\\d,\ e\ ,f::g`, string(text))
+
+	// When escaping characters of a longer separator
+	f.Separator = "::"
+	text, err = f.MarshalText()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `a,b::c\\d::\ e\ ::f\:\:g`, string(text))
+	roundTrip := Flag[string]{Separator: "::"}
+	assert.Nil(t, roundTrip.UnmarshalText(text))
+	assert.True(t, f.Elements.Equals(roundTrip.Elements))
+}
+
+func TestShouldUnmarshalFlagFromEnvironmentText(t *testing.T) {
+	// Given
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	regions := Flag[string]{}
+	flags.Var(&regions, "region", "regions")
+
+	// When the environment variable is read before parsing the flags
+	err := regions.UnmarshalText([]byte("eu,us"))
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"eu", "us"}, regions.Elements.List())
+
+	// When
+	err = flags.Parse([]string{"-region=ap"})
+	// Then the flags replace the environment variable
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ap"}, regions.Elements.List())
+}
+
+func TestShouldUseFlagWithTextVar(t *testing.T) {
+	// Given
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	defaults := Flag[time.Duration]{Parse: time.ParseDuration}
+	assert.Nil(t, defaults.Set("1s"))
+	timeouts := Flag[time.Duration]{Parse: time.ParseDuration, Format: func(d time.Duration) (string, error) { return d.String(), nil }}
+	flags.TextVar(&timeouts, "timeouts", &defaults, "timeouts")
+
+	// When
+	err := flags.Parse([]string{"-timeouts=2m,500ms"})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Duration{2 * time.Minute, 500 * time.Millisecond}, timeouts.Elements.List())
+	assert.Equal(t, "2m0s,500ms", timeouts.String())
+}
+
+func TestShouldReportInvalidFlagElements(t *testing.T) {
+	for text, expected := range map[string]string{
+		"1, x":                 `invalid value "1, x" for flag -ports: element 2 "x" is not a valid int: invalid syntax`,
+		"1,,2":                 `invalid value "1,,2" for flag -ports: element 2 is empty`,
+		"1,2,":                 `invalid value "1,2," for flag -ports: element 3 is empty`,
+		"99999999999999999999": `invalid value "99999999999999999999" for flag -ports: element 1 "99999999999999999999" is not a valid int: value out of range`,
+		`1\`:                   `invalid value "1\\" for flag -ports: unterminated escape at the end of "1\\"`,
+	} {
+		// Given
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		ports := Flag[int]{Elements: NewWithoutValues[int]()}
+		ports.Elements.AddWithoutValue(80)
+		flags.Var(&ports, "ports", "ports")
+
+		// When
+		err := flags.Parse([]string{"-ports=" + text})
+
+		// Then the set remains unchanged
+		assert.EqualError(t, err, expected)
+		assert.Equal(t, []int{80}, ports.Elements.List())
+	}
+
+	// Expect
+	assert.EqualError(t, (&Flag[int]{}).UnmarshalText([]byte("a")), `cannot decode set from text: element 1 "a" is not a valid int: invalid syntax`)
+	assert.EqualError(t, (&Flag[int]{Separator: `\`}).Set("1"), `invalid separator "\\", it must not contain a backslash`)
+	empty := Flag[string]{Elements: NewWithoutValues[string]()}
+	empty.Elements.AddWithoutValue("")
+	_, err := empty.MarshalText()
+	assert.EqualError(t, err, "cannot encode set to text: element : empty elements cannot be represented")
+}
@@ -0,0 +1,231 @@
+package set
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"slices"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+)
+
+// DefaultLogLimit is the maximum number of elements included in the log value of a set, see LogValueWithLimit.
+const DefaultLogLimit = 10
+
+// formatSet implements fmt.Formatter for all sets:
+//   - %v prints the elements in math notation {a, b}, %+v includes the values {a: 1, b: 2} (for sets with values).
+//     A precision limits the number of printed elements, %.2v prints {a, b, ... (3 more)}. %s is the same as %v.
+//   - %#v prints Go syntax creating a set with the same content, set.Collect(slices.Values([]string{"a", "b"})) for sets
+//     without values and set.CollectWithValues(maps.All(map[string]int{"a": 1, "b": 2})) for sets with values.
+//   - All other verbs are applied to each element with their flags, width and precision, %x prints {a, ff}.
+//
+// The elements are printed in the order of orderedEntries.
+func formatSet[T comparable, V any](f fmt.State, verb rune, set Set[T, V]) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		writeGoSyntax(f, set)
+	case verb == 'v' || verb == 's':
+		limit, ok := f.Precision()
+		if !ok {
+			limit = -1
+		}
+		writeSet(f, set, "%v", f.Flag('+') && scalarcodec.HasValues[V](), limit)
+	default:
+		writeSet(f, set, fmt.FormatString(f, verb), false, -1)
+	}
+}
+
+// writeSet writes the set in math notation with each element formatted by elemFormat, followed by its value if withValues is true.
+// If limit is not negative, at most limit elements are written, followed by the number of the remaining elements.
+func writeSet[T comparable, V any](w io.Writer, set Set[T, V], elemFormat string, withValues bool, limit int) {
+	size := set.Size()
+	io.WriteString(w, "{")
+	for i, e := range limitedEntries(set, limit) {
+		if i > 0 {
+			io.WriteString(w, ", ")
+		}
+		fmt.Fprintf(w, elemFormat, e.element)
+		if withValues {
+			fmt.Fprintf(w, ": %v", e.value)
+		}
+	}
+	if limit >= 0 && size > limit {
+		if limit > 0 {
+			io.WriteString(w, ", ")
+		}
+		fmt.Fprintf(w, "... (%d more)", size-limit)
+	}
+	io.WriteString(w, "}")
+}
+
+// writeGoSyntax writes a Go expression creating a set with the same content as the given set.
+func writeGoSyntax[T comparable, V any](w io.Writer, set Set[T, V]) {
+	if scalarcodec.HasValues[V]() {
+		fmt.Fprintf(w, "set.CollectWithValues(maps.All(%T{", map[T]V(nil))
+	} else {
+		fmt.Fprintf(w, "set.Collect(slices.Values(%T{", []T(nil))
+	}
+	for i, e := range orderedEntries(set) {
+		if i > 0 {
+			io.WriteString(w, ", ")
+		}
+		fmt.Fprintf(w, "%#v", e.element)
+		if scalarcodec.HasValues[V]() {
+			fmt.Fprintf(w, ": %#v", e.value)
+		}
+	}
+	io.WriteString(w, "}))")
+}
+
+// limitedEntries returns an iterator over the first limit entries of the set and their indices in the order of orderedEntries,
+// or over all entries if limit is negative. Linked and sorted sets are iterated directly, without collecting their entries first.
+func limitedEntries[T comparable, V any](set Set[T, V], limit int) iter.Seq2[int, entry[T, V]] {
+	switch set.(type) {
+	case *linkedSet[T, V], *sortedSet[T, V]:
+		return func(yield func(int, entry[T, V]) bool) {
+			i := 0
+			for elem, value := range set.All() {
+				if i == limit || !yield(i, entry[T, V]{element: elem, value: value}) {
+					return
+				}
+				i++
+			}
+		}
+	}
+	entries := orderedEntries(set)
+	if limit >= 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return slices.All(entries)
+}
+
+// LogValueWithLimit returns the log value of the set with at most limit elements (all elements if limit is negative).
+// The log value is a group with the attributes "size" (the number of elements), "elements" (a list of the elements,
+// or a group mapping the elements to their values for sets with values) and "truncated" (true, only if elements are missing).
+// The elements are sorted if T is an ordered type, linked and sorted sets keep their own order.
+func LogValueWithLimit[T comparable, V any](set Set[T, V], limit int) slog.Value {
+	size := set.Size()
+	count := 0
+	attrs := []slog.Attr{slog.Int("size", size)}
+	if scalarcodec.HasValues[V]() {
+		var values []slog.Attr
+		for _, e := range limitedEntries(set, limit) {
+			values = append(values, slog.Any(fmt.Sprint(e.element), e.value))
+		}
+		count = len(values)
+		attrs = append(attrs, slog.Attr{Key: "elements", Value: slog.GroupValue(values...)})
+	} else {
+		elements := []T{}
+		for _, e := range limitedEntries(set, limit) {
+			elements = append(elements, e.element)
+		}
+		count = len(elements)
+		attrs = append(attrs, slog.Any("elements", elements))
+	}
+	if count < size {
+		attrs = append(attrs, slog.Bool("truncated", true))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// writeTo writes the set like %+v to w, buffered, and returns the number of bytes written.
+func writeTo[T comparable, V any](w io.Writer, set Set[T, V]) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	writeSet(bw, set, "%v", scalarcodec.HasValues[V](), -1)
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Format implements fmt.Formatter: %v prints the set as {a, b}, %+v with values as {a: 1, b: 2}, %.5v at most 5 elements
+// and %#v as Go syntax. The elements are sorted if T is an ordered type.
+func (s *tzSet[T, V]) Format(f fmt.State, verb rune) {
+	formatSet[T, V](f, verb, s)
+}
+
+// LogValue implements slog.LogValuer with the size of the set and at most DefaultLogLimit elements, see LogValueWithLimit.
+func (s *tzSet[T, V]) LogValue() slog.Value {
+	return LogValueWithLimit[T, V](s, DefaultLogLimit)
+}
+
+// WriteTo implements io.WriterTo, writing the set like %+v to w through a buffer. The elements are collected
+// (and sorted if T is an ordered type) before writing.
+func (s *tzSet[T, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo[T, V](w, s)
+}
+
+// Format implements fmt.Formatter, printing a snapshot of the set taken under the read lock.
+func (s *syncSet[T, V]) Format(f fmt.State, verb rune) {
+	formatSet[T, V](f, verb, s)
+}
+
+// LogValue implements slog.LogValuer with a snapshot of the set taken under the read lock.
+func (s *syncSet[T, V]) LogValue() slog.Value {
+	return LogValueWithLimit[T, V](s, DefaultLogLimit)
+}
+
+// WriteTo implements io.WriterTo, writing a snapshot of the set taken under the read lock.
+func (s *syncSet[T, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo[T, V](w, s)
+}
+
+// Format implements fmt.Formatter, printing a snapshot of all shards taken at the same time.
+func (s *shardedSet[T, V]) Format(f fmt.State, verb rune) {
+	formatSet[T, V](f, verb, s)
+}
+
+// LogValue implements slog.LogValuer with a snapshot of all shards taken at the same time.
+func (s *shardedSet[T, V]) LogValue() slog.Value {
+	return LogValueWithLimit[T, V](s, DefaultLogLimit)
+}
+
+// WriteTo implements io.WriterTo, writing a snapshot of all shards taken at the same time.
+func (s *shardedSet[T, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo[T, V](w, s)
+}
+
+// Format implements fmt.Formatter, printing the elements in insertion order.
+func (s *linkedSet[T, V]) Format(f fmt.State, verb rune) {
+	formatSet[T, V](f, verb, s)
+}
+
+// LogValue implements slog.LogValuer with the first elements in insertion order.
+func (s *linkedSet[T, V]) LogValue() slog.Value {
+	return LogValueWithLimit[T, V](s, DefaultLogLimit)
+}
+
+// WriteTo implements io.WriterTo, streaming the elements in insertion order without collecting them first.
+func (s *linkedSet[T, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo[T, V](w, s)
+}
+
+// Format implements fmt.Formatter, printing the elements in ascending order.
+func (s *sortedSet[T, V]) Format(f fmt.State, verb rune) {
+	formatSet[T, V](f, verb, s)
+}
+
+// LogValue implements slog.LogValuer with the smallest elements.
+func (s *sortedSet[T, V]) LogValue() slog.Value {
+	return LogValueWithLimit[T, V](s, DefaultLogLimit)
+}
+
+// WriteTo implements io.WriterTo, streaming the elements in ascending order without collecting them first.
+func (s *sortedSet[T, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo[T, V](w, s)
+}
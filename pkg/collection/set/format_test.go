@@ -0,0 +1,170 @@
+package set
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X, Y int
+}
+
+func TestShouldFormatSetsInMathNotation(t *testing.T) {
+	// Given
+	labels := NewWithoutValues[string]()
+	labels.AddWithoutValue("sale")
+	labels.AddWithoutValue("new")
+	prices := NewLinkedWithValues[string, float64]()
+	prices.AddWithValue("banana", 0.25)
+	prices.AddWithValue("apple", 1.5)
+	numbers := NewSyncWithoutValues[int]()
+	for i := range 100 {
+		numbers.AddWithoutValue(i)
+	}
+
+	// Expect
+	assert.Equal(t, "{new, sale}", fmt.Sprintf("%v", labels))
+	assert.Equal(t, "{new, sale}", fmt.Sprint(labels))
+	assert.Equal(t, "{new, sale}", fmt.Sprintf("%+v", labels))
+	assert.Equal(t, "{banana, apple}", fmt.Sprintf("%s", prices))
+	assert.Equal(t, "{banana: 0.25, apple: 1.5}", fmt.Sprintf("%+v", prices))
+	assert.Equal(t, "{banana: 0.25, ... (1 more)}", fmt.Sprintf("%+.1v", prices))
+	assert.Equal(t, "{0, 1, 2, 3, 4, ... (95 more)}", fmt.Sprintf("%.5v", numbers))
+	assert.Equal(t, "{... (100 more)}", fmt.Sprintf("%.0v", numbers))
+	assert.Equal(t, "{}", fmt.Sprintf("%v", NewSortedWithoutValues[int]()))
+	assert.Equal(t, `{"new", "sale"}`, fmt.Sprintf("%q", labels))
+}
+
+func TestShouldFormatSetsAsGoSyntax(t *testing.T) {
+	// Given
+	labels := NewShardedWithoutValues[string](4, nil)
+	labels.AddWithoutValue("b")
+	labels.AddWithoutValue("a")
+	points := NewWithValues[point, bool]()
+	points.AddWithValue(point{1, 2}, true)
+	numbers := NewWithoutValues[int]()
+	numbers.AddWithoutValue(255)
+	numbers.AddWithoutValue(16)
+
+	// Expect
+	assert.Equal(t, `set.Collect(slices.Values([]string{"a", "b"}))`, fmt.Sprintf("%#v", labels))
+	assert.Equal(t, `set.CollectWithValues(maps.All(map[set.point]bool{set.point{X:1, Y:2}: true}))`, fmt.Sprintf("%#v", points))
+	assert.Equal(t, "{10, ff}", fmt.Sprintf("%x", numbers))
+	assert.Equal(t, "{ 16, 255}", fmt.Sprintf("%3d", numbers))
+}
+
+func TestShouldLogSetsTruncatedAndSorted(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+	numbers := NewWithoutValues[int]()
+	for i := range 100_000 {
+		numbers.AddWithoutValue(100_000 - i)
+	}
+	limits := NewLinkedWithValues[string, int]()
+	limits.AddWithValue("memory", 8)
+	limits.AddWithValue("cpu", 4)
+
+	// When
+	logger.Info("sets", "numbers", numbers, "limits", limits)
+
+	// Then
+	assert.Equal(t, `{"msg":"sets",`+
+		`"numbers":{"size":100000,"elements":[1,2,3,4,5,6,7,8,9,10],"truncated":true},`+
+		`"limits":{"size":2,"elements":{"memory":8,"cpu":4}}}`+"\n", buf.String())
+}
+
+func TestShouldLogSetsWithLimit(t *testing.T) {
+	// Given
+	labels := NewSortedWithoutValues[string]()
+	labels.AddWithoutValue("c")
+	labels.AddWithoutValue("a")
+	labels.AddWithoutValue("b")
+
+	// Expect
+	assert.Equal(t, "[size=3 elements=[a b] truncated=true]", LogValueWithLimit[string, InternalEmptyType](labels, 2).String())
+	assert.Equal(t, "[size=3 elements=[a b c]]", LogValueWithLimit[string, InternalEmptyType](labels, -1).String())
+}
+
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		w.limit = 0
+		return 0, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestShouldWriteHugeSetsToWriter(t *testing.T) {
+	// Given
+	numbers := NewWithoutValues[int]()
+	for i := range 100_000 {
+		numbers.AddWithoutValue(i)
+	}
+	var buf strings.Builder
+
+	// When
+	n, err := numbers.(io.WriterTo).WriteTo(&buf)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, fmt.Sprintf("%v", numbers), buf.String())
+	assert.True(t, strings.HasPrefix(buf.String(), "{0, 1, 2, "))
+	assert.True(t, strings.HasSuffix(buf.String(), ", 99999}"))
+
+	// When
+	n, err = numbers.(io.WriterTo).WriteTo(&failingWriter{limit: 10_000})
+	// Then the bytes of the first buffer are written
+	assert.EqualError(t, err, "disk full")
+	assert.Equal(t, int64(8192), n)
+}
+
+func TestShouldWriteSetsWithValuesToWriter(t *testing.T) {
+	// Given
+	prices := NewSortedWithValues[string, float64]()
+	prices.AddWithValue("banana", 0.25)
+	prices.AddWithValue("apple", 1.5)
+	var buf bytes.Buffer
+
+	// When
+	n, err := prices.(io.WriterTo).WriteTo(&buf)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(26), n)
+	assert.Equal(t, "{apple: 1.5, banana: 0.25}", buf.String())
+}
+
+func TestShouldWriteLinkedSetsToWriterInInsertionOrder(t *testing.T) {
+	// Given
+	labels := NewLinkedWithoutValues[string]()
+	labels.AddWithoutValue("sale")
+	labels.AddWithoutValue("new")
+	labels.AddWithoutValue("eco")
+	var buf strings.Builder
+
+	// When
+	n, err := labels.(io.WriterTo).WriteTo(&buf)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, int64(16), n)
+	assert.Equal(t, "{sale, new, eco}", buf.String())
+}
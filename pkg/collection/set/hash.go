@@ -0,0 +1,143 @@
+package set
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+)
+
+// HashFunc calculates a 64-bit hash of an element of type T.
+// Equal elements must result in equal hashes.
+type HashFunc[T comparable] func(T) uint64
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// DefaultHashFunc returns a deterministic hash function for elements of type T.
+// Booleans, numbers and strings (including types based on them) are hashed from their binary representation.
+// Structs and arrays are hashed from their fields and elements, interfaces from their dynamic type and value,
+// so elements which are equal (==) always get equal hashes, e.g. structs with float fields being +0 and -0.
+// The hashes are stable across processes and machines, so they can be used for serialized data structures,
+// except for types containing pointers or channels, which are hashed from their addresses.
+func DefaultHashFunc[T comparable]() HashFunc[T] {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String:
+		return func(element T) uint64 {
+			if str, ok := any(element).(string); ok {
+				return HashString(str)
+			}
+			return HashString(reflect.ValueOf(element).String())
+		}
+	case reflect.Int:
+		return func(element T) uint64 {
+			if i, ok := any(element).(int); ok {
+				return HashUint64(uint64(i))
+			}
+			return hashValue(reflect.ValueOf(element))
+		}
+	case reflect.Uint32:
+		return func(element T) uint64 {
+			if u, ok := any(element).(uint32); ok {
+				return HashUint64(uint64(u))
+			}
+			return hashValue(reflect.ValueOf(element))
+		}
+	default:
+		return func(element T) uint64 {
+			// the pointer keeps the interface kind of interface types, reflect.ValueOf(element) would unwrap the dynamic value
+			return hashValue(reflect.ValueOf(&element).Elem())
+		}
+	}
+}
+
+// hashValue hashes a value of a comparable type, see DefaultHashFunc.
+func hashValue(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.String:
+		return HashString(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return HashUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return HashUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return hashFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return mix64(hashFloat(real(c)) ^ mix64(hashFloat(imag(c))+1))
+	case reflect.Bool:
+		if v.Bool() {
+			return HashUint64(1)
+		}
+		return HashUint64(0)
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return HashUint64(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return HashUint64(0)
+		}
+		// values of different dynamic types are never equal, so the type is part of the hash
+		return combineHashes(HashString(v.Elem().Type().String()), hashValue(v.Elem()))
+	case reflect.Array:
+		h := uint64(fnvOffset64)
+		for i := range v.Len() {
+			h = combineHashes(h, hashValue(v.Index(i)))
+		}
+		return mix64(h)
+	case reflect.Struct:
+		h := uint64(fnvOffset64)
+		for i := range v.NumField() {
+			h = combineHashes(h, hashValue(v.Field(i)))
+		}
+		return mix64(h)
+	default:
+		// not reachable for comparable types
+		panic(fmt.Sprintf("cannot hash value of type %v", v.Type()))
+	}
+}
+
+// combineHashes adds the hash of a part of a value to the hash h of the previous parts, depending on the order of the parts.
+func combineHashes(h, part uint64) uint64 {
+	return (bits.RotateLeft64(h, 5) ^ part) * fnvPrime64
+}
+
+// HashString returns the deterministic 64-bit hash of a string (FNV-1a with an additional avalanche step).
+func HashString(str string) uint64 {
+	var h uint64 = fnvOffset64
+	for i := 0; i < len(str); i++ {
+		h ^= uint64(str[i])
+		h *= fnvPrime64
+	}
+	return mix64(h)
+}
+
+// HashUint64 returns the deterministic 64-bit hash of an unsigned integer (FNV-1a of the little endian bytes with an additional avalanche step).
+func HashUint64(u uint64) uint64 {
+	var h uint64 = fnvOffset64
+	for i := 0; i < 8; i++ {
+		h ^= u & 0xff
+		h *= fnvPrime64
+		u >>= 8
+	}
+	return mix64(h)
+}
+
+// hashFloat hashes the bits of a float, treating +0 and -0 as equal (as the == operator does).
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		f = 0
+	}
+	return HashUint64(math.Float64bits(f))
+}
+
+// mix64 is the finalizer of MurmurHash3 which spreads every input bit over all output bits.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,241 @@
+package set
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DuplicateHandling defines how elements occurring more than once are handled when decoding a set.
+type DuplicateHandling int
+
+const (
+	// LenientDuplicates accepts duplicate elements, the last value of an element wins (like decoding a JSON object into a map).
+	LenientDuplicates DuplicateHandling = iota
+	// StrictDuplicates rejects duplicate elements with an error.
+	StrictDuplicates
+)
+
+// jsonForm is the JSON representation of a set, which depends on the types of its elements and values.
+type jsonForm int
+
+const (
+	// jsonElements is an array of elements, used for sets without values.
+	jsonElements jsonForm = iota
+	// jsonObject is an object mapping elements to values, used for sets with values having string-like elements.
+	jsonObject
+	// jsonPairs is an array of objects with the fields "element" and "value", used for all other sets with values.
+	jsonPairs
+)
+
+// jsonPair is an element of a set together with its value in the jsonPairs form.
+type jsonPair[T comparable, V any] struct {
+	Element T `json:"element"`
+	Value   V `json:"value"`
+}
+
+func jsonFormOf[T comparable, V any]() jsonForm {
+	switch {
+	case reflect.TypeFor[V]() == reflect.TypeFor[InternalEmptyType]():
+		return jsonElements
+	case reflect.TypeFor[T]().Kind() == reflect.String:
+		return jsonObject
+	default:
+		return jsonPairs
+	}
+}
+
+// marshalJSON encodes the set in its JSON form, with the elements in the order of orderedEntries.
+func marshalJSON[T comparable, V any](set Set[T, V]) ([]byte, error) {
+	form := jsonFormOf[T, V]()
+	var buf bytes.Buffer
+	if form == jsonObject {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte('[')
+	}
+	for i, e := range orderedEntries(set) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		var data []byte
+		var err error
+		switch form {
+		case jsonElements:
+			data, err = json.Marshal(e.element)
+		case jsonObject:
+			if data, err = json.Marshal(reflect.ValueOf(e.element).String()); err == nil {
+				buf.Write(data)
+				buf.WriteByte(':')
+				data, err = json.Marshal(e.value)
+			}
+		case jsonPairs:
+			data, err = json.Marshal(jsonPair[T, V]{Element: e.element, Value: e.value})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode set to JSON: %w", err)
+		}
+		buf.Write(data)
+	}
+	if form == jsonObject {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalJSON decodes a set from its JSON form (leniently) into a new linked set keeping the order of the input.
+// If data is the JSON null, nil is returned.
+func unmarshalJSON[T comparable, V any](data []byte) (*linkedSet[T, V], error) {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil, nil
+	}
+	decoded := newLinkedSet[T, V]()
+	if err := DecodeJSON[T, V](data, decoded, LenientDuplicates); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// unmarshalJSONInto replaces the content of the set by the set decoded from JSON (leniently).
+// The set remains unchanged if the data is invalid or the JSON null.
+func unmarshalJSONInto[T comparable, V any](set Set[T, V], data []byte) error {
+	decoded, err := unmarshalJSON[T, V](data)
+	if err != nil || decoded == nil {
+		return err
+	}
+	replaceContent(set, decoded)
+	return nil
+}
+
+// JSONDecoder decodes a set from a stream of JSON, adding element by element to the set without reading the whole input first.
+// So it can be used for very large sets, e.g. read from a file or a network connection.
+type JSONDecoder[T comparable, V any] struct {
+	dec        *json.Decoder
+	duplicates DuplicateHandling
+}
+
+// NewJSONDecoder creates a new decoder reading a set from r.
+// If duplicates is StrictDuplicates, decoding fails when an element occurs more than once.
+func NewJSONDecoder[T comparable, V any](r io.Reader, duplicates DuplicateHandling) *JSONDecoder[T, V] {
+	return &JSONDecoder[T, V]{dec: json.NewDecoder(r), duplicates: duplicates}
+}
+
+// DecodeJSON decodes a set from JSON and adds its elements to the given set.
+// If duplicates is StrictDuplicates, decoding fails when an element occurs more than once.
+// See JSONDecoder.Decode for details.
+func DecodeJSON[T comparable, V any](data []byte, set Set[T, V], duplicates DuplicateHandling) error {
+	return NewJSONDecoder[T, V](bytes.NewReader(data), duplicates).Decode(set)
+}
+
+// Decode reads the next set from the stream and adds its elements to the given set.
+// Sets without values are read from an array of elements, sets with values from an object mapping elements to values
+// if T is string-like, and from an array of objects with the fields "element" and "value" otherwise.
+// A JSON null leaves the set unchanged.
+// With StrictDuplicates, an element that is already contained in the set counts as duplicate.
+// If an error occurs, the elements decoded so far remain in the set.
+func (d *JSONDecoder[T, V]) Decode(set Set[T, V]) error {
+	form := jsonFormOf[T, V]()
+	start, err := d.dec.Token()
+	if err != nil {
+		return fmt.Errorf("cannot decode set from JSON: %w", err)
+	}
+	expected := json.Delim('[')
+	if form == jsonObject {
+		expected = '{'
+	}
+	if start == nil {
+		return nil
+	}
+	if start != expected {
+		return fmt.Errorf("cannot decode set from JSON, expected %v but found %v at offset %d", expected, start, d.dec.InputOffset())
+	}
+
+	for index := 0; d.dec.More(); index++ {
+		var elem T
+		var value V
+		switch form {
+		case jsonElements:
+			err = d.dec.Decode(&elem)
+		case jsonObject:
+			var key json.Token
+			if key, err = d.dec.Token(); err == nil {
+				reflect.ValueOf(&elem).Elem().SetString(key.(string))
+				err = d.dec.Decode(&value)
+			}
+		case jsonPairs:
+			var pair jsonPair[T, V]
+			err = d.dec.Decode(&pair)
+			elem, value = pair.Element, pair.Value
+		}
+		if err != nil {
+			return fmt.Errorf("cannot decode set from JSON: %w", err)
+		}
+		if d.duplicates == StrictDuplicates && set.Contains(elem) {
+			return fmt.Errorf("cannot decode set from JSON, duplicate element %v at index %d", elem, index)
+		}
+		set.AddWithValue(elem, value)
+	}
+
+	if _, err := d.dec.Token(); err != nil {
+		return fmt.Errorf("cannot decode set from JSON: %w", err)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler: sets without values are encoded as an array of elements, sets with values
+// as an object mapping elements to values if T is string-like, and as an array of objects with the fields "element"
+// and "value" otherwise. The elements are sorted if T is an ordered type, so the output is deterministic.
+func (s *tzSet[T, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T, V](s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the content of the set by the decoded set.
+// Duplicate elements are accepted (the last value wins), use DecodeJSON to reject them.
+// If the data is invalid, an error is returned and the set remains unchanged.
+func (s *tzSet[T, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONInto[T, V](s, data)
+}
+
+// MarshalJSON implements json.Marshaler, encoding a snapshot of the set taken under the read lock.
+func (s *syncSet[T, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T, V](s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the content of the set atomically.
+func (s *syncSet[T, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONInto[T, V](s, data)
+}
+
+// MarshalJSON implements json.Marshaler, encoding a snapshot of all shards taken at the same time.
+func (s *shardedSet[T, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T, V](s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the content of all shards atomically.
+func (s *shardedSet[T, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONInto[T, V](s, data)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the elements in insertion order.
+func (s *linkedSet[T, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T, V](s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the content of the set by the decoded elements in the order of the input.
+func (s *linkedSet[T, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONInto[T, V](s, data)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the elements in ascending order.
+func (s *sortedSet[T, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T, V](s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the content of the set by the decoded elements.
+func (s *sortedSet[T, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONInto[T, V](s, data)
+}
@@ -0,0 +1,286 @@
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldMarshalSetWithoutValuesAsArray(t *testing.T) {
+	// Given
+	labels := NewWithoutValues[string]()
+	labels.AddWithoutValue("cherry")
+	labels.AddWithoutValue("apple")
+	labels.AddWithoutValue("banana")
+	numbers := NewWithoutValues[int]()
+	numbers.AddWithoutValue(10)
+	numbers.AddWithoutValue(-2)
+	numbers.AddWithoutValue(3)
+
+	// When
+	labelsJSON, err1 := json.Marshal(labels)
+	numbersJSON, err2 := json.Marshal(numbers)
+	emptyJSON, err3 := json.Marshal(NewWithoutValues[string]())
+
+	// Then the elements are sorted
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Equal(t, `["apple","banana","cherry"]`, string(labelsJSON))
+	assert.Equal(t, `[-2,3,10]`, string(numbersJSON))
+	assert.Equal(t, `[]`, string(emptyJSON))
+}
+
+func TestShouldMarshalSetWithStringLikeElementsAsObject(t *testing.T) {
+	// Given
+	type fruit string
+	set := NewWithValues[fruit, int]()
+	set.AddWithValue("cherry", 3)
+	set.AddWithValue("apple", 1)
+	set.AddWithValue(`"quoted"`, 2)
+
+	// When
+	data, err := json.Marshal(set)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `{"\"quoted\"":2,"apple":1,"cherry":3}`, string(data))
+}
+
+func TestShouldMarshalSetWithOtherElementsAsArrayOfPairs(t *testing.T) {
+	// Given
+	type point struct{ X, Y int }
+	numbers := NewWithValues[int, string]()
+	numbers.AddWithValue(2, "two")
+	numbers.AddWithValue(1, "one")
+	points := NewWithValues[point, bool]()
+	points.AddWithValue(point{X: 2, Y: 1}, true)
+	points.AddWithValue(point{X: 1, Y: 2}, false)
+
+	// When
+	numbersJSON, err1 := json.Marshal(numbers)
+	pointsJSON, err2 := json.Marshal(points)
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, `[{"element":1,"value":"one"},{"element":2,"value":"two"}]`, string(numbersJSON))
+	// and elements of unordered types are sorted by their Go-syntax representation
+	assert.Equal(t, `[{"element":{"X":1,"Y":2},"value":false},{"element":{"X":2,"Y":1},"value":true}]`, string(pointsJSON))
+}
+
+func TestShouldMarshalAndUnmarshalSetsInStructs(t *testing.T) {
+	// Given
+	type request struct {
+		Labels Set[string, InternalEmptyType] `json:"labels"`
+		Prices Set[string, float64]           `json:"prices"`
+	}
+	original := request{Labels: NewWithoutValues[string](), Prices: NewWithValues[string, float64]()}
+	original.Labels.AddWithoutValue("new")
+	original.Labels.AddWithoutValue("sale")
+	original.Prices.AddWithValue("apple", 1.5)
+
+	// When
+	data, err := json.Marshal(original)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `{"labels":["new","sale"],"prices":{"apple":1.5}}`, string(data))
+
+	// When
+	decoded := request{Labels: NewWithoutValues[string](), Prices: NewWithValues[string, float64]()}
+	decoded.Labels.AddWithoutValue("old")
+	err = json.Unmarshal(data, &decoded)
+	// Then the content is replaced
+	assert.Nil(t, err)
+	assert.True(t, original.Labels.Equals(decoded.Labels))
+	assert.True(t, original.Prices.Equals(decoded.Prices))
+}
+
+func TestShouldMarshalAndUnmarshalAllKindsOfSets(t *testing.T) {
+	// Given
+	sets := map[string]func() Set[int, string]{
+		"set":       NewWithValues[int, string],
+		"sync set":  func() Set[int, string] { return NewSyncWithValues[int, string]() },
+		"sharded":   func() Set[int, string] { return NewShardedWithValues[int, string](4, nil) },
+		"linked":    func() Set[int, string] { return NewLinkedWithValues[int, string]() },
+		"sorted":    func() Set[int, string] { return NewSortedWithValues[int, string]() },
+		"zero set":  func() Set[int, string] { return &tzSet[int, string]{} },
+		"sync zero": func() Set[int, string] { return newSyncSet[int, string](nil) },
+	}
+	for name, newSet := range sets {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			set := newSet()
+			if name != "zero set" && name != "sync zero" {
+				set.AddWithValue(3, "three")
+				set.AddWithValue(1, "one")
+				set.AddWithValue(2, "two")
+			}
+
+			// When
+			data, err := json.Marshal(set)
+			// Then
+			assert.Nil(t, err)
+			if name == "linked" {
+				assert.Equal(t, `[{"element":3,"value":"three"},{"element":1,"value":"one"},{"element":2,"value":"two"}]`, string(data))
+			} else if set.Size() > 0 {
+				assert.Equal(t, `[{"element":1,"value":"one"},{"element":2,"value":"two"},{"element":3,"value":"three"}]`, string(data))
+			}
+
+			// When
+			decoded := newSet()
+			if set.Size() > 0 {
+				decoded.AddWithValue(42, "stale")
+			}
+			err = json.Unmarshal(data, decoded)
+			// Then
+			assert.Nil(t, err)
+			assert.True(t, set.Equals(decoded))
+			if name == "linked" || name == "sorted" {
+				assert.Equal(t, set.List(), decoded.List())
+			}
+		})
+	}
+}
+
+func TestShouldUnmarshalDuplicatesLeniently(t *testing.T) {
+	// Given
+	set := NewLinkedWithValues[string, int]()
+
+	// When
+	err := json.Unmarshal([]byte(`{"b":1,"a":2,"b":3}`), set)
+
+	// Then the last value wins and the first position is kept
+	assert.Nil(t, err)
+	assert.Equal(t, `{"b":3,"a":2}`, mustMarshal(t, set))
+}
+
+func TestShouldRejectDuplicatesStrictly(t *testing.T) {
+	for _, testCase := range []struct {
+		data string
+		err  string
+	}{
+		{data: `["a", "b", "a"]`, err: "cannot decode set from JSON, duplicate element a at index 2"},
+		{data: `[1, 2, 1]`, err: "cannot decode set from JSON, duplicate element 1 at index 2"},
+	} {
+		// Given
+		set := NewWithoutValues[string]()
+		numbers := NewWithoutValues[int]()
+
+		// When
+		var err error
+		if strings.Contains(testCase.data, `"`) {
+			err = DecodeJSON([]byte(testCase.data), set, StrictDuplicates)
+		} else {
+			err = DecodeJSON([]byte(testCase.data), numbers, StrictDuplicates)
+		}
+
+		// Then
+		assert.EqualError(t, err, testCase.err)
+	}
+
+	// When
+	set := NewWithValues[int, string]()
+	err := DecodeJSON([]byte(`[{"element":1,"value":"a"},{"element":1,"value":"b"}]`), set, StrictDuplicates)
+	// Then
+	assert.EqualError(t, err, "cannot decode set from JSON, duplicate element 1 at index 1")
+	assert.Equal(t, "1 (a)", set.StringWithValues())
+
+	// When
+	set.Clear()
+	err = DecodeJSON([]byte(`[{"element":1,"value":"a"},{"element":1,"value":"b"}]`), set, LenientDuplicates)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "1 (b)", set.StringWithValues())
+}
+
+func TestShouldFailToUnmarshalInvalidJSON(t *testing.T) {
+	for _, testCase := range []struct {
+		data string
+		err  string
+	}{
+		{data: `{"a":1}`, err: "cannot decode set from JSON, expected [ but found { at offset 1"},
+		{data: `["a",1]`, err: "cannot decode set from JSON: json: cannot unmarshal number into Go value of type string"},
+		{data: `["a"`, err: "unexpected end of JSON input"},
+		{data: `"a"`, err: "cannot decode set from JSON, expected [ but found a at offset 3"},
+	} {
+		// Given
+		set := NewWithoutValues[string]()
+		set.AddWithoutValue("existing")
+
+		// When
+		err := json.Unmarshal([]byte(testCase.data), set)
+
+		// Then the set remains unchanged
+		assert.EqualError(t, err, testCase.err)
+		assert.Equal(t, []string{"existing"}, set.List())
+	}
+
+	// Given
+	set := NewWithValues[string, int]()
+	// When
+	err := json.Unmarshal([]byte(`["a"]`), set)
+	// Then
+	assert.EqualError(t, err, "cannot decode set from JSON, expected { but found [ at offset 1")
+}
+
+func TestShouldIgnoreJSONNull(t *testing.T) {
+	// Given
+	set := NewWithoutValues[string]()
+	set.AddWithoutValue("existing")
+
+	// When
+	err := json.Unmarshal([]byte(`null`), set)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"existing"}, set.List())
+}
+
+func TestShouldDecodeLargeArrayFromStream(t *testing.T) {
+	// Given a stream that is produced while decoding
+	reader, writer := io.Pipe()
+	go func() {
+		fmt.Fprint(writer, "[")
+		for i := range 100_000 {
+			if i > 0 {
+				fmt.Fprint(writer, ",")
+			}
+			fmt.Fprint(writer, i)
+		}
+		fmt.Fprint(writer, "] null [1, 2]")
+		writer.Close()
+	}()
+	decoder := NewJSONDecoder[int, InternalEmptyType](reader, StrictDuplicates)
+
+	// When
+	set := NewWithoutValues[int]()
+	err := decoder.Decode(set)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 100_000, set.Size())
+	assert.True(t, set.Contains(99_999))
+
+	// When decoding the next sets of the stream
+	empty := NewWithoutValues[int]()
+	err1 := decoder.Decode(empty)
+	small := NewWithoutValues[int]()
+	err2 := decoder.Decode(small)
+	err3 := decoder.Decode(small)
+	// Then
+	assert.Nil(t, err1)
+	assert.Equal(t, 0, empty.Size())
+	assert.Nil(t, err2)
+	assert.Equal(t, 2, small.Size())
+	assert.EqualError(t, err3, "cannot decode set from JSON: EOF")
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+	return string(data)
+}
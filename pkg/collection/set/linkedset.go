@@ -0,0 +1,531 @@
+package set
+
+import (
+	"crypto/rand"
+	"fmt"
+	"iter"
+	"math/big"
+	"strings"
+)
+
+// LinkedSet is a Set that remembers the order in which the elements have been added (insertion order).
+// Iterating, listing and printing a LinkedSet is deterministic, and all sets derived from a LinkedSet
+// (e.g. by Filter, Map or Intersect) are LinkedSets keeping that order.
+// Re-adding an existing element updates its value but keeps its position.
+// Adding, removing and checking for elements take constant time.
+type LinkedSet[T comparable, V any] interface {
+	Set[T, V]
+
+	First() (T, V, error)
+	Last() (T, V, error)
+	PopFirst() (T, V, error)
+	PopLast() (T, V, error)
+	MoveToFront(T) bool
+	MoveToBack(T) bool
+	Backward() iter.Seq2[T, V]
+}
+
+type linkedNode[T comparable, V any] struct {
+	element    T
+	value      V
+	prev, next *linkedNode[T, V]
+	// removed is set when the node is removed from the set. A removed node keeps its neighbours at the time of removal,
+	// so iterators holding the node can continue with the next node still in the list.
+	removed bool
+}
+
+type linkedSet[T comparable, V any] struct {
+	nodes map[T]*linkedNode[T, V]
+	// root is a sentinel node, root.next is the first and root.prev is the last element of the set
+	root linkedNode[T, V]
+}
+
+// NewLinkedWithValues creates a new, empty, insertion-ordered set that can contain elements of type T having values of type V (like a map).
+func NewLinkedWithValues[T comparable, V any]() LinkedSet[T, V] {
+	return newLinkedSet[T, V]()
+}
+
+// NewLinkedWithoutValues creates a new, empty, insertion-ordered set that can contain elements of type T (like a set of labels).
+func NewLinkedWithoutValues[T comparable]() LinkedSet[T, InternalEmptyType] {
+	return newLinkedSet[T, InternalEmptyType]()
+}
+
+func newLinkedSet[T comparable, V any]() *linkedSet[T, V] {
+	s := &linkedSet[T, V]{
+		nodes: make(map[T]*linkedNode[T, V]),
+	}
+	s.root.next = &s.root
+	s.root.prev = &s.root
+	return s
+}
+
+// insertBefore links the node into the list in front of mark.
+func (s *linkedSet[T, V]) insertBefore(node, mark *linkedNode[T, V]) {
+	node.prev = mark.prev
+	node.next = mark
+	mark.prev.next = node
+	mark.prev = node
+}
+
+// unlink removes the node from the list (but not from the map). The node keeps its own links.
+func (s *linkedSet[T, V]) unlink(node *linkedNode[T, V]) {
+	node.prev.next = node.next
+	node.next.prev = node.prev
+}
+
+// removeNode removes the node from both, the list and the map.
+func (s *linkedSet[T, V]) removeNode(node *linkedNode[T, V]) {
+	s.unlink(node)
+	node.removed = true
+	delete(s.nodes, node.element)
+}
+
+// randIndex returns a random index in the range of the elements.
+// If the set is empty, -1 is returned.
+// This method is not part of the public API.
+func (s *linkedSet[T, V]) randIndex() int64 {
+	if len(s.nodes) == 0 {
+		return -1
+	}
+	n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(s.nodes))))
+	return n.Int64()
+}
+
+// GetElements returns all elements (including the values) of the set as a new map.
+// Since a map has no order, the returned map is a copy, changes to that copy do not interfere with this set.
+func (s *linkedSet[T, V]) GetElements() map[T]V {
+	elements := make(map[T]V, len(s.nodes))
+	for elem, node := range s.nodes {
+		elements[elem] = node.value
+	}
+	return elements
+}
+
+// AddWithValue adds an element with an associated value to the end of the set.
+// If the element already exists, only its value is updated, the element keeps its position.
+func (s *linkedSet[T, V]) AddWithValue(element T, value V) {
+	if node, exists := s.nodes[element]; exists {
+		node.value = value
+		return
+	}
+	node := &linkedNode[T, V]{element: element, value: value}
+	s.nodes[element] = node
+	s.insertBefore(node, &s.root)
+}
+
+// AddWithoutValue adds an element (without an associated value) to the end of the set.
+// If the element already exists, its value is reset but the element keeps its position.
+func (s *linkedSet[T, V]) AddWithoutValue(element T) {
+	var empty V
+	s.AddWithValue(element, empty)
+}
+
+// Remove removes an element from the set.
+func (s *linkedSet[T, V]) Remove(element T) {
+	if node, exists := s.nodes[element]; exists {
+		s.removeNode(node)
+	}
+}
+
+// AddAll adds all elements (including the value) from otherSet to the end of this set (in the iteration order of otherSet).
+// If otherSet is nil, nothing happens.
+// If an element already exists in this set, the value is overwritten with the value from otherSet.
+// The otherSet remains unchanged.
+func (s *linkedSet[T, V]) AddAll(otherSet Set[T, V]) {
+	if otherSet == nil {
+		return
+	}
+	for elem, value := range otherSet.All() {
+		s.AddWithValue(elem, value)
+	}
+}
+
+// RemoveAll removes all elements from otherSet from this set.
+// If otherSet is nil, nothing happens.
+// The otherSet remains unchanged.
+func (s *linkedSet[T, V]) RemoveAll(otherSet Set[T, V]) {
+	if otherSet == nil {
+		return
+	}
+	for elem := range otherSet.Elements() {
+		s.Remove(elem)
+	}
+}
+
+// Clear removes all elements from the set.
+func (s *linkedSet[T, V]) Clear() {
+	for node := s.root.next; node != &s.root; node = node.next {
+		node.removed = true
+	}
+	clear(s.nodes)
+	s.root.next = &s.root
+	s.root.prev = &s.root
+}
+
+// Size returns the number of elements in the set.
+func (s *linkedSet[T, V]) Size() int {
+	return len(s.nodes)
+}
+
+// List returns all elements (without values) of the set as a slice in insertion order.
+// The returned slice is a copy, changes to that copy do not interfere with the original set.
+func (s *linkedSet[T, V]) List() []T {
+	elements := make([]T, 0, s.Size())
+	for elem := range s.Elements() {
+		elements = append(elements, elem)
+	}
+	return elements
+}
+
+// All returns an iterator over all elements and their values of the set in insertion order.
+// The iteration ends with the element which was last when the iteration started, so elements added while iterating are not yielded.
+// Elements may be removed while iterating, removed elements which have not been reached yet are not yielded.
+// The current element may also be moved (e.g. to the back) without being yielded again,
+// but if other elements are moved, elements may be yielded twice or not at all.
+func (s *linkedSet[T, V]) All() iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		last := s.root.prev
+		for node := s.root.next; node != &s.root; {
+			next := node.next
+			if !yield(node.element, node.value) {
+				return
+			}
+			for last.removed {
+				last = last.prev
+			}
+			if node == last {
+				return
+			}
+			for next.removed {
+				next = next.next
+			}
+			node = next
+		}
+	}
+}
+
+// Backward returns an iterator over all elements and their values of the set in reverse insertion order.
+// The iteration ends with the element which was first when the iteration started.
+// Elements may be removed or moved while iterating like for All.
+func (s *linkedSet[T, V]) Backward() iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		first := s.root.next
+		for node := s.root.prev; node != &s.root; {
+			prev := node.prev
+			if !yield(node.element, node.value) {
+				return
+			}
+			for first.removed {
+				first = first.next
+			}
+			if node == first {
+				return
+			}
+			for prev.removed {
+				prev = prev.prev
+			}
+			node = prev
+		}
+	}
+}
+
+// Elements returns an iterator over all elements (without values) of the set in insertion order.
+func (s *linkedSet[T, V]) Elements() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem := range s.All() {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of all elements of the set in insertion order of the elements.
+func (s *linkedSet[T, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range s.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Contains checks whether or not the given element exists in the set (ignoring the value).
+// Returns true if the element is in the set, false otherwise.
+func (s *linkedSet[T, V]) Contains(element T) bool {
+	_, exists := s.nodes[element]
+	return exists
+}
+
+// ContainsAny checks if the set contains at least one of the given elements (ignoring the values).
+// Returns true if at least one of the given elements is in the set, false otherwise.
+func (s *linkedSet[T, V]) ContainsAny(elements ...T) bool {
+	for _, elem := range elements {
+		if _, exists := s.nodes[elem]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// Equals checks if this set is equal to otherSet ignoring the values and the order.
+// Returns true if both sets are of equal size and contain the same elements (ignoring the values), false otherwise.
+func (s *linkedSet[T, V]) Equals(otherSet Set[T, V]) bool {
+	if s.Size() != otherSet.Size() {
+		return false
+	}
+	for elem := range s.nodes {
+		if !otherSet.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset checks if this set is a subset of otherSet.
+// Returns true if all elements of this set are in otherSet, false otherwise.
+// If otherSet is nil, true is returned only if this set is empty.
+// The values are not considered when checking for subset.
+func (s *linkedSet[T, V]) IsSubset(otherSet Set[T, V]) bool {
+	if otherSet == nil {
+		return s.Size() == 0
+	}
+	if s.Size() > otherSet.Size() {
+		return false
+	}
+	for elem := range s.nodes {
+		if !otherSet.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the set.
+// The elements are separated by commas and given in insertion order.
+// The values are not included in the string representation.
+// If the set is empty, an empty string is returned.
+func (s *linkedSet[T, V]) String() string {
+	strElems := make([]string, 0, s.Size())
+	for elem := range s.Elements() {
+		strElems = append(strElems, fmt.Sprintf("%v", elem))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// StringWithValues returns a string representation of the set including values.
+// The elements are separated by commas and given in insertion order.
+// Each element's value is given in braces after the element.
+// If the set is empty, an empty string is returned.
+func (s *linkedSet[T, V]) StringWithValues() string {
+	strElems := make([]string, 0, s.Size())
+	for elem, value := range s.All() {
+		strElems = append(strElems, fmt.Sprintf("%v (%v)", elem, value))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// Copy returns a new linked set containing all elements (including the values) of this set in the same order.
+func (s *linkedSet[T, V]) Copy() Set[T, V] {
+	newSet := newLinkedSet[T, V]()
+	newSet.AddAll(s)
+	return newSet
+}
+
+// Intersect returns a new linked set containing only elements (including the values) that are in both, this set and otherSet.
+// The elements keep the order of this set.
+// If there are no common elements or otherSet is nil, a new empty set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *linkedSet[T, V]) Intersect(otherSet Set[T, V]) Set[T, V] {
+	newSet := newLinkedSet[T, V]()
+	if otherSet == nil {
+		return newSet
+	}
+	commonValues := make(map[T]V)
+	for elem, value := range otherSet.All() {
+		if s.Contains(elem) {
+			commonValues[elem] = value
+		}
+	}
+	for elem := range s.Elements() {
+		if value, exists := commonValues[elem]; exists {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Unite returns a new linked set containing all elements (including the values) of both, this set and otherSet.
+// The elements of this set come first (in the order of this set), followed by the remaining elements of otherSet
+// (in the iteration order of otherSet).
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *linkedSet[T, V]) Unite(otherSet Set[T, V]) Set[T, V] {
+	newSet := newLinkedSet[T, V]()
+	newSet.AddAll(s)
+	newSet.AddAll(otherSet)
+	return newSet
+}
+
+// UniteDisjunctively returns a new linked set containing all elements (including the values) that are in either this set or otherSet, but not in both (symmetric difference).
+// The elements of this set come first (in the order of this set), followed by the elements of otherSet
+// (in the iteration order of otherSet).
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *linkedSet[T, V]) UniteDisjunctively(otherSet Set[T, V]) Set[T, V] {
+	newSet := newLinkedSet[T, V]()
+	if otherSet == nil {
+		newSet.AddAll(s)
+		return newSet
+	}
+	for elem, value := range s.All() {
+		if !otherSet.Contains(elem) {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	for elem, value := range otherSet.All() {
+		if !s.Contains(elem) {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Subtract returns a new linked set containing all elements (including the values) that are in this set but not in otherSet.
+// The elements keep the order of this set.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *linkedSet[T, V]) Subtract(otherSet Set[T, V]) Set[T, V] {
+	newSet := newLinkedSet[T, V]()
+	for elem, value := range s.All() {
+		if otherSet == nil || !otherSet.Contains(elem) {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Filter returns a new linked set containing only elements (including the values) of this set for which the filter function returns true.
+// The elements keep the order of this set.
+// If the filter function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *linkedSet[T, V]) Filter(filterFunc FilterFunc[T, V]) Set[T, V] {
+	if filterFunc == nil {
+		return s.Copy()
+	}
+	newSet := newLinkedSet[T, V]()
+	for elem, value := range s.All() {
+		if filterFunc(elem, value) {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Map returns a new linked set containing all elements (including the values) returned by the map function which is applied to each element of this set.
+// The mapped elements keep the order of this set. If several elements are mapped to the same element,
+// that element is placed at the position of the first of them and gets the value of the last of them.
+// If the map function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *linkedSet[T, V]) Map(mapFunc MapFunc[T, V]) Set[T, V] {
+	if mapFunc == nil {
+		return s.Copy()
+	}
+	newSet := newLinkedSet[T, V]()
+	for elem, value := range s.All() {
+		newElem, newValue := mapFunc(elem, value)
+		newSet.AddWithValue(newElem, newValue)
+	}
+	return newSet
+}
+
+// OneR returns one random element (and its value) from the set.
+// If the set is empty, an error is returned.
+func (s *linkedSet[T, V]) OneR() (T, V, error) {
+	if len(s.nodes) != 0 {
+		rndIndex := s.randIndex()
+		var counter int64 = 0
+
+		for elem, value := range s.All() {
+			if counter == rndIndex {
+				return elem, value, nil
+			}
+			counter++
+		}
+	}
+
+	var emptyT T
+	var emptyV V
+	return emptyT, emptyV, fmt.Errorf("cannot get a random element from set, set is empty")
+}
+
+// First returns the first (i.e. the least recently added) element and its value.
+// If the set is empty, an error is returned.
+func (s *linkedSet[T, V]) First() (T, V, error) {
+	if len(s.nodes) == 0 {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot get first element from set, set is empty")
+	}
+	return s.root.next.element, s.root.next.value, nil
+}
+
+// Last returns the last (i.e. the most recently added) element and its value.
+// If the set is empty, an error is returned.
+func (s *linkedSet[T, V]) Last() (T, V, error) {
+	if len(s.nodes) == 0 {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot get last element from set, set is empty")
+	}
+	return s.root.prev.element, s.root.prev.value, nil
+}
+
+// PopFirst removes the first element from the set and returns it together with its value.
+// If the set is empty, an error is returned.
+func (s *linkedSet[T, V]) PopFirst() (T, V, error) {
+	elem, value, err := s.First()
+	if err != nil {
+		return elem, value, err
+	}
+	s.removeNode(s.root.next)
+	return elem, value, nil
+}
+
+// PopLast removes the last element from the set and returns it together with its value.
+// If the set is empty, an error is returned.
+func (s *linkedSet[T, V]) PopLast() (T, V, error) {
+	elem, value, err := s.Last()
+	if err != nil {
+		return elem, value, err
+	}
+	s.removeNode(s.root.prev)
+	return elem, value, nil
+}
+
+// MoveToFront moves the given element to the front of the set, i.e. it becomes the first element.
+// Returns true if the element is in the set, false otherwise (in which case nothing happens).
+func (s *linkedSet[T, V]) MoveToFront(element T) bool {
+	node, exists := s.nodes[element]
+	if !exists {
+		return false
+	}
+	s.unlink(node)
+	s.insertBefore(node, s.root.next)
+	return true
+}
+
+// MoveToBack moves the given element to the back of the set, i.e. it becomes the last element.
+// Returns true if the element is in the set, false otherwise (in which case nothing happens).
+func (s *linkedSet[T, V]) MoveToBack(element T) bool {
+	node, exists := s.nodes[element]
+	if !exists {
+		return false
+	}
+	s.unlink(node)
+	s.insertBefore(node, &s.root)
+	return true
+}
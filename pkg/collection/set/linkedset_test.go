@@ -0,0 +1,319 @@
+package set
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFruitLinkedSet() LinkedSet[string, string] {
+	set := NewLinkedWithValues[string, string]()
+	set.AddWithValue("cherry", "dark red")
+	set.AddWithValue("apple", "red")
+	set.AddWithValue("mango", "green-orange")
+	set.AddWithValue("banana", "yellow")
+	return set
+}
+
+func TestLinkedSetShouldKeepInsertionOrder(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// Expect
+	assert.Equal(t, []string{"cherry", "apple", "mango", "banana"}, set.List())
+	assert.Equal(t, []string{"cherry", "apple", "mango", "banana"}, slices.Collect(set.Elements()))
+	assert.Equal(t, []string{"dark red", "red", "green-orange", "yellow"}, slices.Collect(set.Values()))
+	assert.Equal(t, "cherry, apple, mango, banana", set.String())
+	assert.Equal(t, "cherry (dark red), apple (red), mango (green-orange), banana (yellow)", set.StringWithValues())
+
+	// When re-adding an existing element
+	set.AddWithValue("cherry", "glossy red")
+	// Then it keeps its position
+	assert.Equal(t, "cherry (glossy red), apple (red), mango (green-orange), banana (yellow)", set.StringWithValues())
+
+	// When
+	set.Remove("apple")
+	set.AddWithValue("apple", "green")
+	// Then
+	assert.Equal(t, []string{"cherry", "mango", "banana", "apple"}, set.List())
+
+	// When
+	backward := []string{}
+	for elem := range set.Backward() {
+		backward = append(backward, elem)
+	}
+	// Then
+	assert.Equal(t, []string{"apple", "banana", "mango", "cherry"}, backward)
+}
+
+func TestLinkedSetShouldAllowRemovingCurrentElementWhileIterating(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// When
+	for elem := range set.Elements() {
+		if strings.Contains(elem, "a") {
+			set.Remove(elem)
+		}
+	}
+
+	// Then
+	assert.Equal(t, []string{"cherry"}, set.List())
+}
+
+func TestLinkedSetShouldSkipElementsRemovedWhileIterating(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// When the following elements are removed
+	forward := []string{}
+	for elem := range set.All() {
+		forward = append(forward, elem)
+		if elem == "cherry" {
+			set.Remove("apple")
+			set.Remove("mango")
+		}
+	}
+	backward := []string{}
+	for elem := range set.Backward() {
+		backward = append(backward, elem)
+		set.Remove("cherry")
+	}
+
+	// Then they are not yielded
+	assert.Equal(t, []string{"cherry", "banana"}, forward)
+	assert.Equal(t, []string{"banana"}, backward)
+	assert.Equal(t, []string{"banana"}, set.List())
+}
+
+func TestLinkedSetShouldNotYieldElementsMovedWhileIteratingTwice(t *testing.T) {
+	// Given
+	newSet := func() LinkedSet[int, InternalEmptyType] {
+		set := NewLinkedWithoutValues[int]()
+		for i := range 4 {
+			set.AddWithoutValue(i)
+		}
+		return set
+	}
+
+	for name, testCase := range map[string]struct {
+		backward bool
+		move     func(LinkedSet[int, InternalEmptyType], int) bool
+		expected []int
+	}{
+		"forward to back":   {move: LinkedSet[int, InternalEmptyType].MoveToBack, expected: []int{0, 2, 3, 1}},
+		"forward to front":  {move: LinkedSet[int, InternalEmptyType].MoveToFront, expected: []int{1, 0, 2, 3}},
+		"backward to back":  {backward: true, move: LinkedSet[int, InternalEmptyType].MoveToBack, expected: []int{0, 1, 3, 2}},
+		"backward to front": {backward: true, move: LinkedSet[int, InternalEmptyType].MoveToFront, expected: []int{2, 0, 1, 3}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			set := newSet()
+			iterate := set.All()
+			if testCase.backward {
+				iterate = set.Backward()
+			}
+
+			// When the current element is moved
+			yielded := []int{}
+			for elem := range iterate {
+				yielded = append(yielded, elem)
+				if elem == 1 && !testCase.backward || elem == 2 && testCase.backward {
+					testCase.move(set, elem)
+				}
+			}
+
+			// Then each element is yielded once
+			if testCase.backward {
+				assert.Equal(t, []int{3, 2, 1, 0}, yielded)
+			} else {
+				assert.Equal(t, []int{0, 1, 2, 3}, yielded)
+			}
+			assert.Equal(t, testCase.expected, set.List())
+		})
+	}
+}
+
+func TestLinkedSetShouldNotYieldElementsAddedWhileIterating(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// When
+	yielded := []string{}
+	for elem := range set.Elements() {
+		yielded = append(yielded, elem)
+		set.AddWithValue(elem+"!", "")
+	}
+
+	// Then
+	assert.Equal(t, []string{"cherry", "apple", "mango", "banana"}, yielded)
+	assert.Equal(t, 8, set.Size())
+}
+
+func TestLinkedSetShouldStopIteratingWhenClearedWhileIterating(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// When
+	yielded := []string{}
+	for elem := range set.Elements() {
+		yielded = append(yielded, elem)
+		set.Clear()
+	}
+
+	// Then
+	assert.Equal(t, []string{"cherry"}, yielded)
+	assert.Equal(t, 0, set.Size())
+}
+
+func TestShouldGetFirstAndLastElementOfLinkedSet(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// When
+	first, firstValue, err1 := set.First()
+	last, lastValue, err2 := set.Last()
+	// Then
+	assert.Equal(t, "cherry", first)
+	assert.Equal(t, "dark red", firstValue)
+	assert.Nil(t, err1)
+	assert.Equal(t, "banana", last)
+	assert.Equal(t, "yellow", lastValue)
+	assert.Nil(t, err2)
+	assert.Equal(t, 4, set.Size())
+
+	// Given
+	emptySet := NewLinkedWithoutValues[string]()
+	// When
+	_, _, err3 := emptySet.First()
+	_, _, err4 := emptySet.Last()
+	// Then
+	assert.NotNil(t, err3)
+	assert.NotNil(t, err4)
+}
+
+func TestShouldPopFirstAndLastElementOfLinkedSet(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// When
+	first, firstValue, err1 := set.PopFirst()
+	last, lastValue, err2 := set.PopLast()
+	// Then
+	assert.Equal(t, "cherry", first)
+	assert.Equal(t, "dark red", firstValue)
+	assert.Nil(t, err1)
+	assert.Equal(t, "banana", last)
+	assert.Equal(t, "yellow", lastValue)
+	assert.Nil(t, err2)
+	assert.Equal(t, []string{"apple", "mango"}, set.List())
+	assert.False(t, set.ContainsAny("cherry", "banana"))
+
+	// When
+	set.PopFirst()
+	set.PopFirst()
+	_, _, err3 := set.PopFirst()
+	_, _, err4 := set.PopLast()
+	// Then
+	assert.NotNil(t, err3)
+	assert.NotNil(t, err4)
+	assert.Equal(t, 0, set.Size())
+}
+
+func TestShouldMoveElementsOfLinkedSet(t *testing.T) {
+	// Given
+	set := newFruitLinkedSet()
+
+	// Expect
+	assert.True(t, set.MoveToBack("cherry"))
+	assert.Equal(t, []string{"apple", "mango", "banana", "cherry"}, set.List())
+	assert.True(t, set.MoveToFront("banana"))
+	assert.Equal(t, []string{"banana", "apple", "mango", "cherry"}, set.List())
+	assert.True(t, set.MoveToFront("banana"))
+	assert.Equal(t, []string{"banana", "apple", "mango", "cherry"}, set.List())
+	assert.False(t, set.MoveToBack("kiwi"))
+	assert.Equal(t, []string{"banana", "apple", "mango", "cherry"}, set.List())
+}
+
+func TestDerivedSetsOfLinkedSetShouldKeepOrder(t *testing.T) {
+	// Given
+	set1 := newFruitLinkedSet()
+	set2 := NewLinkedWithValues[string, string]()
+	set2.AddWithValue("kiwi", "green")
+	set2.AddWithValue("banana", "brownish")
+	set2.AddWithValue("cherry", "glossy red")
+	set2.AddWithValue("lemon", "yellow")
+
+	// Expect
+	assert.Equal(t, "cherry (glossy red), banana (brownish)", set1.Intersect(set2).StringWithValues())
+	assert.Equal(t, "cherry (glossy red), apple (red), mango (green-orange), banana (brownish), kiwi (green), lemon (yellow)", set1.Unite(set2).StringWithValues())
+	assert.Equal(t, "apple, mango, kiwi, lemon", set1.UniteDisjunctively(set2).String())
+	assert.Equal(t, "apple, mango", set1.Subtract(set2).String())
+	assert.Equal(t, "cherry, apple, mango, banana", set1.Copy().String())
+	assert.Equal(t, "apple, mango, banana", set1.Filter(func(elem string, value string) bool {
+		return strings.Contains(elem, "a")
+	}).String())
+	assert.Equal(t, "CHERRY, APPLE, MANGO, BANANA", set1.Map(func(elem string, value string) (string, string) {
+		return strings.ToUpper(elem), value
+	}).String())
+	assert.Equal(t, "fruit (yellow)", set1.Map(func(elem string, value string) (string, string) {
+		return "fruit", value
+	}).StringWithValues())
+
+	// and derived sets are linked sets again
+	_, ok := set1.Intersect(set2).(LinkedSet[string, string])
+	assert.True(t, ok)
+}
+
+func TestLinkedSetShouldBehaveLikeSet(t *testing.T) {
+	// Given
+	set1 := newFruitLinkedSet()
+	plainSet := NewWithValues[string, string]()
+	plainSet.AddWithValue("apple", "green")
+	plainSet.AddWithValue("kiwi", "green")
+
+	// Expect
+	assert.Equal(t, 4, set1.Size())
+	assert.True(t, set1.Contains("apple"))
+	assert.True(t, set1.ContainsAny("kiwi", "mango"))
+	assert.False(t, set1.ContainsAny("kiwi", "lemon"))
+	assert.Equal(t, map[string]string{"cherry": "dark red", "apple": "red", "mango": "green-orange", "banana": "yellow"}, set1.GetElements())
+	assert.True(t, set1.Equals(set1.Copy()))
+	assert.False(t, set1.Equals(plainSet))
+	assert.False(t, set1.IsSubset(plainSet))
+	assert.True(t, set1.Intersect(plainSet).IsSubset(plainSet))
+	assert.Equal(t, map[string]string{"apple": "red"}, plainSet.Intersect(set1).GetElements())
+
+	// When
+	elem, value, err := set1.OneR()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, set1.GetElements()[elem], value)
+
+	// When
+	set1.AddAll(plainSet)
+	// Then
+	assert.Equal(t, 5, set1.Size())
+	last, _, _ := set1.Last()
+	assert.Equal(t, "kiwi", last)
+
+	// When
+	set1.RemoveAll(plainSet)
+	// Then
+	assert.Equal(t, []string{"cherry", "mango", "banana"}, set1.List())
+
+	// When
+	set1.Clear()
+	// Then
+	assert.Equal(t, 0, set1.Size())
+	assert.Equal(t, []string{}, set1.List())
+	_, _, err = set1.OneR()
+	assert.NotNil(t, err)
+
+	// When
+	set1.AddWithoutValue("apple")
+	// Then
+	assert.Equal(t, []string{"apple"}, set1.List())
+}
@@ -0,0 +1,40 @@
+package set
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/order"
+)
+
+// entry is an element of a set together with its value.
+type entry[T comparable, V any] struct {
+	element T
+	value   V
+}
+
+// orderedEntries returns the elements and values of the set in a deterministic order, which is used for encoding and printing sets.
+// Linked sets and sorted sets keep their own order. The elements of all other sets are sorted in their natural order
+// if T is an ordered type, and by their Go-syntax representation (as printed by %#v) otherwise.
+func orderedEntries[T comparable, V any](set Set[T, V]) []entry[T, V] {
+	entries := make([]entry[T, V], 0, set.Size())
+	for elem, value := range set.All() {
+		entries = append(entries, entry[T, V]{element: elem, value: value})
+	}
+	switch set.(type) {
+	case *linkedSet[T, V], *sortedSet[T, V]:
+		return entries
+	}
+
+	if compare := order.Compare[T](); compare != nil {
+		slices.SortFunc(entries, func(a, b entry[T, V]) int { return compare(a.element, b.element) })
+		return entries
+	}
+	keys := make(map[T]string, len(entries))
+	for _, e := range entries {
+		keys[e.element] = fmt.Sprintf("%#v", e.element)
+	}
+	slices.SortFunc(entries, func(a, b entry[T, V]) int { return strings.Compare(keys[a.element], keys[b.element]) })
+	return entries
+}
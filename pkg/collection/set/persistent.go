@@ -0,0 +1,368 @@
+package set
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// PersistentSet is an immutable set of elements of type T having values of type V.
+// Instead of modifying the set, With and Without return new versions of the set. The new versions share most of
+// their structure with the version they have been derived from, so keeping many versions of a large set is cheap.
+// Every version can safely be used by multiple goroutines concurrently.
+// A PersistentSet is implemented as a hash array mapped trie (HAMT), i.e. With, Without and Contains take O(log n) time.
+type PersistentSet[T comparable, V any] interface {
+	With(T, V) PersistentSet[T, V]
+	Without(T) PersistentSet[T, V]
+
+	Size() int
+	Get(T) (V, bool)
+	Contains(T) bool
+	All() iter.Seq2[T, V]
+	Elements() iter.Seq[T]
+	ToSet() Set[T, V]
+}
+
+const (
+	hamtBitsPerLevel = 5
+	hamtBranching    = 1 << hamtBitsPerLevel
+	hamtHashBits     = 64
+)
+
+type hamtEntry[T comparable, V any] struct {
+	hash    uint64
+	element T
+	value   V
+}
+
+// hamtSlot is either an entry or a sub-node.
+type hamtSlot[T comparable, V any] struct {
+	entry *hamtEntry[T, V]
+	node  *hamtNode[T, V]
+}
+
+// hamtNode is either a bitmap-indexed node having one slot for each bit set in the bitmap,
+// or (below the last level) a collision node holding entries whose hashes are all equal.
+type hamtNode[T comparable, V any] struct {
+	bitmap     uint32
+	slots      []hamtSlot[T, V]
+	collisions []*hamtEntry[T, V]
+	// owner marks nodes that have been created by a transient bulk operation and may still be modified in place by it
+	owner *hamtOwner
+}
+
+// hamtOwner identifies a transient bulk operation.
+// It must not be a zero-size type since pointers to distinct zero-size variables may be equal.
+type hamtOwner struct {
+	_ byte
+}
+
+type hamtSet[T comparable, V any] struct {
+	root     *hamtNode[T, V]
+	size     int
+	hashFunc HashFunc[T]
+}
+
+// NewPersistentWithValues creates a new, empty, persistent set that can contain elements of type T having values of type V (like a map).
+// If hashFunc is nil, DefaultHashFunc is used.
+func NewPersistentWithValues[T comparable, V any](hashFunc HashFunc[T]) PersistentSet[T, V] {
+	if hashFunc == nil {
+		hashFunc = DefaultHashFunc[T]()
+	}
+	return &hamtSet[T, V]{root: &hamtNode[T, V]{}, hashFunc: hashFunc}
+}
+
+// NewPersistentWithoutValues creates a new, empty, persistent set that can contain elements of type T (like a set of labels).
+// If hashFunc is nil, DefaultHashFunc is used.
+func NewPersistentWithoutValues[T comparable](hashFunc HashFunc[T]) PersistentSet[T, InternalEmptyType] {
+	return NewPersistentWithValues[T, InternalEmptyType](hashFunc)
+}
+
+// ToPersistent creates a new persistent set containing all elements (including the values) of the given set.
+// The trie is built in place without copying any node, so the conversion takes O(n) time.
+// If set is nil, an empty persistent set is returned. If hashFunc is nil, DefaultHashFunc is used.
+func ToPersistent[T comparable, V any](set Set[T, V], hashFunc HashFunc[T]) PersistentSet[T, V] {
+	persistentSet := NewPersistentWithValues[T, V](hashFunc).(*hamtSet[T, V])
+	if set == nil {
+		return persistentSet
+	}
+	owner := &hamtOwner{}
+	for elem, value := range set.All() {
+		var added bool
+		entry := &hamtEntry[T, V]{hash: persistentSet.hashFunc(elem), element: elem, value: value}
+		persistentSet.root, added = persistentSet.root.with(0, entry, owner)
+		if added {
+			persistentSet.size++
+		}
+	}
+	return persistentSet
+}
+
+// editable returns this node if it may be modified in place by the given owner, otherwise a shallow copy owned by the owner.
+// If owner is nil, a copy is always returned.
+func (n *hamtNode[T, V]) editable(owner *hamtOwner) *hamtNode[T, V] {
+	if owner != nil && n.owner == owner {
+		return n
+	}
+	return &hamtNode[T, V]{
+		bitmap:     n.bitmap,
+		slots:      append([]hamtSlot[T, V](nil), n.slots...),
+		collisions: append([]*hamtEntry[T, V](nil), n.collisions...),
+		owner:      owner,
+	}
+}
+
+// fragment returns the part of the hash used as index on the level with the given shift.
+func fragment(hash uint64, shift uint) uint32 {
+	return uint32(hash>>shift) & (hamtBranching - 1)
+}
+
+// index returns the bit of the fragment in the bitmap and the index of the corresponding slot.
+func (n *hamtNode[T, V]) index(frag uint32) (uint32, int) {
+	bit := uint32(1) << frag
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// newHamtPair creates a sub-node on the level with the given shift holding two entries with different elements.
+func newHamtPair[T comparable, V any](shift uint, e1, e2 *hamtEntry[T, V], owner *hamtOwner) *hamtNode[T, V] {
+	if shift >= hamtHashBits {
+		return &hamtNode[T, V]{collisions: []*hamtEntry[T, V]{e1, e2}, owner: owner}
+	}
+	frag1, frag2 := fragment(e1.hash, shift), fragment(e2.hash, shift)
+	if frag1 == frag2 {
+		return &hamtNode[T, V]{
+			bitmap: uint32(1) << frag1,
+			slots:  []hamtSlot[T, V]{{node: newHamtPair(shift+hamtBitsPerLevel, e1, e2, owner)}},
+			owner:  owner,
+		}
+	}
+	if frag1 > frag2 {
+		e1, e2 = e2, e1
+		frag1, frag2 = frag2, frag1
+	}
+	return &hamtNode[T, V]{
+		bitmap: uint32(1)<<frag1 | uint32(1)<<frag2,
+		slots:  []hamtSlot[T, V]{{entry: e1}, {entry: e2}},
+		owner:  owner,
+	}
+}
+
+// with returns the node (on the level with the given shift) with the entry added or replaced,
+// as well as whether the element has been added (true) or its value has been replaced (false).
+func (n *hamtNode[T, V]) with(shift uint, entry *hamtEntry[T, V], owner *hamtOwner) (*hamtNode[T, V], bool) {
+	if shift >= hamtHashBits {
+		newNode := n.editable(owner)
+		for i, existing := range newNode.collisions {
+			if existing.element == entry.element {
+				newNode.collisions[i] = entry
+				return newNode, false
+			}
+		}
+		newNode.collisions = append(newNode.collisions, entry)
+		return newNode, true
+	}
+
+	bit, idx := n.index(fragment(entry.hash, shift))
+	newNode := n.editable(owner)
+	if n.bitmap&bit == 0 {
+		newNode.bitmap |= bit
+		newNode.slots = append(newNode.slots, hamtSlot[T, V]{})
+		copy(newNode.slots[idx+1:], newNode.slots[idx:])
+		newNode.slots[idx] = hamtSlot[T, V]{entry: entry}
+		return newNode, true
+	}
+
+	slot := n.slots[idx]
+	if slot.node != nil {
+		child, added := slot.node.with(shift+hamtBitsPerLevel, entry, owner)
+		newNode.slots[idx] = hamtSlot[T, V]{node: child}
+		return newNode, added
+	}
+	if slot.entry.element == entry.element {
+		newNode.slots[idx] = hamtSlot[T, V]{entry: entry}
+		return newNode, false
+	}
+	newNode.slots[idx] = hamtSlot[T, V]{node: newHamtPair(shift+hamtBitsPerLevel, slot.entry, entry, owner)}
+	return newNode, true
+}
+
+// without returns the node (on the level with the given shift) with the element removed, or nil if the node becomes empty,
+// as well as whether the element has been removed. If the element is not in the node, the node itself is returned.
+func (n *hamtNode[T, V]) without(shift uint, hash uint64, element T) (*hamtNode[T, V], bool) {
+	if shift >= hamtHashBits {
+		for i, existing := range n.collisions {
+			if existing.element == element {
+				if len(n.collisions) == 1 {
+					return nil, true
+				}
+				newNode := n.editable(nil)
+				newNode.collisions = append(newNode.collisions[:i], newNode.collisions[i+1:]...)
+				return newNode, true
+			}
+		}
+		return n, false
+	}
+
+	bit, idx := n.index(fragment(hash, shift))
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	slot := n.slots[idx]
+	var newSlot hamtSlot[T, V]
+	if slot.node != nil {
+		child, removed := slot.node.without(shift+hamtBitsPerLevel, hash, element)
+		if !removed {
+			return n, false
+		}
+		if child != nil {
+			newSlot = hamtSlot[T, V]{node: child}
+			// a sub-node holding a single entry is replaced by that entry to keep the trie compact
+			if entry := child.singleEntry(); entry != nil {
+				newSlot = hamtSlot[T, V]{entry: entry}
+			}
+		}
+	} else if slot.entry.element != element {
+		return n, false
+	}
+
+	newNode := n.editable(nil)
+	if newSlot.entry != nil || newSlot.node != nil {
+		newNode.slots[idx] = newSlot
+		return newNode, true
+	}
+	if len(n.slots) == 1 && shift > 0 {
+		return nil, true
+	}
+	newNode.bitmap &^= bit
+	newNode.slots = append(newNode.slots[:idx], newNode.slots[idx+1:]...)
+	return newNode, true
+}
+
+// singleEntry returns the only entry of the node if the node holds exactly one entry and no sub-node, nil otherwise.
+func (n *hamtNode[T, V]) singleEntry() *hamtEntry[T, V] {
+	if len(n.collisions) == 1 {
+		return n.collisions[0]
+	}
+	if len(n.slots) == 1 && n.slots[0].entry != nil {
+		return n.slots[0].entry
+	}
+	return nil
+}
+
+// get returns the entry of the element or nil if the element is not in the node.
+func (n *hamtNode[T, V]) get(hash uint64, element T) *hamtEntry[T, V] {
+	node := n
+	for shift := uint(0); ; shift += hamtBitsPerLevel {
+		if shift >= hamtHashBits {
+			for _, entry := range node.collisions {
+				if entry.element == element {
+					return entry
+				}
+			}
+			return nil
+		}
+		bit, idx := node.index(fragment(hash, shift))
+		if node.bitmap&bit == 0 {
+			return nil
+		}
+		slot := node.slots[idx]
+		if slot.node == nil {
+			if slot.entry.element == element {
+				return slot.entry
+			}
+			return nil
+		}
+		node = slot.node
+	}
+}
+
+// all iterates over all entries of the node and its sub-nodes.
+func (n *hamtNode[T, V]) all(yield func(T, V) bool) bool {
+	for _, entry := range n.collisions {
+		if !yield(entry.element, entry.value) {
+			return false
+		}
+	}
+	for _, slot := range n.slots {
+		if slot.node != nil {
+			if !slot.node.all(yield) {
+				return false
+			}
+		} else if !yield(slot.entry.element, slot.entry.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// With returns a new version of the set containing the given element with the given value.
+// If the element already exists, its value is replaced in the new version.
+// This set remains unchanged.
+func (s *hamtSet[T, V]) With(element T, value V) PersistentSet[T, V] {
+	entry := &hamtEntry[T, V]{hash: s.hashFunc(element), element: element, value: value}
+	root, added := s.root.with(0, entry, nil)
+	newSet := &hamtSet[T, V]{root: root, size: s.size, hashFunc: s.hashFunc}
+	if added {
+		newSet.size++
+	}
+	return newSet
+}
+
+// Without returns a new version of the set not containing the given element.
+// If the element is not in the set, this set itself is returned.
+// This set remains unchanged.
+func (s *hamtSet[T, V]) Without(element T) PersistentSet[T, V] {
+	root, removed := s.root.without(0, s.hashFunc(element), element)
+	if !removed {
+		return s
+	}
+	return &hamtSet[T, V]{root: root, size: s.size - 1, hashFunc: s.hashFunc}
+}
+
+// Size returns the number of elements in the set.
+func (s *hamtSet[T, V]) Size() int {
+	return s.size
+}
+
+// Get returns the value of the given element and true if the element is in the set,
+// the zero value of V and false otherwise.
+func (s *hamtSet[T, V]) Get(element T) (V, bool) {
+	if entry := s.root.get(s.hashFunc(element), element); entry != nil {
+		return entry.value, true
+	}
+	var empty V
+	return empty, false
+}
+
+// Contains checks whether or not the given element exists in the set (ignoring the value).
+// Returns true if the element is in the set, false otherwise.
+func (s *hamtSet[T, V]) Contains(element T) bool {
+	return s.root.get(s.hashFunc(element), element) != nil
+}
+
+// All returns an iterator over all elements and their values of the set.
+// The order of the elements is not defined, but it is the same for every iteration over the same version.
+func (s *hamtSet[T, V]) All() iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		s.root.all(yield)
+	}
+}
+
+// Elements returns an iterator over all elements (without values) of the set.
+// The order of the elements is not defined, but it is the same for every iteration over the same version.
+func (s *hamtSet[T, V]) Elements() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.root.all(func(elem T, _ V) bool {
+			return yield(elem)
+		})
+	}
+}
+
+// ToSet returns a new mutable set containing all elements (including the values) of this set.
+// Changes to the returned set do not interfere with this set.
+func (s *hamtSet[T, V]) ToSet() Set[T, V] {
+	elements := make(map[T]V, s.size)
+	for elem, value := range s.All() {
+		elements[elem] = value
+	}
+	return &tzSet[T, V]{elements: elements}
+}
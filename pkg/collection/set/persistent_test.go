@@ -0,0 +1,162 @@
+package set
+
+import (
+	"maps"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentSetShouldKeepOldVersionsUnchanged(t *testing.T) {
+	// Given
+	version0 := NewPersistentWithValues[string, string](nil)
+
+	// When
+	version1 := version0.With("apple", "red")
+	version2 := version1.With("banana", "yellow")
+	version3 := version2.With("apple", "green")
+	version4 := version3.Without("banana")
+
+	// Then
+	assert.Equal(t, 0, version0.Size())
+	assert.False(t, version0.Contains("apple"))
+
+	assert.Equal(t, 1, version1.Size())
+	value, ok := version1.Get("apple")
+	assert.True(t, ok)
+	assert.Equal(t, "red", value)
+
+	assert.Equal(t, 2, version2.Size())
+	assert.Equal(t, map[string]string{"apple": "red", "banana": "yellow"}, maps.Collect(version2.All()))
+
+	assert.Equal(t, 2, version3.Size())
+	assert.Equal(t, map[string]string{"apple": "green", "banana": "yellow"}, maps.Collect(version3.All()))
+
+	assert.Equal(t, 1, version4.Size())
+	assert.Equal(t, map[string]string{"apple": "green"}, maps.Collect(version4.All()))
+	_, ok = version4.Get("banana")
+	assert.False(t, ok)
+
+	// When removing a non-existing element
+	version5 := version4.Without("kiwi")
+	// Then the same version is returned
+	assert.Same(t, version4, version5)
+}
+
+func TestPersistentSetShouldShareStructure(t *testing.T) {
+	// Given
+	set := NewPersistentWithoutValues[int](nil)
+	for i := range 10_000 {
+		set = set.With(i, internalEmptyValue)
+	}
+
+	// When
+	newSet := set.With(10_000, internalEmptyValue).(*hamtSet[int, InternalEmptyType])
+
+	// Then only the nodes on the path to the new element are new
+	oldRoot := set.(*hamtSet[int, InternalEmptyType]).root
+	shared := 0
+	for i, slot := range newSet.root.slots {
+		if slot.node == oldRoot.slots[i].node {
+			shared++
+		}
+	}
+	assert.Equal(t, len(oldRoot.slots)-1, shared)
+	assert.Equal(t, 10_000, set.Size())
+	assert.Equal(t, 10_001, newSet.Size())
+}
+
+func TestPersistentSetShouldBehaveLikeMap(t *testing.T) {
+	// Given
+	set := NewPersistentWithValues[int, int](nil)
+	reference := map[int]int{}
+	versions := []PersistentSet[int, int]{}
+	references := []map[int]int{}
+	rnd := rand.New(rand.NewPCG(4, 2))
+
+	// When adding and removing random elements
+	for i := range 20_000 {
+		elem := rnd.IntN(2000)
+		if rnd.IntN(3) == 0 {
+			set = set.Without(elem)
+			delete(reference, elem)
+		} else {
+			set = set.With(elem, i)
+			reference[elem] = i
+		}
+		if i%1000 == 0 {
+			versions = append(versions, set)
+			references = append(references, maps.Clone(reference))
+		}
+	}
+
+	// Then
+	assert.Equal(t, len(reference), set.Size())
+	assert.Equal(t, reference, maps.Collect(set.All()))
+	for i, version := range versions {
+		assert.Equal(t, references[i], maps.Collect(version.All()))
+		assert.Equal(t, len(references[i]), version.Size())
+	}
+}
+
+func TestPersistentSetShouldHandleHashCollisions(t *testing.T) {
+	// Given
+	constantHash := func(string) uint64 { return 42 }
+	set := NewPersistentWithValues[string, int](constantHash)
+
+	// When
+	set = set.With("apple", 1).With("banana", 2).With("cherry", 3).With("banana", 4)
+
+	// Then
+	assert.Equal(t, 3, set.Size())
+	assert.Equal(t, map[string]int{"apple": 1, "banana": 4, "cherry": 3}, maps.Collect(set.All()))
+
+	// When
+	set = set.Without("apple").Without("cherry")
+	// Then
+	assert.Equal(t, map[string]int{"banana": 4}, maps.Collect(set.All()))
+	assert.True(t, set.Contains("banana"))
+
+	// When
+	set = set.Without("banana")
+	// Then
+	assert.Equal(t, 0, set.Size())
+	assert.False(t, set.Contains("banana"))
+}
+
+func TestShouldConvertBetweenPersistentAndMutableSet(t *testing.T) {
+	// Given
+	mutableSet := NewWithValues[string, string]()
+	mutableSet.AddWithValue("apple", "red")
+	mutableSet.AddWithValue("banana", "yellow")
+
+	// When
+	persistentSet := ToPersistent(mutableSet, nil)
+	// Then
+	assert.Equal(t, 2, persistentSet.Size())
+	assert.Equal(t, mutableSet.GetElements(), maps.Collect(persistentSet.All()))
+
+	// When changing the mutable set
+	mutableSet.AddWithValue("cherry", "dark red")
+	// Then the persistent set remains unchanged
+	assert.False(t, persistentSet.Contains("cherry"))
+
+	// When
+	convertedSet := persistentSet.With("mango", "green-orange").ToSet()
+	convertedSet.Remove("apple")
+	// Then
+	assert.Equal(t, map[string]string{"banana": "yellow", "mango": "green-orange"}, convertedSet.GetElements())
+	assert.True(t, persistentSet.Contains("apple"))
+
+	// When versions derived from a converted set are changed
+	version1 := persistentSet.With("kiwi", "green")
+	version2 := persistentSet.With("lemon", "yellow")
+	// Then they don't interfere with each other
+	assert.False(t, version1.Contains("lemon"))
+	assert.False(t, version2.Contains("kiwi"))
+	assert.ElementsMatch(t, []string{"apple", "banana"}, Collect(persistentSet.Elements()).List())
+
+	// Expect
+	assert.Equal(t, 0, ToPersistent[string, string](nil, nil).Size())
+}
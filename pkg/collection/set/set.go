@@ -4,6 +4,8 @@ package set
 import (
 	"crypto/rand"
 	"fmt"
+	"iter"
+	"maps"
 	"math/big"
 	"strings"
 )
@@ -40,6 +42,9 @@ type Set[T comparable, V any] interface {
 
 	Size() int
 	List() []T
+	All() iter.Seq2[T, V]
+	Elements() iter.Seq[T]
+	Values() iter.Seq[V]
 	Contains(T) bool
 	ContainsAny(...T) bool
 	Equals(Set[T, V]) bool
@@ -125,7 +130,7 @@ func (s *tzSet[T, V]) AddAll(otherSet Set[T, V]) {
 	if otherSet == nil {
 		return
 	}
-	for elem, value := range otherSet.GetElements() {
+	for elem, value := range otherSet.All() {
 		s.elements[elem] = value
 	}
 }
@@ -137,7 +142,7 @@ func (s *tzSet[T, V]) RemoveAll(otherSet Set[T, V]) {
 	if otherSet == nil {
 		return
 	}
-	for elem := range otherSet.GetElements() {
+	for elem := range otherSet.Elements() {
 		delete(s.elements, elem)
 	}
 }
@@ -162,6 +167,26 @@ func (s *tzSet[T, V]) List() []T {
 	return elements
 }
 
+// All returns an iterator over all elements and their values of the set.
+// The order of the elements is not defined.
+// The set must not be modified while iterating except for removing the current element.
+func (s *tzSet[T, V]) All() iter.Seq2[T, V] {
+	return maps.All(s.elements)
+}
+
+// Elements returns an iterator over all elements (without values) of the set.
+// The order of the elements is not defined.
+// Contrary to List, no slice is allocated.
+func (s *tzSet[T, V]) Elements() iter.Seq[T] {
+	return maps.Keys(s.elements)
+}
+
+// Values returns an iterator over the values of all elements of the set.
+// The order of the values is not defined.
+func (s *tzSet[T, V]) Values() iter.Seq[V] {
+	return maps.Values(s.elements)
+}
+
 // Contains checks whether or not the given element exists in the set (ignoring the value).
 // Returns true if the element is in the set, false otherwise.
 // The value associated with the element is not considered, i.e. it doesn't matter whether
@@ -265,7 +290,7 @@ func (s *tzSet[T, V]) Intersect(otherSet Set[T, V]) Set[T, V] {
 		return NewWithValues[T, V]()
 	}
 	newSet := NewWithValues[T, V]()
-	for elem, value := range otherSet.GetElements() {
+	for elem, value := range otherSet.All() {
 		if s.Contains(elem) {
 			newSet.AddWithValue(elem, value)
 		}
@@ -300,7 +325,7 @@ func (s *tzSet[T, V]) UniteDisjunctively(otherSet Set[T, V]) Set[T, V] {
 			newSet.AddWithValue(elem, value)
 		}
 	}
-	for elem, value := range otherSet.GetElements() {
+	for elem, value := range otherSet.All() {
 		if !s.Contains(elem) {
 			newSet.AddWithValue(elem, value)
 		}
@@ -365,7 +390,7 @@ func MapFree[T comparable, V any, TOut comparable, VOut any](set Set[T, V], mapF
 		return make(map[TOut]VOut)
 	}
 	newSet := make(map[TOut]VOut)
-	for elem, value := range set.GetElements() {
+	for elem, value := range set.All() {
 		newElem, newValue := mapFunc(elem, value)
 		newSet[newElem] = newValue
 	}
@@ -380,7 +405,7 @@ func MapToList[T comparable, V any, EOut any](set Set[T, V], mapFunc MapToListFu
 		return make([]EOut, 0)
 	}
 	newList := make([]EOut, 0, set.Size())
-	for elem, value := range set.GetElements() {
+	for elem, value := range set.All() {
 		newListElem := mapFunc(elem, value)
 		newList = append(newList, newListElem)
 	}
@@ -395,12 +420,44 @@ func Reduce[T comparable, V any, Acc any](set Set[T, V], reduceFunc func(T, V, A
 		return initial
 	}
 	var acc = initial
-	for elem, value := range set.GetElements() {
+	for elem, value := range set.All() {
 		acc = reduceFunc(elem, value, acc)
 	}
 	return acc
 }
 
+// Collect collects the elements from seq into a new set without values.
+// Duplicate elements in seq are added only once.
+func Collect[T comparable](seq iter.Seq[T]) Set[T, InternalEmptyType] {
+	newSet := NewWithoutValues[T]()
+	Insert(newSet, seq)
+	return newSet
+}
+
+// CollectWithValues collects the element-value pairs from seq into a new set.
+// If an element occurs more than once in seq, the last value wins.
+func CollectWithValues[T comparable, V any](seq iter.Seq2[T, V]) Set[T, V] {
+	newSet := NewWithValues[T, V]()
+	InsertWithValues(newSet, seq)
+	return newSet
+}
+
+// Insert adds the elements from seq (without values) to the given set.
+// Elements already existing in the set get the zero value of V.
+func Insert[T comparable, V any](set Set[T, V], seq iter.Seq[T]) {
+	for elem := range seq {
+		set.AddWithoutValue(elem)
+	}
+}
+
+// InsertWithValues adds the element-value pairs from seq to the given set.
+// If an element already exists in the set, its value is overwritten.
+func InsertWithValues[T comparable, V any](set Set[T, V], seq iter.Seq2[T, V]) {
+	for elem, value := range seq {
+		set.AddWithValue(elem, value)
+	}
+}
+
 // OneR returns one random element (and its value) from the set.
 // If the set is empty, an error is returned.
 // If there is only one element in the set, that element and its value are returned.
@@ -2,6 +2,7 @@ package set
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"testing"
@@ -472,6 +473,103 @@ func TestShouldListAllSetItems(t *testing.T) {
 	assert.Equal(t, 0, emptySet.Size())
 }
 
+func TestShouldIterateOverAllElementsAndValues(t *testing.T) {
+	// Given
+	set := NewWithValues[string, string]()
+	set.AddWithValue("apple", "red")
+	set.AddWithValue("banana", "yellow")
+	set.AddWithValue("cherry", "dark red")
+
+	// When
+	elements := map[string]string{}
+	for elem, value := range set.All() {
+		elements[elem] = value
+	}
+	// Then
+	assert.Equal(t, map[string]string{"apple": "red", "banana": "yellow", "cherry": "dark red"}, elements)
+
+	// When
+	collected := maps.Collect(set.All())
+	// Then
+	assert.Equal(t, set.GetElements(), collected)
+
+	// When breaking out of the loop early
+	count := 0
+	for range set.All() {
+		count++
+		break
+	}
+	// Then
+	assert.Equal(t, 1, count)
+
+	// Given
+	emptySet := NewWithValues[string, string]()
+	// Expect
+	for range emptySet.All() {
+		assert.Fail(t, "empty set must not yield elements")
+	}
+}
+
+func TestShouldIterateOverElements(t *testing.T) {
+	// Given
+	set := NewWithValues[string, string]()
+	set.AddWithValue("apple", "red")
+	set.AddWithValue("banana", "yellow")
+	set.AddWithValue("cherry", "dark red")
+
+	// When
+	elements := slices.Sorted(set.Elements())
+	// Then
+	assert.Equal(t, []string{"apple", "banana", "cherry"}, elements)
+
+	// When
+	values := slices.Sorted(set.Values())
+	// Then
+	assert.Equal(t, []string{"dark red", "red", "yellow"}, values)
+}
+
+func TestShouldCollectElementsIntoSet(t *testing.T) {
+	// When
+	set1 := Collect(slices.Values([]string{"apple", "banana", "apple", "cherry"}))
+	// Then
+	assert.Equal(t, 3, set1.Size())
+	assert.True(t, set1.Contains("apple"))
+	assert.True(t, set1.Contains("banana"))
+	assert.True(t, set1.Contains("cherry"))
+
+	// When
+	set2 := CollectWithValues(maps.All(map[string]int{"apple": 5, "banana": 6}))
+	// Then
+	assert.Equal(t, 2, set2.Size())
+	assert.Equal(t, 5, set2.GetElements()["apple"])
+	assert.Equal(t, 6, set2.GetElements()["banana"])
+
+	// When
+	set3 := Collect(set2.Elements())
+	// Then
+	assert.ElementsMatch(t, []string{"apple", "banana"}, set3.List())
+}
+
+func TestShouldInsertElementsIntoSet(t *testing.T) {
+	// Given
+	set := NewWithValues[string, string]()
+	set.AddWithValue("apple", "red")
+
+	// When
+	InsertWithValues(set, maps.All(map[string]string{"apple": "green", "banana": "yellow"}))
+	// Then
+	assert.Equal(t, 2, set.Size())
+	assert.Equal(t, "green", set.GetElements()["apple"])
+	assert.Equal(t, "yellow", set.GetElements()["banana"])
+
+	// When
+	Insert(set, slices.Values([]string{"cherry", "mango"}))
+	// Then
+	assert.Equal(t, 4, set.Size())
+	assert.True(t, set.Contains("cherry"))
+	assert.Equal(t, "", set.GetElements()["mango"])
+}
+
 func TestShouldCheckIfSetWithoutValuesContainsTheGivenItem(t *testing.T) {
 	// Given
 	set := NewWithoutValues[string]()
@@ -0,0 +1,558 @@
+package set
+
+import (
+	"crypto/rand"
+	"fmt"
+	"iter"
+	"maps"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// DefaultShardCount is the number of shards used by a sharded set if no positive shard count is given.
+const DefaultShardCount = 32
+
+type shard[T comparable, V any] struct {
+	mu       sync.RWMutex
+	elements map[T]V
+}
+
+type shardedSet[T comparable, V any] struct {
+	id       uint64
+	shards   []*shard[T, V]
+	hashFunc HashFunc[T]
+}
+
+// setReader is the read-only part of a set needed by binary operations.
+type setReader[T comparable, V any] interface {
+	Size() int
+	Contains(T) bool
+	All() iter.Seq2[T, V]
+}
+
+// shardedView provides access to a sharded set without any locking.
+// The caller must hold the locks of all shards as long as the view is used.
+type shardedView[T comparable, V any] struct {
+	set *shardedSet[T, V]
+}
+
+// NewShardedWithValues creates a new, empty, concurrency-safe set that can contain elements of type T having values of type V (like a map).
+// The elements are partitioned by their hash over shardCount independently locked shards, which reduces lock contention
+// when many goroutines access the set concurrently.
+// If shardCount is not positive, DefaultShardCount is used. If hashFunc is nil, DefaultHashFunc is used.
+func NewShardedWithValues[T comparable, V any](shardCount int, hashFunc HashFunc[T]) Set[T, V] {
+	return newShardedSet[T, V](shardCount, hashFunc)
+}
+
+// NewShardedWithoutValues creates a new, empty, concurrency-safe set that can contain elements of type T (like a set of labels).
+// The elements are partitioned by their hash over shardCount independently locked shards, which reduces lock contention
+// when many goroutines access the set concurrently.
+// If shardCount is not positive, DefaultShardCount is used. If hashFunc is nil, DefaultHashFunc is used.
+func NewShardedWithoutValues[T comparable](shardCount int, hashFunc HashFunc[T]) Set[T, InternalEmptyType] {
+	return newShardedSet[T, InternalEmptyType](shardCount, hashFunc)
+}
+
+func newShardedSet[T comparable, V any](shardCount int, hashFunc HashFunc[T]) *shardedSet[T, V] {
+	if shardCount <= 0 {
+		shardCount = DefaultShardCount
+	}
+	if hashFunc == nil {
+		hashFunc = DefaultHashFunc[T]()
+	}
+	shards := make([]*shard[T, V], shardCount)
+	for i := range shards {
+		shards[i] = &shard[T, V]{elements: createNewWithValues[T, V]()}
+	}
+	return &shardedSet[T, V]{
+		// shares the id sequence with sync sets, an id just has to be unique among sharded sets
+		id:       syncSetCounter.Add(1),
+		shards:   shards,
+		hashFunc: hashFunc,
+	}
+}
+
+// newEmptyLike creates a new, empty sharded set with the same shard count and hash function as this set.
+func (s *shardedSet[T, V]) newEmptyLike() *shardedSet[T, V] {
+	return newShardedSet[T, V](len(s.shards), s.hashFunc)
+}
+
+// shardFor returns the shard responsible for the given element.
+func (s *shardedSet[T, V]) shardFor(element T) *shard[T, V] {
+	return s.shards[s.hashFunc(element)%uint64(len(s.shards))]
+}
+
+// lockAll locks all shards (for writing if write is true, for reading otherwise) in the order of their index
+// and returns the function releasing all locks.
+// While all shards are locked, the set cannot be changed, i.e. operations see a consistent snapshot.
+func (s *shardedSet[T, V]) lockAll(write bool) func() {
+	for _, sh := range s.shards {
+		if write {
+			sh.mu.Lock()
+		} else {
+			sh.mu.RLock()
+		}
+	}
+	return func() {
+		for _, sh := range s.shards {
+			if write {
+				sh.mu.Unlock()
+			} else {
+				sh.mu.RUnlock()
+			}
+		}
+	}
+}
+
+// lockWith locks all shards of this set (for writing if write is true, for reading otherwise) and returns a reader
+// for otherSet that can be used while the locks are held, as well as the function releasing all locks.
+// If otherSet is a sharded set, too, its shards are locked for reading; two sharded sets are always locked in the order
+// of their ids, so concurrent binary operations cannot deadlock.
+// Any other set is read into a snapshot before locking, so no lock of this set is held while calling into otherSet.
+// If otherSet is nil, the returned reader is nil.
+func (s *shardedSet[T, V]) lockWith(otherSet Set[T, V], write bool) (setReader[T, V], func()) {
+	if otherSet == nil {
+		return nil, s.lockAll(write)
+	}
+	other, ok := otherSet.(*shardedSet[T, V])
+	if !ok {
+		snapshot := &tzSet[T, V]{elements: maps.Collect(otherSet.All())}
+		return snapshot, s.lockAll(write)
+	}
+	if other == s {
+		return shardedView[T, V]{set: s}, s.lockAll(write)
+	}
+	var unlockSelf, unlockOther func()
+	if s.id < other.id {
+		unlockSelf = s.lockAll(write)
+		unlockOther = other.lockAll(false)
+	} else {
+		unlockOther = other.lockAll(false)
+		unlockSelf = s.lockAll(write)
+	}
+	return shardedView[T, V]{set: other}, func() {
+		unlockOther()
+		unlockSelf()
+	}
+}
+
+// put adds an element without locking, the caller must hold the lock of the corresponding shard
+// or be the only one having access to the set.
+func (s *shardedSet[T, V]) put(element T, value V) {
+	s.shardFor(element).elements[element] = value
+}
+
+// contains checks for an element without locking.
+func (s *shardedSet[T, V]) contains(element T) bool {
+	_, exists := s.shardFor(element).elements[element]
+	return exists
+}
+
+// size counts the elements without locking.
+func (s *shardedSet[T, V]) size() int {
+	size := 0
+	for _, sh := range s.shards {
+		size += len(sh.elements)
+	}
+	return size
+}
+
+// all iterates over all elements without locking.
+func (s *shardedSet[T, V]) all(yield func(T, V) bool) {
+	for _, sh := range s.shards {
+		for elem, value := range sh.elements {
+			if !yield(elem, value) {
+				return
+			}
+		}
+	}
+}
+
+// snapshot returns a copy of all elements taken while all shards are locked.
+func (s *shardedSet[T, V]) snapshot() map[T]V {
+	unlock := s.lockAll(false)
+	defer unlock()
+	return maps.Collect(s.all)
+}
+
+func (v shardedView[T, V]) Size() int {
+	return v.set.size()
+}
+
+func (v shardedView[T, V]) Contains(element T) bool {
+	return v.set.contains(element)
+}
+
+func (v shardedView[T, V]) All() iter.Seq2[T, V] {
+	return v.set.all
+}
+
+// randIndex returns a random index in the range of the elements.
+// If the set is empty, -1 is returned.
+// This method is not part of the public API.
+func (s *shardedSet[T, V]) randIndex() int64 {
+	size := s.Size()
+	if size == 0 {
+		return -1
+	}
+	n, _ := rand.Int(rand.Reader, big.NewInt(int64(size)))
+	return n.Int64()
+}
+
+// GetElements returns a copy of all elements of the set as a map.
+// Contrary to the non-concurrent set, changes to the returned map do not interfere with this set.
+func (s *shardedSet[T, V]) GetElements() map[T]V {
+	return s.snapshot()
+}
+
+// AddWithValue adds an element with an associated value to the set.
+// Only the shard responsible for the element is locked.
+func (s *shardedSet[T, V]) AddWithValue(element T, value V) {
+	sh := s.shardFor(element)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.elements[element] = value
+}
+
+// AddWithoutValue adds an element (without an associated value) to the set.
+// Only the shard responsible for the element is locked.
+func (s *shardedSet[T, V]) AddWithoutValue(element T) {
+	var empty V
+	s.AddWithValue(element, empty)
+}
+
+// Remove removes an element from the set.
+// Only the shard responsible for the element is locked.
+func (s *shardedSet[T, V]) Remove(element T) {
+	sh := s.shardFor(element)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	delete(sh.elements, element)
+}
+
+// AddAll adds all elements (including the value) from otherSet to this set.
+// If otherSet is nil, nothing happens.
+// If an element already exists in this set, the value is overwritten with the value from otherSet.
+// The otherSet remains unchanged.
+func (s *shardedSet[T, V]) AddAll(otherSet Set[T, V]) {
+	other, unlock := s.lockWith(otherSet, true)
+	defer unlock()
+	if other == nil {
+		return
+	}
+	for elem, value := range other.All() {
+		s.put(elem, value)
+	}
+}
+
+// RemoveAll removes all elements from otherSet from this set.
+// If otherSet is nil, nothing happens.
+// The otherSet remains unchanged.
+func (s *shardedSet[T, V]) RemoveAll(otherSet Set[T, V]) {
+	if otherSet == Set[T, V](s) {
+		s.Clear()
+		return
+	}
+	other, unlock := s.lockWith(otherSet, true)
+	defer unlock()
+	if other == nil {
+		return
+	}
+	for elem := range other.All() {
+		delete(s.shardFor(elem).elements, elem)
+	}
+}
+
+// Clear removes all elements from the set.
+func (s *shardedSet[T, V]) Clear() {
+	unlock := s.lockAll(true)
+	defer unlock()
+	for _, sh := range s.shards {
+		clear(sh.elements)
+	}
+}
+
+// Size returns the number of elements in the set.
+// All shards are locked while counting, so the size is consistent.
+func (s *shardedSet[T, V]) Size() int {
+	unlock := s.lockAll(false)
+	defer unlock()
+	return s.size()
+}
+
+// List returns all elements (without values) of the set as a slice.
+// The returned slice is a copy, changes to that copy do not interfere with the original set.
+func (s *shardedSet[T, V]) List() []T {
+	unlock := s.lockAll(false)
+	defer unlock()
+	elements := make([]T, 0, s.size())
+	for elem := range s.all {
+		elements = append(elements, elem)
+	}
+	return elements
+}
+
+// All returns an iterator over all elements and their values of the set.
+// The iterator works on a consistent snapshot taken when the iteration starts, so the set may be modified while iterating.
+// The order of the elements is not defined.
+func (s *shardedSet[T, V]) All() iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		for elem, value := range s.snapshot() {
+			if !yield(elem, value) {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over all elements (without values) of the set.
+// The iterator works on a consistent snapshot taken when the iteration starts, so the set may be modified while iterating.
+// The order of the elements is not defined.
+func (s *shardedSet[T, V]) Elements() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem := range s.snapshot() {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of all elements of the set.
+// The iterator works on a consistent snapshot taken when the iteration starts, so the set may be modified while iterating.
+// The order of the values is not defined.
+func (s *shardedSet[T, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range s.snapshot() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Contains checks whether or not the given element exists in the set (ignoring the value).
+// Returns true if the element is in the set, false otherwise.
+// Only the shard responsible for the element is locked.
+func (s *shardedSet[T, V]) Contains(element T) bool {
+	sh := s.shardFor(element)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	_, exists := sh.elements[element]
+	return exists
+}
+
+// ContainsAny checks if the set contains at least one of the given elements (ignoring the values).
+// Returns true if at least one of the given elements is in the set, false otherwise.
+func (s *shardedSet[T, V]) ContainsAny(elements ...T) bool {
+	for _, elem := range elements {
+		if s.Contains(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equals checks if this set is equal to otherSet ignoring the values.
+// Returns true if both sets are of equal size and contain the same elements (ignoring the values), false otherwise.
+func (s *shardedSet[T, V]) Equals(otherSet Set[T, V]) bool {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	if other == nil || s.size() != other.Size() {
+		return false
+	}
+	for elem := range s.all {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset checks if this set is a subset of otherSet.
+// Returns true if all elements of this set are in otherSet, false otherwise.
+// If otherSet is nil, true is returned only if this set is empty.
+// The values are not considered when checking for subset.
+func (s *shardedSet[T, V]) IsSubset(otherSet Set[T, V]) bool {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	if other == nil {
+		return s.size() == 0
+	}
+	if s.size() > other.Size() {
+		return false
+	}
+	for elem := range s.all {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the set.
+// The elements are separated by commas.
+// The values are not included in the string representation.
+// The order of the elements is not defined.
+// If the set is empty, an empty string is returned.
+func (s *shardedSet[T, V]) String() string {
+	unlock := s.lockAll(false)
+	defer unlock()
+	strElems := make([]string, 0, s.size())
+	for elem := range s.all {
+		strElems = append(strElems, fmt.Sprintf("%v", elem))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// StringWithValues returns a string representation of the set including values.
+// Each element's value is given in braces after the element.
+// The order of the elements is not defined.
+// If the set is empty, an empty string is returned.
+func (s *shardedSet[T, V]) StringWithValues() string {
+	unlock := s.lockAll(false)
+	defer unlock()
+	strElems := make([]string, 0, s.size())
+	for elem, value := range s.all {
+		strElems = append(strElems, fmt.Sprintf("%v (%v)", elem, value))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// Copy returns a new sharded set containing all elements (including the values) of this set.
+func (s *shardedSet[T, V]) Copy() Set[T, V] {
+	unlock := s.lockAll(false)
+	defer unlock()
+	newSet := s.newEmptyLike()
+	for elem, value := range s.all {
+		newSet.put(elem, value)
+	}
+	return newSet
+}
+
+// Intersect returns a new sharded set containing only elements (including the values) that are in both, this set and otherSet.
+// If there are no common elements or otherSet is nil, a new empty set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *shardedSet[T, V]) Intersect(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	newSet := s.newEmptyLike()
+	if other == nil {
+		return newSet
+	}
+	for elem, value := range other.All() {
+		if s.contains(elem) {
+			newSet.put(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Unite returns a new sharded set containing all elements (including the values) of both, this set and otherSet.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *shardedSet[T, V]) Unite(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	newSet := s.newEmptyLike()
+	for elem, value := range s.all {
+		newSet.put(elem, value)
+	}
+	if other != nil {
+		for elem, value := range other.All() {
+			newSet.put(elem, value)
+		}
+	}
+	return newSet
+}
+
+// UniteDisjunctively returns a new sharded set containing all elements (including the values) that are in either this set or otherSet, but not in both (symmetric difference).
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *shardedSet[T, V]) UniteDisjunctively(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	newSet := s.newEmptyLike()
+	for elem, value := range s.all {
+		if other == nil || !other.Contains(elem) {
+			newSet.put(elem, value)
+		}
+	}
+	if other != nil {
+		for elem, value := range other.All() {
+			if !s.contains(elem) {
+				newSet.put(elem, value)
+			}
+		}
+	}
+	return newSet
+}
+
+// Subtract returns a new sharded set containing all elements (including the values) that are in this set but not in otherSet.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *shardedSet[T, V]) Subtract(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	newSet := s.newEmptyLike()
+	for elem, value := range s.all {
+		if other == nil || !other.Contains(elem) {
+			newSet.put(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Filter returns a new sharded set containing only elements (including the values) of this set for which the filter function returns true.
+// The filter function is applied to a snapshot of this set without holding any lock, so it may safely access this set.
+// If the filter function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *shardedSet[T, V]) Filter(filterFunc FilterFunc[T, V]) Set[T, V] {
+	newSet := s.newEmptyLike()
+	for elem, value := range s.snapshot() {
+		if filterFunc == nil || filterFunc(elem, value) {
+			newSet.put(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Map returns a new sharded set containing all elements (including the values) returned by the map function which is applied to each element of this set.
+// The map function is applied to a snapshot of this set without holding any lock, so it may safely access this set.
+// If the map function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *shardedSet[T, V]) Map(mapFunc MapFunc[T, V]) Set[T, V] {
+	newSet := s.newEmptyLike()
+	for elem, value := range s.snapshot() {
+		if mapFunc != nil {
+			elem, value = mapFunc(elem, value)
+		}
+		newSet.put(elem, value)
+	}
+	return newSet
+}
+
+// OneR returns one random element (and its value) from the set.
+// If the set is empty, an error is returned.
+// All shards are locked while choosing the element.
+func (s *shardedSet[T, V]) OneR() (T, V, error) {
+	unlock := s.lockAll(false)
+	defer unlock()
+	if size := s.size(); size != 0 {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(size)))
+		rndIndex := n.Int64()
+		var counter int64 = 0
+		for elem, value := range s.all {
+			if counter == rndIndex {
+				return elem, value, nil
+			}
+			counter++
+		}
+	}
+
+	var emptyT T
+	var emptyV V
+	return emptyT, emptyV, fmt.Errorf("cannot get a random element from set, set is empty")
+}
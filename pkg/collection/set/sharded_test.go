@@ -0,0 +1,268 @@
+package set
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedSetShouldBehaveLikeSet(t *testing.T) {
+	// Given
+	set1 := NewShardedWithValues[string, string](4, nil)
+	set1.AddWithValue("apple", "red")
+	set1.AddWithValue("banana", "yellow")
+	set1.AddWithValue("cherry", "dark red")
+
+	set2 := NewShardedWithValues[string, string](8, nil)
+	set2.AddWithValue("banana", "brownish")
+	set2.AddWithValue("mango", "green-orange")
+
+	// Expect
+	assert.Equal(t, 3, set1.Size())
+	assert.True(t, set1.Contains("apple"))
+	assert.False(t, set1.Contains("mango"))
+	assert.True(t, set1.ContainsAny("kiwi", "cherry"))
+	assert.ElementsMatch(t, []string{"apple", "banana", "cherry"}, set1.List())
+	assert.Equal(t, map[string]string{"apple": "red", "banana": "yellow", "cherry": "dark red"}, set1.GetElements())
+	assert.Equal(t, map[string]string{"banana": "brownish"}, set1.Intersect(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red", "banana": "brownish", "cherry": "dark red", "mango": "green-orange"}, set1.Unite(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red", "cherry": "dark red", "mango": "green-orange"}, set1.UniteDisjunctively(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red", "cherry": "dark red"}, set1.Subtract(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red"}, set1.Filter(func(elem string, value string) bool {
+		return value == "red"
+	}).GetElements())
+	assert.Equal(t, map[string]string{"APPLE": "red", "BANANA": "yellow", "CHERRY": "dark red"}, set1.Map(func(elem string, value string) (string, string) {
+		return map[string]string{"apple": "APPLE", "banana": "BANANA", "cherry": "CHERRY"}[elem], value
+	}).GetElements())
+	assert.False(t, set1.Equals(set2))
+	assert.True(t, set1.Equals(set1.Copy()))
+	assert.True(t, set1.Equals(set1))
+	assert.True(t, set1.Intersect(set2).IsSubset(set1))
+	assert.False(t, set1.IsSubset(nil))
+	assert.Equal(t, 0, set1.Intersect(nil).Size())
+
+	// and binary operations with other set implementations work in both directions
+	plainSet := NewWithValues[string, string]()
+	plainSet.AddWithValue("apple", "green")
+	assert.Equal(t, map[string]string{"apple": "green"}, set1.Intersect(plainSet).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red"}, plainSet.Intersect(set1).GetElements())
+
+	// When
+	elem, value, err := set1.OneR()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, set1.GetElements()[elem], value)
+
+	// When
+	set1.AddAll(set2)
+	// Then
+	assert.Equal(t, 4, set1.Size())
+	assert.Equal(t, "brownish", set1.GetElements()["banana"])
+
+	// When
+	set1.RemoveAll(set2)
+	set1.Remove("apple")
+	// Then
+	assert.Equal(t, []string{"cherry"}, set1.List())
+
+	// When
+	set1.RemoveAll(set1)
+	// Then
+	assert.Equal(t, 0, set1.Size())
+	_, _, err = set1.OneR()
+	assert.NotNil(t, err)
+}
+
+func TestShardedSetShouldUseDefaults(t *testing.T) {
+	// When
+	set := NewShardedWithoutValues[int](0, nil).(*shardedSet[int, InternalEmptyType])
+
+	// Then
+	assert.Equal(t, DefaultShardCount, len(set.shards))
+
+	// When
+	for i := range 1000 {
+		set.AddWithoutValue(i)
+	}
+	// Then the elements are spread over the shards
+	for _, sh := range set.shards {
+		assert.NotEmpty(t, sh.elements)
+	}
+	assert.Equal(t, 1000, set.Size())
+}
+
+func TestShardedSetShouldBeSafeForConcurrentUse(t *testing.T) {
+	// Given
+	set1 := NewShardedWithoutValues[int](16, nil)
+	set2 := NewShardedWithoutValues[int](16, nil)
+
+	// When
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				set1.AddWithoutValue(g*1000 + i)
+				set2.AddWithoutValue(i)
+				set1.Contains(i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				// opposite directions must not deadlock
+				set1.Intersect(set2)
+				set2.Subtract(set1)
+				set1.Size()
+				set2.List()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	assert.Equal(t, 8000, set1.Size())
+	assert.Equal(t, 1000, set2.Size())
+	assert.True(t, set2.IsSubset(set1))
+}
+
+func TestDefaultHashFuncShouldBeDeterministic(t *testing.T) {
+	// Given
+	type myString string
+	type point struct{ x, y int }
+
+	// Expect
+	assert.Equal(t, DefaultHashFunc[string]()("apple"), DefaultHashFunc[myString]()("apple"))
+	assert.NotEqual(t, DefaultHashFunc[string]()("apple"), DefaultHashFunc[string]()("banana"))
+	assert.Equal(t, DefaultHashFunc[int]()(42), DefaultHashFunc[int64]()(42))
+	assert.Equal(t, DefaultHashFunc[float64]()(0.0), DefaultHashFunc[float64]()(-1*0.0))
+	assert.Equal(t, DefaultHashFunc[point]()(point{1, 2}), DefaultHashFunc[point]()(point{1, 2}))
+	assert.NotEqual(t, DefaultHashFunc[point]()(point{1, 2}), DefaultHashFunc[point]()(point{2, 1}))
+	assert.NotEqual(t, DefaultHashFunc[bool]()(true), DefaultHashFunc[bool]()(false))
+
+	// and strings are hashed with FNV-1a plus an avalanche step
+	fnvHash := fnv.New64a()
+	fnvHash.Write([]byte("apple"))
+	assert.Equal(t, mix64(fnvHash.Sum64()), HashString("apple"))
+}
+
+func TestDefaultHashFuncShouldHashEqualCompositeElementsEqually(t *testing.T) {
+	// Given
+	type measurement struct {
+		X     float64
+		Label any
+		Tags  [2]string
+	}
+	negativeZero := math.Copysign(0, -1)
+	a := measurement{X: 0, Label: 1, Tags: [2]string{"a", "b"}}
+	b := measurement{X: negativeZero, Label: 1, Tags: [2]string{"a", "b"}}
+	hash := DefaultHashFunc[measurement]()
+
+	// Expect
+	assert.True(t, a == b)
+	assert.Equal(t, hash(a), hash(b))
+	assert.NotEqual(t, hash(a), hash(measurement{X: 0, Label: int64(1), Tags: [2]string{"a", "b"}}))
+	assert.NotEqual(t, hash(a), hash(measurement{X: 0, Label: 1, Tags: [2]string{"b", "a"}}))
+	assert.Equal(t, DefaultHashFunc[any]()(nil), DefaultHashFunc[any]()(nil))
+	assert.Equal(t, DefaultHashFunc[any]()(struct{ X float64 }{0}), DefaultHashFunc[any]()(struct{ X float64 }{negativeZero}))
+	pointer := &measurement{}
+	assert.Equal(t, DefaultHashFunc[*measurement]()(pointer), DefaultHashFunc[*measurement]()(pointer))
+}
+
+func TestShardedSetShouldFindEqualStructElements(t *testing.T) {
+	// Given
+	set := NewShardedWithoutValues[struct{ X float64 }](8, nil)
+	set.AddWithoutValue(struct{ X float64 }{0})
+
+	// Expect
+	assert.True(t, set.Contains(struct{ X float64 }{math.Copysign(0, -1)}))
+}
+
+func benchmarkParallelContainsAndAdd(b *testing.B, contains func(int) bool, add func(int)) {
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				add(i % 100_000)
+			} else {
+				contains(i % 100_000)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkPlainSetWithMutexParallel(b *testing.B) {
+	set := NewWithoutValues[int]()
+	var mu sync.RWMutex
+	benchmarkParallelContainsAndAdd(b, func(i int) bool {
+		mu.RLock()
+		defer mu.RUnlock()
+		return set.Contains(i)
+	}, func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		set.AddWithoutValue(i)
+	})
+}
+
+func BenchmarkSyncSetParallel(b *testing.B) {
+	set := NewSyncWithoutValues[int]()
+	benchmarkParallelContainsAndAdd(b, set.Contains, set.AddWithoutValue)
+}
+
+func BenchmarkShardedSetParallel(b *testing.B) {
+	for _, shardCount := range []int{4, 32, 128} {
+		b.Run(strconv.Itoa(shardCount), func(b *testing.B) {
+			set := NewShardedWithoutValues[int](shardCount, nil)
+			benchmarkParallelContainsAndAdd(b, set.Contains, set.AddWithoutValue)
+		})
+	}
+}
+
+func BenchmarkPlainSetSequential(b *testing.B) {
+	set := NewWithoutValues[int]()
+	for i := 0; i < b.N; i++ {
+		set.AddWithoutValue(i % 100_000)
+		set.Contains(i % 100_000)
+	}
+}
+
+func BenchmarkShardedSetSequential(b *testing.B) {
+	set := NewShardedWithoutValues[int](DefaultShardCount, nil)
+	for i := 0; i < b.N; i++ {
+		set.AddWithoutValue(i % 100_000)
+		set.Contains(i % 100_000)
+	}
+}
+
+func BenchmarkPlainSetIntersect(b *testing.B) {
+	set1 := NewWithoutValues[int]()
+	set2 := NewWithoutValues[int]()
+	for i := range 10_000 {
+		set1.AddWithoutValue(i)
+		set2.AddWithoutValue(i * 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set1.Intersect(set2)
+	}
+}
+
+func BenchmarkShardedSetIntersect(b *testing.B) {
+	set1 := NewShardedWithoutValues[int](DefaultShardCount, nil)
+	set2 := NewShardedWithoutValues[int](DefaultShardCount, nil)
+	for i := range 10_000 {
+		set1.AddWithoutValue(i)
+		set2.AddWithoutValue(i * 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set1.Intersect(set2)
+	}
+}
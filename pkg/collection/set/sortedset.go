@@ -0,0 +1,792 @@
+package set
+
+import (
+	"cmp"
+	"crypto/rand"
+	"fmt"
+	"iter"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/order"
+)
+
+// CompareFunc compares two elements and returns a negative number if a < b, zero if a == b and a positive number if a > b.
+// It has to define a strict weak ordering which is consistent with ==, i.e. it must return zero only for equal elements.
+type CompareFunc[T any] func(a, b T) int
+
+// SortedSet is a Set that keeps its elements sorted.
+// Iterating, listing and printing a SortedSet yields the elements in ascending order.
+// Adding, removing and checking for elements take logarithmic time.
+// Additionally, a SortedSet provides range queries and order statistics.
+type SortedSet[T comparable, V any] interface {
+	Set[T, V]
+
+	Min() (T, V, error)
+	Max() (T, V, error)
+	Floor(T) (T, V, error)
+	Ceiling(T) (T, V, error)
+	Range(T, T) iter.Seq2[T, V]
+	Ascend() iter.Seq2[T, V]
+	Descend() iter.Seq2[T, V]
+	Rank(T) int
+	Select(int) (T, V, error)
+}
+
+type sortedNode[T comparable, V any] struct {
+	element     T
+	value       V
+	left, right *sortedNode[T, V]
+	height      int
+	size        int
+}
+
+type sortedSet[T comparable, V any] struct {
+	root    *sortedNode[T, V]
+	compare CompareFunc[T]
+	// natural is true if the elements are ordered by cmp.Compare, so two such sets are known to share the same order.
+	natural bool
+}
+
+// NewSortedWithValues creates a new, empty, sorted set that can contain elements of type T having values of type V (like a map).
+// The elements are ordered by their natural order (cmp.Compare).
+func NewSortedWithValues[T cmp.Ordered, V any]() SortedSet[T, V] {
+	return &sortedSet[T, V]{compare: cmp.Compare[T], natural: true}
+}
+
+// NewSortedWithoutValues creates a new, empty, sorted set that can contain elements of type T (like a set of labels).
+// The elements are ordered by their natural order (cmp.Compare).
+func NewSortedWithoutValues[T cmp.Ordered]() SortedSet[T, InternalEmptyType] {
+	return &sortedSet[T, InternalEmptyType]{compare: cmp.Compare[T], natural: true}
+}
+
+// NewSortedWithValuesFunc creates a new, empty, sorted set that can contain elements of type T having values of type V (like a map).
+// The elements are ordered by the given compare function. If compare is nil, the natural order of T is used
+// if T is an ordered type (integers, floats and strings, including types based on them); otherwise it panics.
+func NewSortedWithValuesFunc[T comparable, V any](compare CompareFunc[T]) SortedSet[T, V] {
+	compare, natural := compareOrNatural(compare)
+	return &sortedSet[T, V]{compare: compare, natural: natural}
+}
+
+// NewSortedWithoutValuesFunc creates a new, empty, sorted set that can contain elements of type T (like a set of labels).
+// The elements are ordered by the given compare function. If compare is nil, the natural order of T is used
+// if T is an ordered type (integers, floats and strings, including types based on them); otherwise it panics.
+func NewSortedWithoutValuesFunc[T comparable](compare CompareFunc[T]) SortedSet[T, InternalEmptyType] {
+	compare, natural := compareOrNatural(compare)
+	return &sortedSet[T, InternalEmptyType]{compare: compare, natural: natural}
+}
+
+// compareOrNatural returns the given compare function, or the natural order of T and true if compare is nil.
+// It panics if compare is nil and T is not an ordered type.
+func compareOrNatural[T comparable](compare CompareFunc[T]) (CompareFunc[T], bool) {
+	if compare != nil {
+		return compare, false
+	}
+	if natural := order.Compare[T](); natural != nil {
+		return natural, true
+	}
+	panic(fmt.Sprintf("cannot create sorted set without compare function, %v has no natural order", reflect.TypeFor[T]()))
+}
+
+// newEmptyLike creates a new, empty sorted set with the same order as this set.
+func (s *sortedSet[T, V]) newEmptyLike() *sortedSet[T, V] {
+	return &sortedSet[T, V]{compare: s.compare, natural: s.natural}
+}
+
+// sameOrder checks whether otherSet is a sorted set known to have the same order as this set.
+func (s *sortedSet[T, V]) sameOrder(otherSet Set[T, V]) (*sortedSet[T, V], bool) {
+	other, ok := otherSet.(*sortedSet[T, V])
+	if !ok || other == nil {
+		return nil, false
+	}
+	return other, other == s || (s.natural && other.natural)
+}
+
+func height[T comparable, V any](node *sortedNode[T, V]) int {
+	if node == nil {
+		return 0
+	}
+	return node.height
+}
+
+func size[T comparable, V any](node *sortedNode[T, V]) int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+// update recalculates height and size of the node from its children.
+func (n *sortedNode[T, V]) update() {
+	n.height = max(height(n.left), height(n.right)) + 1
+	n.size = size(n.left) + size(n.right) + 1
+}
+
+func rotateRight[T comparable, V any](node *sortedNode[T, V]) *sortedNode[T, V] {
+	left := node.left
+	node.left = left.right
+	left.right = node
+	node.update()
+	left.update()
+	return left
+}
+
+func rotateLeft[T comparable, V any](node *sortedNode[T, V]) *sortedNode[T, V] {
+	right := node.right
+	node.right = right.left
+	right.left = node
+	node.update()
+	right.update()
+	return right
+}
+
+// rebalance restores the AVL property of the node whose children differ in height by at most two.
+func rebalance[T comparable, V any](node *sortedNode[T, V]) *sortedNode[T, V] {
+	node.update()
+	balance := height(node.left) - height(node.right)
+	if balance > 1 {
+		if height(node.left.left) < height(node.left.right) {
+			node.left = rotateLeft(node.left)
+		}
+		return rotateRight(node)
+	}
+	if balance < -1 {
+		if height(node.right.right) < height(node.right.left) {
+			node.right = rotateRight(node.right)
+		}
+		return rotateLeft(node)
+	}
+	return node
+}
+
+func (s *sortedSet[T, V]) insert(node *sortedNode[T, V], element T, value V) *sortedNode[T, V] {
+	if node == nil {
+		return &sortedNode[T, V]{element: element, value: value, height: 1, size: 1}
+	}
+	switch c := s.compare(element, node.element); {
+	case c < 0:
+		node.left = s.insert(node.left, element, value)
+	case c > 0:
+		node.right = s.insert(node.right, element, value)
+	default:
+		node.value = value
+		return node
+	}
+	return rebalance(node)
+}
+
+// removeMin removes the smallest node of the subtree and returns the new subtree root as well as the removed node.
+func removeMin[T comparable, V any](node *sortedNode[T, V]) (*sortedNode[T, V], *sortedNode[T, V]) {
+	if node.left == nil {
+		return node.right, node
+	}
+	var minNode *sortedNode[T, V]
+	node.left, minNode = removeMin(node.left)
+	return rebalance(node), minNode
+}
+
+func (s *sortedSet[T, V]) delete(node *sortedNode[T, V], element T) *sortedNode[T, V] {
+	if node == nil {
+		return nil
+	}
+	switch c := s.compare(element, node.element); {
+	case c < 0:
+		node.left = s.delete(node.left, element)
+	case c > 0:
+		node.right = s.delete(node.right, element)
+	default:
+		if node.left == nil {
+			return node.right
+		}
+		if node.right == nil {
+			return node.left
+		}
+		var successor *sortedNode[T, V]
+		node.right, successor = removeMin(node.right)
+		successor.left = node.left
+		successor.right = node.right
+		node = successor
+	}
+	return rebalance(node)
+}
+
+// find returns the node of the given element or nil if the element is not in the set.
+func (s *sortedSet[T, V]) find(element T) *sortedNode[T, V] {
+	node := s.root
+	for node != nil {
+		switch c := s.compare(element, node.element); {
+		case c < 0:
+			node = node.left
+		case c > 0:
+			node = node.right
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+// buildBalanced builds a perfectly balanced tree from nodes sorted in ascending order, reusing the given nodes.
+func buildBalanced[T comparable, V any](nodes []*sortedNode[T, V]) *sortedNode[T, V] {
+	if len(nodes) == 0 {
+		return nil
+	}
+	mid := len(nodes) / 2
+	node := nodes[mid]
+	node.left = buildBalanced(nodes[:mid])
+	node.right = buildBalanced(nodes[mid+1:])
+	node.update()
+	return node
+}
+
+// newFromSorted creates a new set with the same order as this set from element-value pairs given in ascending order in linear time.
+func (s *sortedSet[T, V]) newFromSorted(seq iter.Seq2[T, V]) *sortedSet[T, V] {
+	nodes := make([]*sortedNode[T, V], 0)
+	for elem, value := range seq {
+		nodes = append(nodes, &sortedNode[T, V]{element: elem, value: value})
+	}
+	newSet := s.newEmptyLike()
+	newSet.root = buildBalanced(nodes)
+	return newSet
+}
+
+// ascendFrom iterates in ascending order over all elements of the subtree being greater than or equal to lo
+// (or over all elements if lo is nil) and less than or equal to hi (or without upper limit if hi is nil).
+func (s *sortedSet[T, V]) ascendFrom(lo, hi *T) iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		stack := make([]*sortedNode[T, V], 0, height(s.root))
+		node := s.root
+		for node != nil || len(stack) > 0 {
+			for node != nil {
+				if lo != nil && s.compare(node.element, *lo) < 0 {
+					node = node.right
+					continue
+				}
+				stack = append(stack, node)
+				node = node.left
+			}
+			if len(stack) == 0 {
+				return
+			}
+			node = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if hi != nil && s.compare(node.element, *hi) > 0 {
+				return
+			}
+			if !yield(node.element, node.value) {
+				return
+			}
+			node = node.right
+		}
+	}
+}
+
+// randIndex returns a random index in the range of the elements.
+// If the set is empty, -1 is returned.
+// This method is not part of the public API.
+func (s *sortedSet[T, V]) randIndex() int64 {
+	if s.Size() == 0 {
+		return -1
+	}
+	n, _ := rand.Int(rand.Reader, big.NewInt(int64(s.Size())))
+	return n.Int64()
+}
+
+// GetElements returns all elements (including the values) of the set as a new map.
+// Since a map has no order, the returned map is a copy, changes to that copy do not interfere with this set.
+func (s *sortedSet[T, V]) GetElements() map[T]V {
+	elements := make(map[T]V, s.Size())
+	for elem, value := range s.All() {
+		elements[elem] = value
+	}
+	return elements
+}
+
+// AddWithValue adds an element with an associated value to the set.
+func (s *sortedSet[T, V]) AddWithValue(element T, value V) {
+	s.root = s.insert(s.root, element, value)
+}
+
+// AddWithoutValue adds an element (without an associated value) to the set.
+func (s *sortedSet[T, V]) AddWithoutValue(element T) {
+	var empty V
+	s.AddWithValue(element, empty)
+}
+
+// Remove removes an element from the set.
+func (s *sortedSet[T, V]) Remove(element T) {
+	s.root = s.delete(s.root, element)
+}
+
+// AddAll adds all elements (including the value) from otherSet to this set.
+// If otherSet is nil, nothing happens.
+// If an element already exists in this set, the value is overwritten with the value from otherSet.
+// The otherSet remains unchanged.
+func (s *sortedSet[T, V]) AddAll(otherSet Set[T, V]) {
+	if otherSet == nil {
+		return
+	}
+	if _, ok := s.sameOrder(otherSet); ok {
+		s.root = s.Unite(otherSet).(*sortedSet[T, V]).root
+		return
+	}
+	for elem, value := range otherSet.All() {
+		s.AddWithValue(elem, value)
+	}
+}
+
+// RemoveAll removes all elements from otherSet from this set.
+// If otherSet is nil, nothing happens.
+// The otherSet remains unchanged.
+func (s *sortedSet[T, V]) RemoveAll(otherSet Set[T, V]) {
+	if otherSet == nil {
+		return
+	}
+	if _, ok := s.sameOrder(otherSet); ok {
+		s.root = s.Subtract(otherSet).(*sortedSet[T, V]).root
+		return
+	}
+	for elem := range otherSet.Elements() {
+		s.Remove(elem)
+	}
+}
+
+// Clear removes all elements from the set.
+func (s *sortedSet[T, V]) Clear() {
+	s.root = nil
+}
+
+// Size returns the number of elements in the set.
+func (s *sortedSet[T, V]) Size() int {
+	return size(s.root)
+}
+
+// List returns all elements (without values) of the set as a slice in ascending order.
+// The returned slice is a copy, changes to that copy do not interfere with the original set.
+func (s *sortedSet[T, V]) List() []T {
+	elements := make([]T, 0, s.Size())
+	for elem := range s.Elements() {
+		elements = append(elements, elem)
+	}
+	return elements
+}
+
+// All returns an iterator over all elements and their values of the set in ascending order.
+// The set must not be modified while iterating.
+func (s *sortedSet[T, V]) All() iter.Seq2[T, V] {
+	return s.ascendFrom(nil, nil)
+}
+
+// Ascend returns an iterator over all elements and their values of the set in ascending order.
+// It is the same as All.
+// The set must not be modified while iterating.
+func (s *sortedSet[T, V]) Ascend() iter.Seq2[T, V] {
+	return s.All()
+}
+
+// Descend returns an iterator over all elements and their values of the set in descending order.
+// The set must not be modified while iterating.
+func (s *sortedSet[T, V]) Descend() iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		stack := make([]*sortedNode[T, V], 0, height(s.root))
+		node := s.root
+		for node != nil || len(stack) > 0 {
+			for node != nil {
+				stack = append(stack, node)
+				node = node.right
+			}
+			node = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(node.element, node.value) {
+				return
+			}
+			node = node.left
+		}
+	}
+}
+
+// Range returns an iterator over all elements and their values being greater than or equal to lo
+// and less than or equal to hi in ascending order.
+// If lo is greater than hi, the iterator yields nothing.
+// The set must not be modified while iterating.
+func (s *sortedSet[T, V]) Range(lo, hi T) iter.Seq2[T, V] {
+	return s.ascendFrom(&lo, &hi)
+}
+
+// Elements returns an iterator over all elements (without values) of the set in ascending order.
+// The set must not be modified while iterating.
+func (s *sortedSet[T, V]) Elements() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem := range s.All() {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of all elements of the set in ascending order of the elements.
+// The set must not be modified while iterating.
+func (s *sortedSet[T, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range s.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Contains checks whether or not the given element exists in the set (ignoring the value).
+// Returns true if the element is in the set, false otherwise.
+func (s *sortedSet[T, V]) Contains(element T) bool {
+	return s.find(element) != nil
+}
+
+// ContainsAny checks if the set contains at least one of the given elements (ignoring the values).
+// Returns true if at least one of the given elements is in the set, false otherwise.
+func (s *sortedSet[T, V]) ContainsAny(elements ...T) bool {
+	for _, elem := range elements {
+		if s.Contains(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equals checks if this set is equal to otherSet ignoring the values.
+// Returns true if both sets are of equal size and contain the same elements (ignoring the values), false otherwise.
+func (s *sortedSet[T, V]) Equals(otherSet Set[T, V]) bool {
+	if s.Size() != otherSet.Size() {
+		return false
+	}
+	return s.IsSubset(otherSet)
+}
+
+// IsSubset checks if this set is a subset of otherSet.
+// Returns true if all elements of this set are in otherSet, false otherwise.
+// If otherSet is nil, true is returned only if this set is empty.
+// The values are not considered when checking for subset.
+func (s *sortedSet[T, V]) IsSubset(otherSet Set[T, V]) bool {
+	if otherSet == nil {
+		return s.Size() == 0
+	}
+	if s.Size() > otherSet.Size() {
+		return false
+	}
+	if _, ok := s.sameOrder(otherSet); ok {
+		return s.Subtract(otherSet).Size() == 0
+	}
+	for elem := range s.Elements() {
+		if !otherSet.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the set.
+// The elements are separated by commas and given in ascending order.
+// The values are not included in the string representation.
+// If the set is empty, an empty string is returned.
+func (s *sortedSet[T, V]) String() string {
+	strElems := make([]string, 0, s.Size())
+	for elem := range s.Elements() {
+		strElems = append(strElems, fmt.Sprintf("%v", elem))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// StringWithValues returns a string representation of the set including values.
+// The elements are separated by commas and given in ascending order.
+// Each element's value is given in braces after the element.
+// If the set is empty, an empty string is returned.
+func (s *sortedSet[T, V]) StringWithValues() string {
+	strElems := make([]string, 0, s.Size())
+	for elem, value := range s.All() {
+		strElems = append(strElems, fmt.Sprintf("%v (%v)", elem, value))
+	}
+	return strings.Join(strElems, ", ")
+}
+
+// Copy returns a new sorted set with the same order containing all elements (including the values) of this set.
+func (s *sortedSet[T, V]) Copy() Set[T, V] {
+	return s.newFromSorted(s.All())
+}
+
+// merge iterates in ascending order over the elements of this set and otherSet (which must have the same order).
+// For each element, inThis and inOther tell in which of the sets it is contained.
+// If the element is in both sets, the value of otherSet is passed.
+func (s *sortedSet[T, V]) merge(other *sortedSet[T, V], yield func(elem T, value V, inThis, inOther bool)) {
+	nextThis, stopThis := iter.Pull2(s.All())
+	defer stopThis()
+	nextOther, stopOther := iter.Pull2(other.All())
+	defer stopOther()
+
+	elemThis, valueThis, okThis := nextThis()
+	elemOther, valueOther, okOther := nextOther()
+	for okThis || okOther {
+		switch {
+		case !okOther || (okThis && s.compare(elemThis, elemOther) < 0):
+			yield(elemThis, valueThis, true, false)
+			elemThis, valueThis, okThis = nextThis()
+		case !okThis || s.compare(elemThis, elemOther) > 0:
+			yield(elemOther, valueOther, false, true)
+			elemOther, valueOther, okOther = nextOther()
+		default:
+			yield(elemOther, valueOther, true, true)
+			elemThis, valueThis, okThis = nextThis()
+			elemOther, valueOther, okOther = nextOther()
+		}
+	}
+}
+
+// mergeInto creates a new set from merging this set and otherSet, keeping the elements accepted by the keep function.
+func (s *sortedSet[T, V]) mergeInto(other *sortedSet[T, V], keep func(inThis, inOther bool) bool) *sortedSet[T, V] {
+	return s.newFromSorted(func(yield func(T, V) bool) {
+		s.merge(other, func(elem T, value V, inThis, inOther bool) {
+			if keep(inThis, inOther) {
+				yield(elem, value)
+			}
+		})
+	})
+}
+
+// Intersect returns a new sorted set containing only elements (including the values) that are in both, this set and otherSet.
+// If otherSet is a sorted set having the same order, the sets are merged in linear time.
+// If there are no common elements or otherSet is nil, a new empty set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *sortedSet[T, V]) Intersect(otherSet Set[T, V]) Set[T, V] {
+	if otherSet == nil {
+		return s.newEmptyLike()
+	}
+	if other, ok := s.sameOrder(otherSet); ok {
+		return s.mergeInto(other, func(inThis, inOther bool) bool { return inThis && inOther })
+	}
+	newSet := s.newEmptyLike()
+	for elem, value := range otherSet.All() {
+		if s.Contains(elem) {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	return newSet
+}
+
+// Unite returns a new sorted set containing all elements (including the values) of both, this set and otherSet.
+// If otherSet is a sorted set having the same order, the sets are merged in linear time.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *sortedSet[T, V]) Unite(otherSet Set[T, V]) Set[T, V] {
+	if other, ok := s.sameOrder(otherSet); ok {
+		return s.mergeInto(other, func(inThis, inOther bool) bool { return true })
+	}
+	newSet := s.newFromSorted(s.All())
+	if otherSet != nil {
+		for elem, value := range otherSet.All() {
+			newSet.AddWithValue(elem, value)
+		}
+	}
+	return newSet
+}
+
+// UniteDisjunctively returns a new sorted set containing all elements (including the values) that are in either this set or otherSet, but not in both (symmetric difference).
+// If otherSet is a sorted set having the same order, the sets are merged in linear time.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *sortedSet[T, V]) UniteDisjunctively(otherSet Set[T, V]) Set[T, V] {
+	if other, ok := s.sameOrder(otherSet); ok {
+		return s.mergeInto(other, func(inThis, inOther bool) bool { return inThis != inOther })
+	}
+	newSet := s.Subtract(otherSet).(*sortedSet[T, V])
+	if otherSet != nil {
+		for elem, value := range otherSet.All() {
+			if !s.Contains(elem) {
+				newSet.AddWithValue(elem, value)
+			}
+		}
+	}
+	return newSet
+}
+
+// Subtract returns a new sorted set containing all elements (including the values) that are in this set but not in otherSet.
+// If otherSet is a sorted set having the same order, the sets are merged in linear time.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *sortedSet[T, V]) Subtract(otherSet Set[T, V]) Set[T, V] {
+	if other, ok := s.sameOrder(otherSet); ok {
+		return s.mergeInto(other, func(inThis, inOther bool) bool { return inThis && !inOther })
+	}
+	return s.Filter(func(elem T, value V) bool {
+		return otherSet == nil || !otherSet.Contains(elem)
+	})
+}
+
+// Filter returns a new sorted set containing only elements (including the values) of this set for which the filter function returns true.
+// If the filter function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *sortedSet[T, V]) Filter(filterFunc FilterFunc[T, V]) Set[T, V] {
+	if filterFunc == nil {
+		return s.Copy()
+	}
+	return s.newFromSorted(func(yield func(T, V) bool) {
+		for elem, value := range s.All() {
+			if filterFunc(elem, value) && !yield(elem, value) {
+				return
+			}
+		}
+	})
+}
+
+// Map returns a new sorted set with the same order containing all elements (including the values) returned by the map function which is applied to each element of this set.
+// If the map function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *sortedSet[T, V]) Map(mapFunc MapFunc[T, V]) Set[T, V] {
+	if mapFunc == nil {
+		return s.Copy()
+	}
+	newSet := s.newEmptyLike()
+	for elem, value := range s.All() {
+		newElem, newValue := mapFunc(elem, value)
+		newSet.AddWithValue(newElem, newValue)
+	}
+	return newSet
+}
+
+// OneR returns one random element (and its value) from the set.
+// If the set is empty, an error is returned.
+func (s *sortedSet[T, V]) OneR() (T, V, error) {
+	if s.Size() != 0 {
+		return s.Select(int(s.randIndex()))
+	}
+
+	var emptyT T
+	var emptyV V
+	return emptyT, emptyV, fmt.Errorf("cannot get a random element from set, set is empty")
+}
+
+// Min returns the smallest element of the set and its value.
+// If the set is empty, an error is returned.
+func (s *sortedSet[T, V]) Min() (T, V, error) {
+	if s.root == nil {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot get smallest element from set, set is empty")
+	}
+	node := s.root
+	for node.left != nil {
+		node = node.left
+	}
+	return node.element, node.value, nil
+}
+
+// Max returns the largest element of the set and its value.
+// If the set is empty, an error is returned.
+func (s *sortedSet[T, V]) Max() (T, V, error) {
+	if s.root == nil {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot get largest element from set, set is empty")
+	}
+	node := s.root
+	for node.right != nil {
+		node = node.right
+	}
+	return node.element, node.value, nil
+}
+
+// Floor returns the largest element of the set being less than or equal to the given element, together with its value.
+// If there is no such element, an error is returned.
+func (s *sortedSet[T, V]) Floor(element T) (T, V, error) {
+	var floor *sortedNode[T, V]
+	node := s.root
+	for node != nil {
+		c := s.compare(element, node.element)
+		if c == 0 {
+			return node.element, node.value, nil
+		}
+		if c < 0 {
+			node = node.left
+		} else {
+			floor = node
+			node = node.right
+		}
+	}
+	if floor == nil {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot get floor of %v from set, there is no element less than or equal to it", element)
+	}
+	return floor.element, floor.value, nil
+}
+
+// Ceiling returns the smallest element of the set being greater than or equal to the given element, together with its value.
+// If there is no such element, an error is returned.
+func (s *sortedSet[T, V]) Ceiling(element T) (T, V, error) {
+	var ceiling *sortedNode[T, V]
+	node := s.root
+	for node != nil {
+		c := s.compare(element, node.element)
+		if c == 0 {
+			return node.element, node.value, nil
+		}
+		if c > 0 {
+			node = node.right
+		} else {
+			ceiling = node
+			node = node.left
+		}
+	}
+	if ceiling == nil {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot get ceiling of %v from set, there is no element greater than or equal to it", element)
+	}
+	return ceiling.element, ceiling.value, nil
+}
+
+// Rank returns the number of elements of the set being less than the given element.
+// If the element is in the set, this is its zero-based index in ascending order.
+func (s *sortedSet[T, V]) Rank(element T) int {
+	rank := 0
+	node := s.root
+	for node != nil {
+		c := s.compare(element, node.element)
+		if c <= 0 {
+			if c == 0 {
+				return rank + size(node.left)
+			}
+			node = node.left
+		} else {
+			rank += size(node.left) + 1
+			node = node.right
+		}
+	}
+	return rank
+}
+
+// Select returns the element (and its value) with the given zero-based index in ascending order,
+// i.e. Select(0) returns the smallest element.
+// If the index is out of range, an error is returned.
+func (s *sortedSet[T, V]) Select(index int) (T, V, error) {
+	if index < 0 || index >= s.Size() {
+		var emptyT T
+		var emptyV V
+		return emptyT, emptyV, fmt.Errorf("cannot select element %d from set, index out of range [0, %d)", index, s.Size())
+	}
+	node := s.root
+	for {
+		leftSize := size(node.left)
+		switch {
+		case index < leftSize:
+			node = node.left
+		case index > leftSize:
+			index -= leftSize + 1
+			node = node.right
+		default:
+			return node.element, node.value, nil
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package set
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newNumberSortedSet(elements ...int) SortedSet[int, string] {
+	set := NewSortedWithValues[int, string]()
+	for _, elem := range elements {
+		set.AddWithValue(elem, strings.Repeat("*", elem))
+	}
+	return set
+}
+
+// checkAVL checks the AVL invariants of the subtree and returns its height and size.
+func checkAVL[T comparable, V any](t *testing.T, node *sortedNode[T, V]) (int, int) {
+	if node == nil {
+		return 0, 0
+	}
+	leftHeight, leftSize := checkAVL(t, node.left)
+	rightHeight, rightSize := checkAVL(t, node.right)
+	assert.LessOrEqual(t, leftHeight-rightHeight, 1)
+	assert.GreaterOrEqual(t, leftHeight-rightHeight, -1)
+	assert.Equal(t, max(leftHeight, rightHeight)+1, node.height)
+	assert.Equal(t, leftSize+rightSize+1, node.size)
+	return node.height, node.size
+}
+
+func TestSortedSetShouldKeepElementsSorted(t *testing.T) {
+	// Given
+	set := newNumberSortedSet(5, 3, 8, 1, 4, 9, 2)
+
+	// Expect
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 8, 9}, set.List())
+	assert.Equal(t, "1, 2, 3, 4, 5, 8, 9", set.String())
+	assert.Equal(t, "1 (*), 2 (**), 3 (***)", set.Filter(func(elem int, value string) bool {
+		return elem <= 3
+	}).StringWithValues())
+	descending := []int{}
+	for elem := range set.Descend() {
+		descending = append(descending, elem)
+	}
+	assert.Equal(t, []int{9, 8, 5, 4, 3, 2, 1}, descending)
+
+	// When
+	set.Remove(5)
+	set.Remove(42)
+	set.AddWithoutValue(6)
+	// Then
+	assert.Equal(t, []int{1, 2, 3, 4, 6, 8, 9}, set.List())
+}
+
+func TestSortedSetShouldStayBalanced(t *testing.T) {
+	// Given
+	set := NewSortedWithoutValues[int]().(*sortedSet[int, InternalEmptyType])
+	reference := NewWithoutValues[int]()
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	// When adding and removing random elements
+	for range 5000 {
+		elem := rnd.IntN(1000)
+		if rnd.IntN(3) == 0 {
+			set.Remove(elem)
+			reference.Remove(elem)
+		} else {
+			set.AddWithoutValue(elem)
+			reference.AddWithoutValue(elem)
+		}
+	}
+
+	// Then
+	checkAVL(t, set.root)
+	expected := reference.List()
+	slices.Sort(expected)
+	assert.Equal(t, expected, set.List())
+	assert.True(t, set.Equals(reference))
+}
+
+func TestShouldGetMinAndMaxOfSortedSet(t *testing.T) {
+	// Given
+	set := newNumberSortedSet(5, 3, 8)
+
+	// When
+	minElem, minValue, err1 := set.Min()
+	maxElem, maxValue, err2 := set.Max()
+	// Then
+	assert.Equal(t, 3, minElem)
+	assert.Equal(t, "***", minValue)
+	assert.Nil(t, err1)
+	assert.Equal(t, 8, maxElem)
+	assert.Equal(t, "********", maxValue)
+	assert.Nil(t, err2)
+
+	// Given
+	emptySet := NewSortedWithoutValues[int]()
+	// When
+	_, _, err3 := emptySet.Min()
+	_, _, err4 := emptySet.Max()
+	// Then
+	assert.NotNil(t, err3)
+	assert.NotNil(t, err4)
+}
+
+func TestShouldGetFloorAndCeilingOfSortedSet(t *testing.T) {
+	// Given
+	set := newNumberSortedSet(10, 20, 30)
+
+	// Expect
+	for _, testCase := range []struct {
+		element, floor, ceiling int
+		floorErr, ceilingErr    bool
+	}{
+		{element: 5, ceiling: 10, floorErr: true},
+		{element: 10, floor: 10, ceiling: 10},
+		{element: 15, floor: 10, ceiling: 20},
+		{element: 30, floor: 30, ceiling: 30},
+		{element: 35, floor: 30, ceilingErr: true},
+	} {
+		floor, _, err := set.Floor(testCase.element)
+		assert.Equal(t, testCase.floorErr, err != nil, "floor of %d", testCase.element)
+		assert.Equal(t, testCase.floor, floor, "floor of %d", testCase.element)
+		ceiling, _, err := set.Ceiling(testCase.element)
+		assert.Equal(t, testCase.ceilingErr, err != nil, "ceiling of %d", testCase.element)
+		assert.Equal(t, testCase.ceiling, ceiling, "ceiling of %d", testCase.element)
+	}
+}
+
+func TestShouldIterateOverRangeOfSortedSet(t *testing.T) {
+	// Given
+	set := NewSortedWithoutValues[int]()
+	for i := range 100 {
+		set.AddWithoutValue(i * 2)
+	}
+
+	collect := func(lo, hi int) []int {
+		elements := []int{}
+		for elem := range set.Range(lo, hi) {
+			elements = append(elements, elem)
+		}
+		return elements
+	}
+
+	// Expect
+	assert.Equal(t, []int{10, 12, 14}, collect(10, 14))
+	assert.Equal(t, []int{12, 14}, collect(11, 15))
+	assert.Equal(t, []int{0, 2}, collect(-10, 2))
+	assert.Equal(t, []int{196, 198}, collect(195, 1000))
+	assert.Equal(t, []int{}, collect(15, 11))
+	assert.Equal(t, []int{}, collect(11, 11))
+
+	// When breaking out of the loop early
+	count := 0
+	for range set.Range(0, 100) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	// Then
+	assert.Equal(t, 3, count)
+}
+
+func TestShouldRankAndSelectElementsOfSortedSet(t *testing.T) {
+	// Given
+	set := newNumberSortedSet(50, 10, 40, 20, 30)
+
+	// Expect
+	assert.Equal(t, 0, set.Rank(10))
+	assert.Equal(t, 0, set.Rank(5))
+	assert.Equal(t, 1, set.Rank(15))
+	assert.Equal(t, 2, set.Rank(30))
+	assert.Equal(t, 5, set.Rank(100))
+	for i, expected := range []int{10, 20, 30, 40, 50} {
+		elem, _, err := set.Select(i)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, elem)
+		assert.Equal(t, i, set.Rank(elem))
+	}
+	_, _, err := set.Select(5)
+	assert.NotNil(t, err)
+	_, _, err = set.Select(-1)
+	assert.NotNil(t, err)
+}
+
+func TestShouldSortWithCompareFunc(t *testing.T) {
+	// Given
+	type person struct {
+		name string
+		age  int
+	}
+	set := NewSortedWithValuesFunc[person, bool](func(a, b person) int {
+		if a.age != b.age {
+			return b.age - a.age
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	// When
+	set.AddWithValue(person{"bob", 30}, true)
+	set.AddWithValue(person{"alice", 40}, false)
+	set.AddWithValue(person{"carol", 30}, true)
+
+	// Then
+	assert.Equal(t, []person{{"alice", 40}, {"bob", 30}, {"carol", 30}}, set.List())
+	oldest, _, _ := set.Min()
+	assert.Equal(t, person{"alice", 40}, oldest)
+
+	// and derived sets keep the order
+	assert.Equal(t, []person{{"bob", 30}, {"carol", 30}}, set.Filter(func(p person, value bool) bool {
+		return value
+	}).List())
+}
+
+func TestShouldUseNaturalOrderWithoutCompareFunc(t *testing.T) {
+	// Given
+	type label string
+	labels := NewSortedWithoutValuesFunc[label](nil)
+	prices := NewSortedWithValuesFunc[float64, string](nil)
+
+	// When
+	labels.AddWithoutValue("sale")
+	labels.AddWithoutValue("new")
+	prices.AddWithValue(1.5, "apple")
+	prices.AddWithValue(0.25, "banana")
+
+	// Then
+	assert.Equal(t, []label{"new", "sale"}, labels.List())
+	assert.Equal(t, []float64{0.25, 1.5}, prices.List())
+
+	// Expect a panic for types without natural order
+	type person struct{ name string }
+	assert.PanicsWithValue(t, "cannot create sorted set without compare function, set.person has no natural order", func() {
+		NewSortedWithoutValuesFunc[person](nil)
+	})
+}
+
+func TestSortedSetOperationsShouldMergeSortedSets(t *testing.T) {
+	// Given
+	set1 := newNumberSortedSet(1, 2, 3, 4, 5)
+	set2 := NewSortedWithValues[int, string]()
+	set2.AddWithValue(4, "four")
+	set2.AddWithValue(5, "five")
+	set2.AddWithValue(6, "six")
+
+	// Expect
+	assert.Equal(t, "4 (four), 5 (five)", set1.Intersect(set2).StringWithValues())
+	assert.Equal(t, "1 (*), 2 (**), 3 (***), 4 (four), 5 (five), 6 (six)", set1.Unite(set2).StringWithValues())
+	assert.Equal(t, "1, 2, 3, 6", set1.UniteDisjunctively(set2).String())
+	assert.Equal(t, "1, 2, 3", set1.Subtract(set2).String())
+	assert.Equal(t, "6", set2.Subtract(set1).String())
+	assert.True(t, set1.Intersect(set2).IsSubset(set1))
+	assert.False(t, set2.IsSubset(set1))
+	assert.True(t, set1.Equals(set1.Copy()))
+	assert.False(t, set1.Equals(set2))
+
+	// and merged sets are balanced sorted sets again
+	united := set1.Unite(set2).(*sortedSet[int, string])
+	checkAVL(t, united.root)
+	assert.Equal(t, 3, united.Rank(4))
+
+	// When
+	set1.AddAll(set2)
+	// Then
+	assert.Equal(t, "1, 2, 3, 4, 5, 6", set1.String())
+
+	// When
+	set1.RemoveAll(set2)
+	// Then
+	assert.Equal(t, "1, 2, 3", set1.String())
+}
+
+func TestSortedSetShouldBehaveLikeSet(t *testing.T) {
+	// Given
+	set1 := newNumberSortedSet(3, 1, 2)
+	plainSet := NewWithValues[int, string]()
+	plainSet.AddWithValue(3, "three")
+	plainSet.AddWithValue(4, "four")
+
+	// Expect
+	assert.Equal(t, 3, set1.Size())
+	assert.True(t, set1.Contains(2))
+	assert.True(t, set1.ContainsAny(7, 3))
+	assert.Equal(t, map[int]string{1: "*", 2: "**", 3: "***"}, set1.GetElements())
+	assert.Equal(t, "3 (three)", set1.Intersect(plainSet).StringWithValues())
+	assert.Equal(t, "1, 2, 3, 4", set1.Unite(plainSet).String())
+	assert.Equal(t, "1, 2, 4", set1.UniteDisjunctively(plainSet).String())
+	assert.Equal(t, "1, 2", set1.Subtract(plainSet).String())
+	assert.Equal(t, "2, 4, 6", set1.Map(func(elem int, value string) (int, string) {
+		return elem * 2, value
+	}).String())
+	assert.Equal(t, map[int]string{3: "***"}, plainSet.Intersect(set1).GetElements())
+	assert.Equal(t, []string{"*", "**", "***"}, slices.Collect(set1.Values()))
+
+	// When
+	elem, value, err := set1.OneR()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, set1.GetElements()[elem], value)
+
+	// When
+	set1.AddAll(plainSet)
+	// Then
+	assert.Equal(t, "1 (*), 2 (**), 3 (three), 4 (four)", set1.StringWithValues())
+
+	// When
+	set1.Clear()
+	// Then
+	assert.Equal(t, 0, set1.Size())
+	_, _, err = set1.OneR()
+	assert.NotNil(t, err)
+}
+
+func BenchmarkSortedSetIntersect(b *testing.B) {
+	set1 := NewSortedWithoutValues[int]()
+	set2 := NewSortedWithoutValues[int]()
+	for i := range 10_000 {
+		set1.AddWithoutValue(i)
+		set2.AddWithoutValue(i * 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set1.Intersect(set2)
+	}
+}
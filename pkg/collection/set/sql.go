@@ -0,0 +1,276 @@
+package set
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+)
+
+// SQLEncoding defines how a set is stored in a column of an SQL database.
+type SQLEncoding int
+
+const (
+	// SQLJSON stores the set as JSON text, see the MarshalJSON method of sets. It is the only encoding for sets with values.
+	SQLJSON SQLEncoding = iota
+	// SQLPostgresArray stores the elements as PostgreSQL array literal like {a,b,"c d"}, e.g. in a column of type text[].
+	SQLPostgresArray
+	// SQLDelimited stores the elements as text separated by a separator like a,b,c.
+	SQLDelimited
+)
+
+// String returns the name of the encoding.
+func (e SQLEncoding) String() string {
+	switch e {
+	case SQLJSON:
+		return "JSON"
+	case SQLPostgresArray:
+		return "PostgreSQL array"
+	case SQLDelimited:
+		return "delimited text"
+	default:
+		return fmt.Sprintf("SQLEncoding(%d)", int(e))
+	}
+}
+
+// SQLSet wraps a set to store it in a column of an SQL database, implementing sql.Scanner and driver.Valuer.
+// Elements are converted to and from text like by ReadCSV and WriteCSV for SQLPostgresArray and SQLDelimited.
+//
+//	tags := set.SQLSet[string, set.InternalEmptyType]{Encoding: set.SQLPostgresArray}
+//	err := db.QueryRow("SELECT tags FROM articles WHERE id = $1", id).Scan(&tags)
+type SQLSet[T comparable, V any] struct {
+	Set      Set[T, V]
+	Encoding SQLEncoding
+	// Separator separates the elements with SQLDelimited, "," if not set.
+	// Spaces around the elements and empty elements are ignored when scanning.
+	Separator string
+}
+
+// Value returns the wrapped set as text in the configured encoding, or nil (NULL) if no set is wrapped.
+// The elements are sorted if T is an ordered type (linked and sorted sets keep their own order), so the text is deterministic.
+func (s SQLSet[T, V]) Value() (driver.Value, error) {
+	if s.Set == nil {
+		return nil, nil
+	}
+	text, err := s.format()
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert set to SQL value: %w", err)
+	}
+	return text, nil
+}
+
+// Scan replaces the content of the wrapped set by the set decoded from a text or byte slice column in the configured encoding.
+// If no set is wrapped, a new insertion-ordered set is created. NULL clears the wrapped set (or leaves the missing set missing).
+// If the value is invalid, an error is returned and the set remains unchanged.
+func (s *SQLSet[T, V]) Scan(src any) error {
+	var text string
+	switch src := src.(type) {
+	case nil:
+		if s.Set != nil {
+			s.Set.Clear()
+		}
+		return nil
+	case string:
+		text = src
+	case []byte:
+		text = string(src)
+	default:
+		return fmt.Errorf("cannot scan set from SQL value of type %T", src)
+	}
+	decoded, err := s.parse(text)
+	if err != nil {
+		return fmt.Errorf("cannot scan set from SQL value: %w", err)
+	}
+	if s.Set == nil {
+		s.Set = decoded
+		return nil
+	}
+	replaceContent(s.Set, decoded)
+	return nil
+}
+
+func (s SQLSet[T, V]) separator() string {
+	if s.Separator == "" {
+		return ","
+	}
+	return s.Separator
+}
+
+func (s SQLSet[T, V]) format() (string, error) {
+	if s.Encoding != SQLJSON && scalarcodec.HasValues[V]() {
+		return "", fmt.Errorf("a set with values cannot be stored as %v, use SQLJSON", s.Encoding)
+	}
+	switch s.Encoding {
+	case SQLJSON:
+		data, err := marshalJSON(s.Set)
+		return string(data), err
+	case SQLPostgresArray:
+		var b strings.Builder
+		b.WriteByte('{')
+		for i, e := range orderedEntries(s.Set) {
+			text, err := formatText(e.element)
+			if err != nil {
+				return "", fmt.Errorf("element %v: %w", e.element, err)
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writePostgresArrayElement(&b, text)
+		}
+		b.WriteByte('}')
+		return b.String(), nil
+	case SQLDelimited:
+		separator := s.separator()
+		texts := make([]string, 0, s.Set.Size())
+		for _, e := range orderedEntries(s.Set) {
+			text, err := formatText(e.element)
+			if err != nil {
+				return "", fmt.Errorf("element %v: %w", e.element, err)
+			}
+			if text == "" || text != strings.TrimSpace(text) || strings.Contains(text, separator) {
+				return "", fmt.Errorf("element %q cannot be stored as %v with separator %q", text, s.Encoding, separator)
+			}
+			texts = append(texts, text)
+		}
+		return strings.Join(texts, separator), nil
+	default:
+		return "", fmt.Errorf("unknown encoding %v", s.Encoding)
+	}
+}
+
+func (s SQLSet[T, V]) parse(text string) (*linkedSet[T, V], error) {
+	if s.Encoding != SQLJSON && scalarcodec.HasValues[V]() {
+		return nil, fmt.Errorf("a set with values cannot be stored as %v, use SQLJSON", s.Encoding)
+	}
+	var texts []string
+	switch s.Encoding {
+	case SQLJSON:
+		decoded, err := unmarshalJSON[T, V]([]byte(text))
+		if decoded == nil && err == nil {
+			decoded = newLinkedSet[T, V]()
+		}
+		return decoded, err
+	case SQLPostgresArray:
+		var err error
+		if texts, err = splitPostgresArray(text); err != nil {
+			return nil, err
+		}
+	case SQLDelimited:
+		for _, field := range strings.Split(text, s.separator()) {
+			if field = strings.TrimSpace(field); field != "" {
+				texts = append(texts, field)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown encoding %v", s.Encoding)
+	}
+	decoded := newLinkedSet[T, V]()
+	for _, t := range texts {
+		elem, err := parseText[T](t)
+		if err != nil {
+			return nil, fmt.Errorf("element %q: %w", t, err)
+		}
+		var value V
+		decoded.AddWithValue(elem, value)
+	}
+	return decoded, nil
+}
+
+// writePostgresArrayElement writes text as element of a PostgreSQL array literal, quoted and escaped if needed.
+func writePostgresArrayElement(b *strings.Builder, text string) {
+	if text != "" && !strings.EqualFold(text, "NULL") && !strings.ContainsAny(text, "{},\"\\ \t\n\r\v\f") {
+		b.WriteString(text)
+		return
+	}
+	b.WriteByte('"')
+	for _, r := range text {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+}
+
+// splitPostgresArray returns the elements of a one-dimensional PostgreSQL array literal like {a,"b c",d\,e}, unquoted and unescaped.
+// NULL elements are rejected, since sets cannot contain them.
+func splitPostgresArray(text string) ([]string, error) {
+	trimmed := strings.TrimSpace(text)
+	if len(trimmed) < 2 || trimmed[0] != '{' || trimmed[len(trimmed)-1] != '}' {
+		return nil, fmt.Errorf("invalid PostgreSQL array %q, expected {...}", text)
+	}
+	body := trimmed[1 : len(trimmed)-1]
+	if strings.TrimSpace(body) == "" {
+		return nil, nil
+	}
+	// offset converts an index into body into an offset in text for error messages
+	offset := strings.Index(text, "{") + 1
+	var elements []string
+	i := 0
+	for {
+		for i < len(body) && isSpace(body[i]) {
+			i++
+		}
+		start := i
+		var b strings.Builder
+		if i < len(body) && body[i] == '"' {
+			for i++; ; i++ {
+				if i >= len(body) {
+					return nil, fmt.Errorf("unterminated quoted element at offset %d", offset+start)
+				}
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				} else if body[i] == '"' {
+					i++
+					break
+				}
+				b.WriteByte(body[i])
+			}
+			for i < len(body) && isSpace(body[i]) {
+				i++
+			}
+		} else {
+			// trailing unescaped spaces are not part of an unquoted element
+			length, escaped := 0, false
+			for ; i < len(body) && body[i] != ','; i++ {
+				switch c := body[i]; {
+				case c == '{':
+					return nil, fmt.Errorf("multi-dimensional arrays are not supported, found '{' at offset %d", offset+i)
+				case c == '}' || c == '"':
+					return nil, fmt.Errorf("unexpected %q at offset %d", c, offset+i)
+				case c == '\\' && i+1 < len(body):
+					i++
+					b.WriteByte(body[i])
+					length, escaped = b.Len(), true
+				default:
+					b.WriteByte(c)
+					if !isSpace(c) {
+						length = b.Len()
+					}
+				}
+			}
+			element := b.String()[:length]
+			if element == "" {
+				return nil, fmt.Errorf("empty element at offset %d", offset+start)
+			}
+			if !escaped && strings.EqualFold(element, "NULL") {
+				return nil, fmt.Errorf("NULL element at offset %d cannot be contained in a set", offset+start)
+			}
+			b.Reset()
+			b.WriteString(element)
+		}
+		elements = append(elements, b.String())
+		if i >= len(body) {
+			return elements, nil
+		}
+		if body[i] != ',' {
+			return nil, fmt.Errorf("expected ',' but found %q at offset %d", body[i], offset+i)
+		}
+		i++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
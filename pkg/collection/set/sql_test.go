@@ -0,0 +1,278 @@
+package set
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver is a database/sql driver without database: each data source name is a table with a single column,
+// "INSERT" appends the value of its argument and "SELECT" returns all values.
+type fakeDriver struct {
+	mu     sync.Mutex
+	tables map[string][]driver.Value
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+	name   string
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func init() {
+	sql.Register("fakeset", &fakeDriver{tables: make(map[string][]driver.Value)})
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d, name: name}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query != "INSERT" || len(args) != 1 {
+		return nil, errors.New("unsupported statement")
+	}
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	s.conn.driver.tables[s.conn.name] = append(s.conn.driver.tables[s.conn.name], args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.query != "SELECT" {
+		return nil, errors.New("unsupported query")
+	}
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	return &fakeRows{values: append([]driver.Value(nil), s.conn.driver.tables[s.conn.name]...)}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"set"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func openFakeDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("fakeset", t.Name())
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestShouldStoreAndScanSetsInSQLColumns(t *testing.T) {
+	for encoding, expected := range map[SQLEncoding]string{
+		SQLJSON:          `["new arrival","sale"]`,
+		SQLPostgresArray: `{"new arrival",sale}`,
+		SQLDelimited:     `new arrival;sale`,
+	} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			// Given
+			db := openFakeDB(t)
+			labels := NewWithoutValues[string]()
+			labels.AddWithoutValue("sale")
+			labels.AddWithoutValue("new arrival")
+
+			// When
+			_, err := db.Exec("INSERT", SQLSet[string, InternalEmptyType]{Set: labels, Encoding: encoding, Separator: ";"})
+			// Then
+			assert.Nil(t, err)
+
+			// When
+			var stored string
+			err1 := db.QueryRow("SELECT").Scan(&stored)
+			scanned := SQLSet[string, InternalEmptyType]{Encoding: encoding, Separator: ";"}
+			err2 := db.QueryRow("SELECT").Scan(&scanned)
+			// Then
+			assert.Nil(t, err1)
+			assert.Equal(t, expected, stored)
+			assert.Nil(t, err2)
+			assert.True(t, labels.Equals(scanned.Set))
+		})
+	}
+}
+
+func TestShouldStoreAndScanSetsWithValuesAsJSON(t *testing.T) {
+	// Given
+	db := openFakeDB(t)
+	limits := NewWithValues[string, int]()
+	limits.AddWithValue("cpu", 4)
+	limits.AddWithValue("memory", 8)
+	scanned := SQLSet[string, int]{Set: NewSortedWithValues[string, int]()}
+	scanned.Set.AddWithValue("disk", 100)
+
+	// When
+	_, err1 := db.Exec("INSERT", SQLSet[string, int]{Set: limits})
+	err2 := db.QueryRow("SELECT").Scan(&scanned)
+
+	// Then the content of the set is replaced
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.True(t, limits.Equals(scanned.Set))
+}
+
+func TestShouldStoreAndScanNullSets(t *testing.T) {
+	// Given
+	db := openFakeDB(t)
+	existing := SQLSet[string, InternalEmptyType]{Set: NewWithoutValues[string]()}
+	existing.Set.AddWithoutValue("stale")
+	var missing SQLSet[string, InternalEmptyType]
+
+	// When
+	_, err1 := db.Exec("INSERT", SQLSet[string, InternalEmptyType]{})
+	err2 := db.QueryRow("SELECT").Scan(&existing)
+	err3 := db.QueryRow("SELECT").Scan(&missing)
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Equal(t, 0, existing.Set.Size())
+	assert.Nil(t, missing.Set)
+}
+
+func TestShouldScanPostgresArraysFromBytes(t *testing.T) {
+	for text, expected := range map[string][]string{
+		`{}`:                          {},
+		` { a , b } `:                 {"a", "b"},
+		`{"b \"c\"",d\,e,"NULL",f\ }`: {`b "c"`, "d,e", "NULL", "f "},
+		`{"",x}`:                      {"", "x"},
+	} {
+		t.Run(text, func(t *testing.T) {
+			// Given
+			db := openFakeDB(t)
+			_, err := db.Exec("INSERT", []byte(text))
+			assert.Nil(t, err)
+			scanned := SQLSet[string, InternalEmptyType]{Encoding: SQLPostgresArray}
+
+			// When
+			err = db.QueryRow("SELECT").Scan(&scanned)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, expected, scanned.Set.List())
+		})
+	}
+}
+
+func TestShouldQuotePostgresArrayElements(t *testing.T) {
+	// Given
+	labels := NewLinkedWithoutValues[string]()
+	for _, label := range []string{"plain", "", "null", `a "quoted" \ text`, "{braces}", "comma,"} {
+		labels.AddWithoutValue(label)
+	}
+
+	// When
+	value, err := SQLSet[string, InternalEmptyType]{Set: labels, Encoding: SQLPostgresArray}.Value()
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `{plain,"","null","a \"quoted\" \\ text","{braces}","comma,"}`, value)
+
+	// When
+	scanned := SQLSet[string, InternalEmptyType]{Encoding: SQLPostgresArray}
+	err = scanned.Scan(value)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, labels.List(), scanned.Set.List())
+}
+
+func TestShouldScanNumbersFromDelimitedText(t *testing.T) {
+	// Given
+	ports := SQLSet[int, InternalEmptyType]{Set: NewSortedWithoutValues[int](), Encoding: SQLDelimited}
+
+	// When
+	err := ports.Scan("443, 80,,8080,")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443, 8080}, ports.Set.List())
+}
+
+func TestShouldFailToScanInvalidSQLValues(t *testing.T) {
+	for _, tc := range []struct {
+		encoding SQLEncoding
+		src      any
+		expected string
+	}{
+		{SQLJSON, `[1,"x"]`, "cannot scan set from SQL value: cannot decode set from JSON: json: cannot unmarshal string into Go value of type int"},
+		{SQLJSON, 42, "cannot scan set from SQL value of type int"},
+		{SQLDelimited, "1,x", `cannot scan set from SQL value: element "x": strconv.ParseInt: parsing "x": invalid syntax`},
+		{SQLPostgresArray, "1,2", `cannot scan set from SQL value: invalid PostgreSQL array "1,2", expected {...}`},
+		{SQLPostgresArray, "{1,{2}}", "cannot scan set from SQL value: multi-dimensional arrays are not supported, found '{' at offset 3"},
+		{SQLPostgresArray, "{1,NULL}", "cannot scan set from SQL value: NULL element at offset 3 cannot be contained in a set"},
+		{SQLPostgresArray, "{1,,2}", "cannot scan set from SQL value: empty element at offset 3"},
+		{SQLPostgresArray, `{1,"2}`, "cannot scan set from SQL value: unterminated quoted element at offset 3"},
+		{SQLPostgresArray, `{"1"2}`, "cannot scan set from SQL value: expected ',' but found '2' at offset 4"},
+		{SQLEncoding(7), "1", "cannot scan set from SQL value: unknown encoding SQLEncoding(7)"},
+	} {
+		// Given
+		numbers := SQLSet[int, InternalEmptyType]{Set: NewWithoutValues[int](), Encoding: tc.encoding}
+		numbers.Set.AddWithoutValue(42)
+
+		// When
+		err := numbers.Scan(tc.src)
+
+		// Then the set remains unchanged
+		assert.EqualError(t, err, tc.expected)
+		assert.Equal(t, []int{42}, numbers.Set.List())
+	}
+}
+
+func TestShouldFailToConvertSetsToSQLValues(t *testing.T) {
+	// Given
+	labels := NewWithoutValues[string]()
+	labels.AddWithoutValue("a,b")
+	limits := NewWithValues[string, int]()
+
+	// When
+	_, err1 := SQLSet[string, InternalEmptyType]{Set: labels, Encoding: SQLDelimited}.Value()
+	_, err2 := SQLSet[string, int]{Set: limits, Encoding: SQLPostgresArray}.Value()
+	err3 := (&SQLSet[string, int]{Encoding: SQLDelimited}).Scan("a")
+
+	// Then
+	assert.EqualError(t, err1, `cannot convert set to SQL value: element "a,b" cannot be stored as delimited text with separator ","`)
+	assert.EqualError(t, err2, "cannot convert set to SQL value: a set with values cannot be stored as PostgreSQL array, use SQLJSON")
+	assert.EqualError(t, err3, "cannot scan set from SQL value: a set with values cannot be stored as delimited text, use SQLJSON")
+}
@@ -0,0 +1,368 @@
+package set
+
+import (
+	"iter"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncSet is a Set that is safe for concurrent use by multiple goroutines.
+// All methods of Set are guarded by a read-write mutex, so no external locking is needed.
+// Additionally, a SyncSet provides atomic check-and-modify operations.
+type SyncSet[T comparable, V any] interface {
+	Set[T, V]
+
+	AddIfAbsent(T, V) bool
+	LoadOrStore(T, V) (V, bool)
+	RemoveIfPresent(T) (V, bool)
+}
+
+type syncSet[T comparable, V any] struct {
+	id       uint64
+	mu       sync.RWMutex
+	elements map[T]V
+}
+
+// syncSetCounter provides unique ids for sync sets which define the order in which two sync sets are locked.
+var syncSetCounter atomic.Uint64
+
+// NewSyncWithValues creates a new, empty, concurrency-safe set that can contain elements of type T having values of type V (like a map).
+func NewSyncWithValues[T comparable, V any]() SyncSet[T, V] {
+	return newSyncSet(createNewWithValues[T, V]())
+}
+
+// NewSyncWithoutValues creates a new, empty, concurrency-safe set that can contain elements of type T (like a set of labels).
+func NewSyncWithoutValues[T comparable]() SyncSet[T, InternalEmptyType] {
+	return newSyncSet(createNewWithValues[T, InternalEmptyType]())
+}
+
+// newSyncSet creates a new sync set taking ownership of the given map.
+func newSyncSet[T comparable, V any](elements map[T]V) *syncSet[T, V] {
+	return &syncSet[T, V]{
+		id:       syncSetCounter.Add(1),
+		elements: elements,
+	}
+}
+
+// unlocked returns a view of this set which operates on the internal map without any locking.
+// The caller must hold the lock of this set as long as the view is used.
+func (s *syncSet[T, V]) unlocked() *tzSet[T, V] {
+	return &tzSet[T, V]{elements: s.elements}
+}
+
+// lockWith locks this set (for writing if write is true, for reading otherwise) as well as otherSet (for reading)
+// if otherSet is a sync set, too. Two sync sets are always locked in the order of their ids, so concurrent binary
+// operations like s1.Intersect(s2) and s2.Intersect(s1) cannot deadlock.
+// Returns a set that can be used in place of otherSet while the locks are held as well as the function releasing all locks.
+func (s *syncSet[T, V]) lockWith(otherSet Set[T, V], write bool) (Set[T, V], func()) {
+	lockSelf, unlockSelf := s.mu.RLock, s.mu.RUnlock
+	if write {
+		lockSelf, unlockSelf = s.mu.Lock, s.mu.Unlock
+	}
+	other, ok := otherSet.(*syncSet[T, V])
+	if !ok || other == nil {
+		lockSelf()
+		return otherSet, unlockSelf
+	}
+	if other == s {
+		lockSelf()
+		return s.unlocked(), unlockSelf
+	}
+	if s.id < other.id {
+		lockSelf()
+		other.mu.RLock()
+	} else {
+		other.mu.RLock()
+		lockSelf()
+	}
+	return other.unlocked(), func() {
+		other.mu.RUnlock()
+		unlockSelf()
+	}
+}
+
+// snapshot returns a copy of the internal map taken while holding the read lock.
+func (s *syncSet[T, V]) snapshot() map[T]V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.elements)
+}
+
+// randIndex returns a random index in the range of the elements.
+// If the set is empty, -1 is returned.
+// This method is not part of the public API.
+func (s *syncSet[T, V]) randIndex() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unlocked().randIndex()
+}
+
+// GetElements returns a copy of the internal map of elements.
+// Contrary to the non-concurrent set, changes to the returned map do not interfere with this set.
+func (s *syncSet[T, V]) GetElements() map[T]V {
+	return s.snapshot()
+}
+
+// AddWithValue adds an element with an associated value to the set.
+func (s *syncSet[T, V]) AddWithValue(element T, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elements[element] = value
+}
+
+// AddWithoutValue adds an element (without an associated value) to the set.
+func (s *syncSet[T, V]) AddWithoutValue(element T) {
+	var empty V
+	s.AddWithValue(element, empty)
+}
+
+// Remove removes an element from the set.
+func (s *syncSet[T, V]) Remove(element T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.elements, element)
+}
+
+// AddAll adds all elements (including the value) from otherSet to this set.
+// If otherSet is nil, nothing happens.
+// If an element already exists in this set, the value is overwritten with the value from otherSet.
+// The otherSet remains unchanged.
+func (s *syncSet[T, V]) AddAll(otherSet Set[T, V]) {
+	other, unlock := s.lockWith(otherSet, true)
+	defer unlock()
+	s.unlocked().AddAll(other)
+}
+
+// RemoveAll removes all elements from otherSet from this set.
+// If otherSet is nil, nothing happens.
+// The otherSet remains unchanged.
+func (s *syncSet[T, V]) RemoveAll(otherSet Set[T, V]) {
+	other, unlock := s.lockWith(otherSet, true)
+	defer unlock()
+	s.unlocked().RemoveAll(other)
+}
+
+// Clear removes all elements from the set.
+func (s *syncSet[T, V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.elements)
+}
+
+// Size returns the number of elements in the set.
+func (s *syncSet[T, V]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.elements)
+}
+
+// List returns all elements (without values) of the set as a slice.
+// The returned slice is a copy, changes to that copy do not interfere with the original set.
+func (s *syncSet[T, V]) List() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unlocked().List()
+}
+
+// All returns an iterator over all elements and their values of the set.
+// The iterator works on a snapshot taken when the iteration starts, so the set may be modified while iterating.
+// The order of the elements is not defined.
+func (s *syncSet[T, V]) All() iter.Seq2[T, V] {
+	return func(yield func(T, V) bool) {
+		for elem, value := range s.snapshot() {
+			if !yield(elem, value) {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over all elements (without values) of the set.
+// The iterator works on a snapshot taken when the iteration starts, so the set may be modified while iterating.
+// The order of the elements is not defined.
+func (s *syncSet[T, V]) Elements() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem := range s.snapshot() {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of all elements of the set.
+// The iterator works on a snapshot taken when the iteration starts, so the set may be modified while iterating.
+// The order of the values is not defined.
+func (s *syncSet[T, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range s.snapshot() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Contains checks whether or not the given element exists in the set (ignoring the value).
+// Returns true if the element is in the set, false otherwise.
+func (s *syncSet[T, V]) Contains(element T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.elements[element]
+	return exists
+}
+
+// ContainsAny checks if the set contains at least one of the given elements (ignoring the values).
+// Returns true if at least one of the given elements is in the set, false otherwise.
+func (s *syncSet[T, V]) ContainsAny(elements ...T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unlocked().ContainsAny(elements...)
+}
+
+// Equals checks if this set is equal to otherSet ignoring the values.
+// Returns true if both sets are of equal size and contain the same elements (ignoring the values), false otherwise.
+func (s *syncSet[T, V]) Equals(otherSet Set[T, V]) bool {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	return s.unlocked().Equals(other)
+}
+
+// IsSubset checks if this set is a subset of otherSet.
+// Returns true if all elements of this set are in otherSet, false otherwise.
+// If otherSet is nil, true is returned only if this set is empty.
+// The values are not considered when checking for subset.
+func (s *syncSet[T, V]) IsSubset(otherSet Set[T, V]) bool {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	return s.unlocked().IsSubset(other)
+}
+
+// String returns a string representation of the set.
+// The elements are separated by commas.
+// The values are not included in the string representation.
+// The order of the elements is not defined.
+// If the set is empty, an empty string is returned.
+func (s *syncSet[T, V]) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unlocked().String()
+}
+
+// StringWithValues returns a string representation of the set including values.
+// Each element's value is given in braces after the element.
+// The order of the elements is not defined.
+// If the set is empty, an empty string is returned.
+func (s *syncSet[T, V]) StringWithValues() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unlocked().StringWithValues()
+}
+
+// Copy returns a new sync set containing all elements (including the values) of this set.
+func (s *syncSet[T, V]) Copy() Set[T, V] {
+	return newSyncSet(s.snapshot())
+}
+
+// Intersect returns a new sync set containing only elements (including the values) that are in both, this set and otherSet.
+// If there are no common elements or otherSet is nil, a new empty set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *syncSet[T, V]) Intersect(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	return newSyncSet(s.unlocked().Intersect(other).GetElements())
+}
+
+// Unite returns a new sync set containing all elements (including the values) of both, this set and otherSet.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Values of elements that are in both sets are taken from otherSet.
+// Neither this set nor otherSet are changed.
+func (s *syncSet[T, V]) Unite(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	return newSyncSet(s.unlocked().Unite(other).GetElements())
+}
+
+// UniteDisjunctively returns a new sync set containing all elements (including the values) that are in either this set or otherSet, but not in both (symmetric difference).
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *syncSet[T, V]) UniteDisjunctively(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	return newSyncSet(s.unlocked().UniteDisjunctively(other).GetElements())
+}
+
+// Subtract returns a new sync set containing all elements (including the values) that are in this set but not in otherSet.
+// If otherSet is nil, a new set containing all elements of this set is returned.
+// Neither this set nor otherSet are changed.
+func (s *syncSet[T, V]) Subtract(otherSet Set[T, V]) Set[T, V] {
+	other, unlock := s.lockWith(otherSet, false)
+	defer unlock()
+	return newSyncSet(s.unlocked().Subtract(other).GetElements())
+}
+
+// Filter returns a new sync set containing only elements (including the values) of this set for which the filter function returns true.
+// The filter function is applied to a snapshot of this set without holding the lock, so it may safely access this set.
+// If the filter function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *syncSet[T, V]) Filter(filterFunc FilterFunc[T, V]) Set[T, V] {
+	snapshot := &tzSet[T, V]{elements: s.snapshot()}
+	return newSyncSet(snapshot.Filter(filterFunc).GetElements())
+}
+
+// Map returns a new sync set containing all elements (including the values) returned by the map function which is applied to each element of this set.
+// The map function is applied to a snapshot of this set without holding the lock, so it may safely access this set.
+// If the map function is nil, a copy of this set is returned.
+// This set remains unchanged.
+func (s *syncSet[T, V]) Map(mapFunc MapFunc[T, V]) Set[T, V] {
+	snapshot := &tzSet[T, V]{elements: s.snapshot()}
+	return newSyncSet(snapshot.Map(mapFunc).GetElements())
+}
+
+// OneR returns one random element (and its value) from the set.
+// If the set is empty, an error is returned.
+func (s *syncSet[T, V]) OneR() (T, V, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unlocked().OneR()
+}
+
+// AddIfAbsent atomically adds the element with the given value if the element is not yet in the set.
+// Returns true if the element has been added, false if it already existed (in which case its value is left unchanged).
+func (s *syncSet[T, V]) AddIfAbsent(element T, value V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.elements[element]; exists {
+		return false
+	}
+	s.elements[element] = value
+	return true
+}
+
+// LoadOrStore atomically returns the existing value of the element if it is in the set.
+// Otherwise, it adds the element with the given value and returns that value.
+// The returned bool is true if the value was loaded, false if it was stored.
+func (s *syncSet[T, V]) LoadOrStore(element T, value V) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, exists := s.elements[element]; exists {
+		return existing, true
+	}
+	s.elements[element] = value
+	return value, false
+}
+
+// RemoveIfPresent atomically removes the element from the set.
+// Returns the value the element had and true if the element was in the set,
+// the zero value of V and false otherwise.
+func (s *syncSet[T, V]) RemoveIfPresent(element T) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, exists := s.elements[element]
+	if exists {
+		delete(s.elements, element)
+	}
+	return value, exists
+}
@@ -0,0 +1,211 @@
+package set
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncSetShouldBehaveLikeSet(t *testing.T) {
+	// Given
+	set1 := NewSyncWithValues[string, string]()
+	set1.AddWithValue("apple", "red")
+	set1.AddWithValue("banana", "yellow")
+	set1.AddWithValue("cherry", "dark red")
+
+	set2 := NewSyncWithValues[string, string]()
+	set2.AddWithValue("banana", "brownish")
+	set2.AddWithValue("mango", "green-orange")
+
+	// Expect
+	assert.Equal(t, 3, set1.Size())
+	assert.True(t, set1.Contains("apple"))
+	assert.True(t, set1.ContainsAny("kiwi", "cherry"))
+	assert.ElementsMatch(t, []string{"apple", "banana", "cherry"}, set1.List())
+	assert.Equal(t, map[string]string{"banana": "brownish"}, set1.Intersect(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red", "banana": "brownish", "cherry": "dark red", "mango": "green-orange"}, set1.Unite(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red", "cherry": "dark red", "mango": "green-orange"}, set1.UniteDisjunctively(set2).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red", "cherry": "dark red"}, set1.Subtract(set2).GetElements())
+	assert.False(t, set1.Equals(set2))
+	assert.True(t, set1.Equals(set1.Copy()))
+	assert.True(t, set1.Intersect(set2).IsSubset(set1))
+	assert.Equal(t, map[string]string{"apple": "red"}, set1.Filter(func(elem string, value string) bool {
+		return value == "red"
+	}).GetElements())
+
+	// and derived sets are sync sets again
+	_, ok := set1.Intersect(set2).(SyncSet[string, string])
+	assert.True(t, ok)
+
+	// and binary operations with non-concurrent sets work as well
+	plainSet := NewWithValues[string, string]()
+	plainSet.AddWithValue("apple", "green")
+	assert.Equal(t, map[string]string{"apple": "green"}, set1.Intersect(plainSet).GetElements())
+	assert.Equal(t, map[string]string{"apple": "red"}, plainSet.Intersect(set1).GetElements())
+
+	// and binary operations with the set itself do not deadlock
+	assert.True(t, set1.Equals(set1))
+	assert.Equal(t, 3, set1.Intersect(set1).Size())
+	set1.AddAll(set1)
+	assert.Equal(t, 3, set1.Size())
+}
+
+func TestSyncSetGetElementsShouldReturnCopy(t *testing.T) {
+	// Given
+	set := NewSyncWithoutValues[string]()
+	set.AddWithoutValue("apple")
+
+	// When
+	elements := set.GetElements()
+	elements["banana"] = internalEmptyValue
+
+	// Then
+	assert.Equal(t, 1, set.Size())
+	assert.False(t, set.Contains("banana"))
+}
+
+func TestSyncSetIteratorsShouldAllowModificationWhileIterating(t *testing.T) {
+	// Given
+	set := NewSyncWithValues[int, int]()
+	for i := range 10 {
+		set.AddWithValue(i, i*i)
+	}
+
+	// When
+	for elem := range set.Elements() {
+		set.Remove(elem)
+		set.AddWithValue(elem+100, 0)
+	}
+
+	// Then
+	assert.Equal(t, 10, set.Size())
+	assert.False(t, set.ContainsAny(0, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+}
+
+func TestShouldAddIfAbsent(t *testing.T) {
+	// Given
+	set := NewSyncWithValues[string, string]()
+
+	// Expect
+	assert.True(t, set.AddIfAbsent("apple", "red"))
+	assert.False(t, set.AddIfAbsent("apple", "green"))
+	assert.Equal(t, "red", set.GetElements()["apple"])
+}
+
+func TestShouldLoadOrStore(t *testing.T) {
+	// Given
+	set := NewSyncWithValues[string, string]()
+
+	// When
+	value1, loaded1 := set.LoadOrStore("apple", "red")
+	// Then
+	assert.Equal(t, "red", value1)
+	assert.False(t, loaded1)
+
+	// When
+	value2, loaded2 := set.LoadOrStore("apple", "green")
+	// Then
+	assert.Equal(t, "red", value2)
+	assert.True(t, loaded2)
+}
+
+func TestShouldRemoveIfPresent(t *testing.T) {
+	// Given
+	set := NewSyncWithValues[string, string]()
+	set.AddWithValue("apple", "red")
+
+	// When
+	value1, removed1 := set.RemoveIfPresent("apple")
+	// Then
+	assert.Equal(t, "red", value1)
+	assert.True(t, removed1)
+	assert.Equal(t, 0, set.Size())
+
+	// When
+	value2, removed2 := set.RemoveIfPresent("apple")
+	// Then
+	assert.Equal(t, "", value2)
+	assert.False(t, removed2)
+}
+
+func TestSyncSetShouldBeSafeForConcurrentUse(t *testing.T) {
+	// Given
+	set := NewSyncWithoutValues[int]()
+	goroutines := 16
+	perGoroutine := 1000
+
+	// When
+	var wg sync.WaitGroup
+	added := make([]int, goroutines)
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perGoroutine {
+				// all goroutines compete for the same elements
+				if set.AddIfAbsent(i, internalEmptyValue) {
+					added[g]++
+				}
+				set.Contains(i)
+				set.Size()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then every element has been added exactly once
+	total := 0
+	for _, count := range added {
+		total += count
+	}
+	assert.Equal(t, perGoroutine, total)
+	assert.Equal(t, perGoroutine, set.Size())
+}
+
+func TestConcurrentBinaryOperationsOnSyncSetsShouldNotDeadlock(t *testing.T) {
+	// Given
+	set1 := NewSyncWithoutValues[string]()
+	set2 := NewSyncWithoutValues[string]()
+	for i := range 100 {
+		set1.AddWithoutValue(fmt.Sprint(i))
+		set2.AddWithoutValue(fmt.Sprint(i * 2))
+	}
+
+	// When operating on both sets in opposite directions while writers are active
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				set1.Intersect(set2)
+				set1.AddAll(set2)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				set2.Subtract(set1)
+				set2.RemoveAll(set1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				set1.AddWithoutValue(fmt.Sprint(i))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				set2.AddWithoutValue(fmt.Sprint(i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then all operations have finished
+	assert.True(t, set1.Size() >= 100)
+}
@@ -0,0 +1,82 @@
+package set
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// parseText parses s into a value of type E. Types implementing encoding.TextUnmarshaler use it, strings, booleans,
+// integers and floats (including types based on them) are parsed with strconv. Empty structs (like InternalEmptyType) accept any text.
+func parseText[E any](s string) (E, error) {
+	var e E
+	if u, ok := any(&e).(encoding.TextUnmarshaler); ok {
+		err := u.UnmarshalText([]byte(s))
+		return e, err
+	}
+	v := reflect.ValueOf(&e).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return e, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return e, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return e, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return e, err
+		}
+		v.SetFloat(f)
+	default:
+		if v.Kind() != reflect.Struct || v.NumField() > 0 {
+			return e, fmt.Errorf("cannot parse %v from text", v.Type())
+		}
+	}
+	return e, nil
+}
+
+// formatText formats e as text, the inverse of parseText. Types implementing encoding.TextMarshaler use it,
+// all other types are formatted with fmt.Sprint (empty structs as empty text).
+func formatText[E any](e E) (string, error) {
+	if m, ok := any(e).(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	if v := reflect.ValueOf(e); v.Kind() == reflect.Struct && v.NumField() == 0 {
+		return "", nil
+	}
+	return fmt.Sprint(e), nil
+}
+
+// ParseError is returned (wrapped) when reading a set from text fails. It reports the position of the error in the input.
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error returns the position and the cause of the error.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,243 @@
+package set
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+)
+
+// XMLNames defines the names of the XML elements a set and its items are encoded with.
+type XMLNames struct {
+	// Root is the name of the XML element of the set itself. If not set, the name chosen by encoding/xml is used,
+	// which is the field name for fields, but the name of the generic set type (no valid XML name) for sets marshaled
+	// at the top level. So sets marshaled at the top level need a Root, or a start element given to xml.Encoder.EncodeElement.
+	Root string
+	// Item is the name of the XML element of each item of the set, "item" if not set.
+	Item string
+	// Element and Value are the names of the child elements of an item holding the element and its value,
+	// "element" and "value" if not set. The items of sets without values contain the element directly.
+	Element string
+	Value   string
+}
+
+func (n XMLNames) withDefaults() XMLNames {
+	if n.Item == "" {
+		n.Item = "item"
+	}
+	if n.Element == "" {
+		n.Element = "element"
+	}
+	if n.Value == "" {
+		n.Value = "value"
+	}
+	return n
+}
+
+// XMLSet wraps a set for encoding/xml to use other names than the default XMLNames or to reject duplicate elements.
+type XMLSet[T comparable, V any] struct {
+	Set        Set[T, V]
+	Names      XMLNames
+	Duplicates DuplicateHandling
+}
+
+// MarshalXML implements xml.Marshaler, encoding the wrapped set as XML with the configured names and root name.
+func (x XMLSet[T, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXML(e, start, x.Set, x.Names)
+}
+
+// UnmarshalXML replaces the content of the wrapped set by the set decoded from XML with the configured names.
+// If no set is wrapped, a new insertion-ordered set is created. With StrictDuplicates, an element occurring in more than one item
+// is rejected. If the data is invalid, an error is returned and the set remains unchanged.
+func (x *XMLSet[T, V]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	decoded, err := unmarshalXML[T, V](d, x.Names, x.Duplicates)
+	if err != nil {
+		return err
+	}
+	if x.Set == nil {
+		x.Set = decoded
+		return nil
+	}
+	replaceContent(x.Set, decoded)
+	return nil
+}
+
+// marshalXML encodes the set as the XML element start with one child element per item, in the order of orderedEntries.
+func marshalXML[T comparable, V any](e *xml.Encoder, start xml.StartElement, set Set[T, V], names XMLNames) error {
+	names = names.withDefaults()
+	if names.Root != "" {
+		start.Name = xml.Name{Local: names.Root}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if set != nil {
+		for _, entry := range orderedEntries(set) {
+			if err := marshalXMLItem(e, entry, names); err != nil {
+				return fmt.Errorf("cannot encode set to XML, element %v: %w", entry.element, err)
+			}
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func marshalXMLItem[T comparable, V any](e *xml.Encoder, entry entry[T, V], names XMLNames) error {
+	item := xml.StartElement{Name: xml.Name{Local: names.Item}}
+	if !scalarcodec.HasValues[V]() {
+		return e.EncodeElement(entry.element, item)
+	}
+	if err := e.EncodeToken(item); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(entry.element, xml.StartElement{Name: xml.Name{Local: names.Element}}); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(entry.value, xml.StartElement{Name: xml.Name{Local: names.Value}}); err != nil {
+		return err
+	}
+	return e.EncodeToken(item.End())
+}
+
+// unmarshalXML decodes the items of a set up to the end of the current XML element into a new linked set keeping the order of the input.
+// Errors are reported as ParseError with the position in the input.
+func unmarshalXML[T comparable, V any](d *xml.Decoder, names XMLNames, duplicates DuplicateHandling) (*linkedSet[T, V], error) {
+	names = names.withDefaults()
+	decoded := newLinkedSet[T, V]()
+	positions := make(map[T][2]int)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, xmlError(d, err)
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			return decoded, nil
+		case xml.StartElement:
+			line, column := d.InputPos()
+			if t.Name.Local != names.Item {
+				return nil, xmlError(d, fmt.Errorf("expected <%s> but found <%s>", names.Item, t.Name.Local))
+			}
+			elem, value, err := unmarshalXMLItem[T, V](d, t, names)
+			if err != nil {
+				return nil, err
+			}
+			if duplicates == StrictDuplicates {
+				if first, ok := positions[elem]; ok {
+					err := fmt.Errorf("duplicate element %v, first in line %d, column %d", elem, first[0], first[1])
+					return nil, fmt.Errorf("cannot decode set from XML: %w", &ParseError{Line: line, Column: column, Err: err})
+				}
+				positions[elem] = [2]int{line, column}
+			}
+			decoded.AddWithValue(elem, value)
+		}
+	}
+}
+
+func unmarshalXMLItem[T comparable, V any](d *xml.Decoder, item xml.StartElement, names XMLNames) (T, V, error) {
+	var elem T
+	var value V
+	if !scalarcodec.HasValues[V]() {
+		if err := d.DecodeElement(&elem, &item); err != nil {
+			return elem, value, xmlError(d, err)
+		}
+		return elem, value, nil
+	}
+	found := false
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return elem, value, xmlError(d, err)
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			if !found {
+				return elem, value, xmlError(d, fmt.Errorf("missing <%s> in <%s>", names.Element, names.Item))
+			}
+			return elem, value, nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case names.Element:
+				err = d.DecodeElement(&elem, &t)
+				found = true
+			case names.Value:
+				err = d.DecodeElement(&value, &t)
+			default:
+				err = fmt.Errorf("expected <%s> or <%s> but found <%s>", names.Element, names.Value, t.Name.Local)
+			}
+			if err != nil {
+				return elem, value, xmlError(d, err)
+			}
+		}
+	}
+}
+
+// xmlError wraps err into a ParseError with the current position of the decoder.
+func xmlError(d *xml.Decoder, err error) error {
+	line, column := d.InputPos()
+	return fmt.Errorf("cannot decode set from XML: %w", &ParseError{Line: line, Column: column, Err: err})
+}
+
+// unmarshalXMLInto replaces the content of the set by the set decoded (leniently) from XML with the default names.
+// If the data is invalid, the set remains unchanged.
+func unmarshalXMLInto[T comparable, V any](set Set[T, V], d *xml.Decoder) error {
+	decoded, err := unmarshalXML[T, V](d, XMLNames{}, LenientDuplicates)
+	if err != nil {
+		return err
+	}
+	replaceContent(set, decoded)
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler, encoding the set with one child element <item> per element: sets without values
+// contain the element, sets with values contain an <element> and a <value> element. The elements are sorted if T is an ordered type.
+// Use XMLSet for other names, which is also needed to give a set marshaled at the top level a name (see XMLNames.Root).
+func (s *tzSet[T, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXML[T, V](e, start, s, XMLNames{})
+}
+
+// UnmarshalXML implements xml.Unmarshaler, replacing the content of the set by the set decoded from XML.
+// Duplicate elements are accepted (the last value wins), use XMLSet to reject them.
+// If the data is invalid, an error is returned and the set remains unchanged.
+func (s *tzSet[T, V]) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	return unmarshalXMLInto[T, V](s, d)
+}
+
+// MarshalXML implements xml.Marshaler, encoding a snapshot of the set taken under the read lock.
+func (s *syncSet[T, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXML[T, V](e, start, s, XMLNames{})
+}
+
+// UnmarshalXML implements xml.Unmarshaler, replacing the content of the set atomically.
+func (s *syncSet[T, V]) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	return unmarshalXMLInto[T, V](s, d)
+}
+
+// MarshalXML implements xml.Marshaler, encoding a snapshot of all shards taken at the same time.
+func (s *shardedSet[T, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXML[T, V](e, start, s, XMLNames{})
+}
+
+// UnmarshalXML implements xml.Unmarshaler, replacing the content of all shards atomically.
+func (s *shardedSet[T, V]) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	return unmarshalXMLInto[T, V](s, d)
+}
+
+// MarshalXML implements xml.Marshaler, encoding the elements in insertion order.
+func (s *linkedSet[T, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXML[T, V](e, start, s, XMLNames{})
+}
+
+// UnmarshalXML implements xml.Unmarshaler, replacing the content of the set by the decoded elements in the order of the input.
+func (s *linkedSet[T, V]) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	return unmarshalXMLInto[T, V](s, d)
+}
+
+// MarshalXML implements xml.Marshaler, encoding the elements in ascending order.
+func (s *sortedSet[T, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXML[T, V](e, start, s, XMLNames{})
+}
+
+// UnmarshalXML implements xml.Unmarshaler, replacing the content of the set by the decoded elements.
+func (s *sortedSet[T, V]) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	return unmarshalXMLInto[T, V](s, d)
+}
@@ -0,0 +1,135 @@
+package set
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldMarshalSetsAsXML(t *testing.T) {
+	// Given
+	labels := NewWithoutValues[string]()
+	labels.AddWithoutValue("sale")
+	labels.AddWithoutValue("new")
+	prices := NewLinkedWithValues[string, float64]()
+	prices.AddWithValue("banana", 0.25)
+	prices.AddWithValue("apple", 1.5)
+
+	var buf bytes.Buffer
+
+	// When
+	labelsXML, err1 := xml.Marshal(XMLSet[string, InternalEmptyType]{Set: labels, Names: XMLNames{Root: "labels"}})
+	err2 := xml.NewEncoder(&buf).EncodeElement(prices, xml.StartElement{Name: xml.Name{Local: "prices"}})
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, `<labels><item>new</item><item>sale</item></labels>`, string(labelsXML))
+	assert.Equal(t, `<prices><item><element>banana</element><value>0.25</value></item>`+
+		`<item><element>apple</element><value>1.5</value></item></prices>`, buf.String())
+}
+
+func TestShouldMarshalAndUnmarshalSetsInStructsAsXML(t *testing.T) {
+	// Given
+	type catalog struct {
+		XMLName xml.Name                       `xml:"catalog"`
+		Labels  Set[string, InternalEmptyType] `xml:"labels"`
+		Prices  XMLSet[string, float64]        `xml:"prices"`
+	}
+	names := XMLNames{Item: "product", Element: "name", Value: "price"}
+	original := catalog{Labels: NewWithoutValues[string](), Prices: XMLSet[string, float64]{Set: NewWithValues[string, float64](), Names: names}}
+	original.Labels.AddWithoutValue("new")
+	original.Prices.Set.AddWithValue("apple", 1.5)
+
+	// When
+	data, err := xml.Marshal(original)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `<catalog><labels><item>new</item></labels>`+
+		`<prices><product><name>apple</name><price>1.5</price></product></prices></catalog>`, string(data))
+
+	// When
+	decoded := catalog{Labels: NewWithoutValues[string](), Prices: XMLSet[string, float64]{Names: names}}
+	decoded.Labels.AddWithoutValue("old")
+	err = xml.Unmarshal(data, &decoded)
+	// Then the content is replaced
+	assert.Nil(t, err)
+	assert.True(t, original.Labels.Equals(decoded.Labels))
+	assert.True(t, original.Prices.Set.Equals(decoded.Prices.Set))
+}
+
+func TestShouldUnmarshalAllKindsOfSetsFromXML(t *testing.T) {
+	// Given
+	data := `<set>
+  <item><value>three</value><element>3</element></item>
+  <!-- comment -->
+  <item><element>1</element><value>one</value></item>
+  <item><element>2</element></item>
+</set>`
+	for name, set := range map[string]Set[int, string]{
+		"set":      NewWithValues[int, string](),
+		"sync set": NewSyncWithValues[int, string](),
+		"sharded":  NewShardedWithValues[int, string](4, nil),
+		"linked":   NewLinkedWithValues[int, string](),
+		"sorted":   NewSortedWithValues[int, string](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			set.AddWithValue(42, "stale")
+
+			// When
+			err := xml.Unmarshal([]byte(data), set)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, map[int]string{1: "one", 2: "", 3: "three"}, set.GetElements())
+		})
+	}
+}
+
+func TestShouldRejectDuplicatesInXMLStrictly(t *testing.T) {
+	// Given
+	data := "<labels>\n  <item>a</item>\n  <item>b</item>\n  <item>a</item>\n</labels>"
+	labels := XMLSet[string, InternalEmptyType]{Duplicates: StrictDuplicates}
+
+	// When
+	err := xml.Unmarshal([]byte(data), &labels)
+	// Then
+	assert.EqualError(t, err, "cannot decode set from XML: line 4, column 9: duplicate element a, first in line 2, column 9")
+	assert.Nil(t, labels.Set)
+
+	// When
+	labels.Duplicates = LenientDuplicates
+	err = xml.Unmarshal([]byte(data), &labels)
+	// Then a new set is created
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, labels.Set.List())
+}
+
+func TestShouldFailToUnmarshalInvalidXML(t *testing.T) {
+	for data, expected := range map[string]string{
+		"<set>\n<item><element>x</element></item></set>":  "cannot decode set from XML: line 2, column 27: strconv.ParseInt: parsing \"x\": invalid syntax",
+		"<set>\n<entry/></set>":                           "cannot decode set from XML: line 2, column 9: expected <item> but found <entry>",
+		"<set>\n<item><value>a</value></item></set>":      "cannot decode set from XML: line 2, column 30: missing <element> in <item>",
+		"<set>\n<item><key>1</key></item></set>":          "cannot decode set from XML: line 2, column 12: expected <element> or <value> but found <key>",
+		"<set>\n<item><element>1</element></item>":        "cannot decode set from XML: line 2, column 34: XML syntax error on line 2: unexpected EOF",
+		"<set>\n<item><element>1</element></wrong></set>": "cannot decode set from XML: line 2, column 35: XML syntax error on line 2: element <item> closed by </wrong>",
+	} {
+		// Given
+		set := NewWithValues[int, string]()
+		set.AddWithValue(42, "existing")
+
+		// When
+		err := xml.Unmarshal([]byte(data), set)
+
+		// Then the set remains unchanged
+		assert.EqualError(t, err, expected)
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, 2, parseErr.Line)
+		assert.Equal(t, map[int]string{42: "existing"}, set.GetElements())
+	}
+}
@@ -0,0 +1,138 @@
+package set
+
+import (
+	"fmt"
+
+	"github.com/tztz/gocollection/pkg/collection/internal/scalarcodec"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlSetTag is the YAML tag of an unordered set, a mapping whose keys are the elements and whose values are null.
+const yamlSetTag = "!!set"
+
+// YAMLSet wraps a set to encode and decode it with gopkg.in/yaml.v3, which cannot decode into a field of the interface type Set.
+// Sets without values are encoded as a sequence of their elements (or with the !!set tag if configured), sets with values
+// as a mapping from the elements to their values. The elements are sorted if T is an ordered type.
+// Decoding accepts sequences of elements, mappings from elements to values and mappings tagged !!set. Duplicate keys of a mapping
+// are rejected with their position, duplicate elements of a sequence only with StrictDuplicates.
+type YAMLSet[T comparable, V any] struct {
+	Set Set[T, V]
+	// Tagged encodes sets without values as mapping tagged !!set (with null values) instead of a sequence.
+	Tagged     bool
+	Duplicates DuplicateHandling
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding the wrapped set as YAML. A missing set is encoded as null.
+func (y YAMLSet[T, V]) MarshalYAML() (any, error) {
+	if y.Set == nil {
+		return nil, nil
+	}
+	return marshalYAML(y.Set, y.Tagged)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, replacing the content of the wrapped set by the set decoded from the YAML node.
+// If no set is wrapped, a new insertion-ordered set is created. A null node leaves the set unchanged.
+// If the node is invalid, an error is returned and the set remains unchanged.
+func (y *YAMLSet[T, V]) UnmarshalYAML(node *yaml.Node) error {
+	decoded, err := unmarshalYAML[T, V](node, y.Duplicates)
+	if err != nil || decoded == nil {
+		return err
+	}
+	if y.Set == nil {
+		y.Set = decoded
+		return nil
+	}
+	replaceContent(y.Set, decoded)
+	return nil
+}
+
+// marshalYAML returns the YAML node of the set, in the order of orderedEntries: a sequence of the elements for sets without values
+// (a mapping tagged !!set if tagged is true), and a mapping from the elements to their values otherwise.
+func marshalYAML[T comparable, V any](set Set[T, V], tagged bool) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	if scalarcodec.HasValues[V]() {
+		node.Kind = yaml.MappingNode
+	} else if tagged {
+		node.Kind, node.Tag = yaml.MappingNode, yamlSetTag
+	}
+	for _, entry := range orderedEntries(set) {
+		elem := new(yaml.Node)
+		if err := elem.Encode(entry.element); err != nil {
+			return nil, fmt.Errorf("cannot encode set to YAML, element %v: %w", entry.element, err)
+		}
+		node.Content = append(node.Content, elem)
+		if node.Kind != yaml.MappingNode {
+			continue
+		}
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		if scalarcodec.HasValues[V]() {
+			if err := value.Encode(entry.value); err != nil {
+				return nil, fmt.Errorf("cannot encode set to YAML, value of element %v: %w", entry.element, err)
+			}
+		}
+		node.Content = append(node.Content, value)
+	}
+	return node, nil
+}
+
+// unmarshalYAML decodes a sequence of elements or a mapping from elements to values (also tagged !!set) into a new linked set
+// keeping the order of the input. Duplicate keys of a mapping are always rejected, duplicate elements of a sequence only with
+// StrictDuplicates. Errors in the structure are reported as ParseError with the position of the node.
+// A null node returns no set and no error.
+func unmarshalYAML[T comparable, V any](node *yaml.Node, duplicates DuplicateHandling) (*linkedSet[T, V], error) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.ShortTag() == "!!null" {
+		return nil, nil
+	}
+	decoded := newLinkedSet[T, V]()
+	positions := make(map[T]*yaml.Node)
+	add := func(key *yaml.Node, value V, unique bool) error {
+		var elem T
+		if err := key.Decode(&elem); err != nil {
+			return fmt.Errorf("cannot decode set from YAML: %w", err)
+		}
+		if unique {
+			if first, ok := positions[elem]; ok {
+				return yamlError(key, fmt.Errorf("duplicate element %v, first in line %d, column %d", elem, first.Line, first.Column))
+			}
+			positions[elem] = key
+		}
+		decoded.AddWithValue(elem, value)
+		return nil
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			var value V
+			if err := add(item, value, duplicates == StrictDuplicates); err != nil {
+				return nil, err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			var value V
+			if valueNode := node.Content[i+1]; valueNode.ShortTag() != "!!null" {
+				if err := valueNode.Decode(&value); err != nil {
+					return nil, fmt.Errorf("cannot decode set from YAML: %w", err)
+				}
+			}
+			if err := add(node.Content[i], value, true); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, yamlError(node, fmt.Errorf("expected a sequence or mapping but found %s", node.ShortTag()))
+	}
+	return decoded, nil
+}
+
+// yamlError wraps err into a ParseError with the position of the node.
+func yamlError(node *yaml.Node, err error) error {
+	return fmt.Errorf("cannot decode set from YAML: %w", &ParseError{Line: node.Line, Column: node.Column, Err: err})
+}
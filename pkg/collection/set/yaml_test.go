@@ -0,0 +1,181 @@
+package set
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestShouldMarshalSetsAsYAML(t *testing.T) {
+	// Given
+	labels := NewWithoutValues[string]()
+	labels.AddWithoutValue("sale")
+	labels.AddWithoutValue("new")
+	prices := NewLinkedWithValues[string, float64]()
+	prices.AddWithValue("banana", 0.25)
+	prices.AddWithValue("apple", 1.5)
+
+	// When
+	labelsYAML, err1 := yaml.Marshal(YAMLSet[string, InternalEmptyType]{Set: labels})
+	taggedYAML, err2 := yaml.Marshal(YAMLSet[string, InternalEmptyType]{Set: labels, Tagged: true})
+	pricesYAML, err3 := yaml.Marshal(YAMLSet[string, float64]{Set: prices})
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Equal(t, "- new\n- sale\n", string(labelsYAML))
+	assert.Equal(t, "!!set\nnew:\nsale:\n", string(taggedYAML))
+	assert.Equal(t, "banana: 0.25\napple: 1.5\n", string(pricesYAML))
+}
+
+func TestShouldMarshalAndUnmarshalSetsInStructsAsYAML(t *testing.T) {
+	// Given
+	type config struct {
+		AllowedRegions YAMLSet[string, InternalEmptyType] `yaml:"allowedRegions"`
+		Ports          YAMLSet[int, InternalEmptyType]    `yaml:"ports"`
+		Limits         YAMLSet[string, int]               `yaml:"limits"`
+	}
+	original := config{
+		AllowedRegions: YAMLSet[string, InternalEmptyType]{Set: NewWithoutValues[string]()},
+		Ports:          YAMLSet[int, InternalEmptyType]{Set: NewSortedWithoutValues[int]()},
+		Limits:         YAMLSet[string, int]{Set: NewWithValues[string, int]()},
+	}
+	original.AllowedRegions.Set.AddWithoutValue("eu-west-1")
+	original.AllowedRegions.Set.AddWithoutValue("eu-central-1")
+	original.Ports.Set.AddWithoutValue(443)
+	original.Limits.Set.AddWithValue("cpu", 4)
+
+	// When
+	data, err := yaml.Marshal(original)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "allowedRegions:\n    - eu-central-1\n    - eu-west-1\nports:\n    - 443\nlimits:\n    cpu: 4\n", string(data))
+
+	// When
+	decoded := config{Ports: YAMLSet[int, InternalEmptyType]{Set: NewSortedWithoutValues[int]()}}
+	decoded.Ports.Set.AddWithoutValue(80)
+	err = yaml.Unmarshal(data, &decoded)
+	// Then the wrapped sets are created and the content of the set is replaced
+	assert.Nil(t, err)
+	assert.True(t, original.AllowedRegions.Set.Equals(decoded.AllowedRegions.Set))
+	assert.True(t, original.Ports.Set.Equals(decoded.Ports.Set))
+	assert.True(t, original.Limits.Set.Equals(decoded.Limits.Set))
+}
+
+func TestShouldUnmarshalAllKindsOfSetsFromYAML(t *testing.T) {
+	// Given
+	data := "3: three\n1: one\n2:\n"
+	for name, set := range map[string]Set[int, string]{
+		"set":     NewWithValues[int, string](),
+		"sync":    NewSyncWithValues[int, string](),
+		"sharded": NewShardedWithValues[int, string](4, nil),
+		"linked":  NewLinkedWithValues[int, string](),
+		"sorted":  NewSortedWithValues[int, string](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			set.AddWithValue(42, "stale")
+
+			// When
+			err := yaml.Unmarshal([]byte(data), &YAMLSet[int, string]{Set: set})
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, map[int]string{1: "one", 2: "", 3: "three"}, set.GetElements())
+		})
+	}
+}
+
+func TestShouldUnmarshalTaggedSetsAndAliasesFromYAML(t *testing.T) {
+	// Given
+	data := `
+defaults: &defaults !!set
+  ? eu-west-1
+  ? eu-central-1
+regions: *defaults
+`
+	var decoded struct {
+		Regions YAMLSet[string, InternalEmptyType] `yaml:"regions"`
+	}
+
+	// When
+	err := yaml.Unmarshal([]byte(data), &decoded)
+
+	// Then the order of the input is kept
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"eu-west-1", "eu-central-1"}, decoded.Regions.Set.List())
+}
+
+func TestShouldLeaveSetUnchangedForNullYAML(t *testing.T) {
+	// Given
+	labels := NewWithoutValues[string]()
+	labels.AddWithoutValue("existing")
+	var node yaml.Node
+	assert.Nil(t, yaml.Unmarshal([]byte("null"), &node))
+
+	// When
+	err := (&YAMLSet[string, InternalEmptyType]{Set: labels}).UnmarshalYAML(&node)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"existing"}, labels.List())
+}
+
+func TestShouldRejectDuplicateKeysInYAML(t *testing.T) {
+	// Given
+	data := "limits:\n  cpu: 4\n  memory: 8\n  cpu: 2\n"
+	var decoded struct {
+		Limits YAMLSet[string, int] `yaml:"limits"`
+	}
+
+	// When
+	err := yaml.Unmarshal([]byte(data), &decoded)
+
+	// Then
+	assert.EqualError(t, err, "cannot decode set from YAML: line 4, column 3: duplicate element cpu, first in line 2, column 3")
+	var parseErr *ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 4, parseErr.Line)
+	assert.Nil(t, decoded.Limits.Set)
+}
+
+func TestShouldRejectDuplicatesInYAMLSequencesStrictly(t *testing.T) {
+	// Given
+	data := "- a\n- b\n- a\n"
+	labels := YAMLSet[string, InternalEmptyType]{Duplicates: StrictDuplicates}
+
+	// When
+	err := yaml.Unmarshal([]byte(data), &labels)
+	// Then
+	assert.EqualError(t, err, "cannot decode set from YAML: line 3, column 3: duplicate element a, first in line 1, column 3")
+	assert.Nil(t, labels.Set)
+
+	// When
+	labels.Duplicates = LenientDuplicates
+	err = yaml.Unmarshal([]byte(data), &labels)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, labels.Set.List())
+}
+
+func TestShouldFailToUnmarshalInvalidYAML(t *testing.T) {
+	for data, expected := range map[string]string{
+		"1: one\nx: two\n": "cannot decode set from YAML: yaml: unmarshal errors:\n  line 2: cannot unmarshal !!str `x` into int",
+		"1: [one]\n":       "cannot decode set from YAML: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into string",
+		"just text\n":      "cannot decode set from YAML: line 1, column 1: expected a sequence or mapping but found !!str",
+	} {
+		// Given
+		set := NewWithValues[int, string]()
+		set.AddWithValue(42, "existing")
+
+		// When
+		err := yaml.Unmarshal([]byte(data), &YAMLSet[int, string]{Set: set})
+
+		// Then the set remains unchanged
+		assert.EqualError(t, err, expected)
+		assert.Equal(t, map[int]string{42: "existing"}, set.GetElements())
+	}
+}
@@ -0,0 +1,76 @@
+package theta
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// The serialized format is: version (1 byte) | k (uint32) | theta (uint64) | number of retained hashes (uvarint) |
+// retained hashes in ascending order as differences to the previous hash (uvarint), all little endian.
+const formatVersion = 1
+
+// ErrInvalidFormat is returned (wrapped) when unmarshaling data that is not a valid serialized sketch.
+var ErrInvalidFormat = errors.New("invalid theta sketch format")
+
+// MarshalBinary serializes the sketch, so sketches computed on different machines can be combined.
+// The hash function is not serialized, so the sketch has to be unmarshaled into a sketch using the same hash function.
+func (s *sketch[T]) MarshalBinary() ([]byte, error) {
+	data := []byte{formatVersion}
+	data = binary.LittleEndian.AppendUint32(data, uint32(s.k))
+	data = binary.LittleEndian.AppendUint64(data, s.theta)
+	data = binary.AppendUvarint(data, uint64(len(s.hashes)))
+	previous := uint64(0)
+	for _, hash := range slices.Sorted(maps.Keys(s.hashes)) {
+		data = binary.AppendUvarint(data, hash-previous)
+		previous = hash
+	}
+	return data, nil
+}
+
+// UnmarshalBinary replaces the content of the sketch by the serialized sketch, which may have a different k.
+// If the data is invalid, an error wrapping ErrInvalidFormat is returned and the sketch remains unchanged.
+func (s *sketch[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 13 {
+		return fmt.Errorf("%w: data too short (%d bytes)", ErrInvalidFormat, len(data))
+	}
+	if data[0] != formatVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidFormat, data[0])
+	}
+	k := int(binary.LittleEndian.Uint32(data[1:]))
+	if k < MinNominalEntries || k > MaxNominalEntries {
+		return fmt.Errorf("%w: unsupported nominal entries %d", ErrInvalidFormat, k)
+	}
+	result := newSketch(k, binary.LittleEndian.Uint64(data[5:]), s.hashFunc)
+	data = data[13:]
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(k) {
+		return fmt.Errorf("%w: invalid number of retained hashes", ErrInvalidFormat)
+	}
+	data = data[n:]
+	hash := uint64(0)
+	for i := range count {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("%w: unexpected end of data", ErrInvalidFormat)
+		}
+		data = data[n:]
+		if (i > 0 && delta == 0) || hash+delta < hash || hash+delta >= result.theta {
+			return fmt.Errorf("%w: retained hashes must be ascending and less than theta", ErrInvalidFormat)
+		}
+		hash += delta
+		result.hashes[hash] = struct{}{}
+		result.largest = append(result.largest, hash)
+	}
+	if len(data) > 0 {
+		return fmt.Errorf("%w: %d unexpected bytes after retained hashes", ErrInvalidFormat, len(data))
+	}
+	// hashes in descending order form a valid max-heap
+	slices.Reverse(result.largest)
+
+	*s = *result
+	return nil
+}
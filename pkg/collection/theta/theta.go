@@ -0,0 +1,242 @@
+// Sketches estimating the sizes of unions, intersections and differences of large sets (theta sketches).
+package theta
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+const (
+	// MinNominalEntries is the smallest supported number of retained hashes.
+	MinNominalEntries = 16
+	// MaxNominalEntries is the largest supported number of retained hashes.
+	MaxNominalEntries = 1 << 26
+	// DefaultNominalEntries is a number of retained hashes with a relative standard error of about 1.6%.
+	DefaultNominalEntries = 4096
+)
+
+// Sketch is a theta sketch (k minimum values sketch) estimating the number of distinct elements of type T added to it.
+// It retains the hashes of the added elements below a threshold theta, which is lowered so that at most k hashes are retained.
+// The number of distinct elements is estimated as the number of retained hashes divided by theta (as a fraction of the hash space),
+// with a relative standard error of about 1/sqrt(k). As long as fewer than k distinct elements have been added, the count is exact.
+//
+// Unlike HyperLogLog sketches, theta sketches can be combined with Unite, Intersect and Subtract like sets, estimating
+// the sizes of the union, intersection and difference of the sets of added elements. The resulting sketches can be combined further.
+// The error of the estimates grows with the ratio between the sizes of the operands and the result, see LowerBound and UpperBound.
+// Sketches can only be combined if they use the same hash function for T.
+type Sketch[T comparable] interface {
+	Add(T)
+	Estimate() float64
+	LowerBound(float64) float64
+	UpperBound(float64) float64
+	IsEstimationMode() bool
+	NominalEntries() int
+	Retained() int
+
+	Copy() Sketch[T]
+	Unite(Sketch[T]) Sketch[T]
+	Intersect(Sketch[T]) Sketch[T]
+	Subtract(Sketch[T]) Sketch[T]
+
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+// maxTheta is the initial threshold, retaining all hashes (exact mode).
+const maxTheta = math.MaxUint64
+
+type sketch[T comparable] struct {
+	k     int
+	theta uint64
+	// hashes contains the retained hashes, which are all less than theta
+	hashes map[uint64]struct{}
+	// largest is a max-heap of the retained hashes
+	largest  hashHeap
+	hashFunc set.HashFunc[T]
+}
+
+// hashHeap is a max-heap of hashes implementing heap.Interface.
+type hashHeap []uint64
+
+func (h hashHeap) Len() int           { return len(h) }
+func (h hashHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h hashHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hashHeap) Push(x any)        { *h = append(*h, x.(uint64)) }
+func (h *hashHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// New creates a new, empty sketch retaining up to k hashes, using set.DefaultHashFunc for hashing the elements.
+// An error is returned if k is not between MinNominalEntries and MaxNominalEntries.
+func New[T comparable](k int) (Sketch[T], error) {
+	return NewWithHashFunc[T](k, nil)
+}
+
+// NewWithHashFunc creates a new, empty sketch retaining up to k hashes, using hashFunc for hashing the elements.
+// If hashFunc is nil, set.DefaultHashFunc is used.
+// An error is returned if k is not between MinNominalEntries and MaxNominalEntries.
+func NewWithHashFunc[T comparable](k int, hashFunc set.HashFunc[T]) (Sketch[T], error) {
+	if err := checkNominalEntries(k); err != nil {
+		return nil, err
+	}
+	return newSketch(k, maxTheta, hashFunc), nil
+}
+
+// FromSet creates a new sketch retaining up to k hashes of the elements of the given set (ignoring the values).
+// If hashFunc is nil, set.DefaultHashFunc is used. A nil set is treated as an empty set.
+// An error is returned if k is not between MinNominalEntries and MaxNominalEntries.
+func FromSet[T comparable, V any](s set.Set[T, V], k int, hashFunc set.HashFunc[T]) (Sketch[T], error) {
+	sk, err := NewWithHashFunc(k, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		for elem := range s.Elements() {
+			sk.Add(elem)
+		}
+	}
+	return sk, nil
+}
+
+func checkNominalEntries(k int) error {
+	if k < MinNominalEntries || k > MaxNominalEntries {
+		return fmt.Errorf("cannot create theta sketch, nominal entries must be between %d and %d but is %d", MinNominalEntries, MaxNominalEntries, k)
+	}
+	return nil
+}
+
+func newSketch[T comparable](k int, theta uint64, hashFunc set.HashFunc[T]) *sketch[T] {
+	if hashFunc == nil {
+		hashFunc = set.DefaultHashFunc[T]()
+	}
+	return &sketch[T]{k: k, theta: theta, hashes: map[uint64]struct{}{}, hashFunc: hashFunc}
+}
+
+// update retains the hash if it is less than theta, lowering theta if more than k hashes are retained.
+func (s *sketch[T]) update(hash uint64) {
+	if hash >= s.theta {
+		return
+	}
+	if _, found := s.hashes[hash]; found {
+		return
+	}
+	s.hashes[hash] = struct{}{}
+	heap.Push(&s.largest, hash)
+	if len(s.hashes) > s.k {
+		s.theta = heap.Pop(&s.largest).(uint64)
+		delete(s.hashes, s.theta)
+	}
+}
+
+// Add adds an element to the sketch.
+func (s *sketch[T]) Add(element T) {
+	s.update(s.hashFunc(element))
+}
+
+// fraction returns theta as a fraction of the hash space, i.e. the probability of a hash being retained.
+func (s *sketch[T]) fraction() float64 {
+	if s.theta == maxTheta {
+		return 1
+	}
+	return math.Ldexp(float64(s.theta), -64)
+}
+
+// Estimate returns the estimated number of distinct elements added to the sketch.
+func (s *sketch[T]) Estimate() float64 {
+	return float64(len(s.hashes)) / s.fraction()
+}
+
+// standardError returns the standard error of the estimate, assuming the number of retained hashes is binomially distributed.
+func (s *sketch[T]) standardError() float64 {
+	p := s.fraction()
+	return math.Sqrt(float64(len(s.hashes))*(1-p)) / p
+}
+
+// LowerBound returns a lower bound of the number of distinct elements, the given number of standard errors below the estimate.
+// With 1, 2 and 3 standard errors, the number is at least the bound with a probability of about 84%, 97.7% and 99.9%.
+// The bound is never less than the number of retained hashes. In exact mode, the bound equals the exact count.
+func (s *sketch[T]) LowerBound(standardErrors float64) float64 {
+	return max(s.Estimate()-standardErrors*s.standardError(), float64(len(s.hashes)))
+}
+
+// UpperBound returns an upper bound of the number of distinct elements, the given number of standard errors above the estimate.
+// With 1, 2 and 3 standard errors, the number is at most the bound with a probability of about 84%, 97.7% and 99.9%.
+// In exact mode, the bound equals the exact count.
+func (s *sketch[T]) UpperBound(standardErrors float64) float64 {
+	return s.Estimate() + standardErrors*s.standardError()
+}
+
+// IsEstimationMode checks whether or not the count is estimated, i.e. hashes have been dropped.
+// If false, Estimate returns the exact number of distinct elements.
+func (s *sketch[T]) IsEstimationMode() bool {
+	return s.theta != maxTheta
+}
+
+// NominalEntries returns the maximum number of retained hashes k.
+func (s *sketch[T]) NominalEntries() int {
+	return s.k
+}
+
+// Retained returns the number of currently retained hashes.
+func (s *sketch[T]) Retained() int {
+	return len(s.hashes)
+}
+
+// Copy returns a new sketch with the same content as this sketch.
+func (s *sketch[T]) Copy() Sketch[T] {
+	return s.combine(nil, func(inThis, inOther bool) bool { return inThis })
+}
+
+// combine returns a new sketch with theta being the minimum theta of both sketches, retaining the hashes below that theta
+// for which keep returns true. The new sketch retains at most the minimum k of both sketches.
+// A nil other is treated as an empty sketch with the same k.
+func (s *sketch[T]) combine(other Sketch[T], keep func(inThis, inOther bool) bool) *sketch[T] {
+	o := newSketch(s.k, maxTheta, s.hashFunc)
+	if other != nil {
+		o = other.(*sketch[T])
+	}
+	result := newSketch(min(s.k, o.k), min(s.theta, o.theta), s.hashFunc)
+	candidates := make([]uint64, 0, len(s.hashes)+len(o.hashes))
+	for hash := range s.hashes {
+		_, inOther := o.hashes[hash]
+		if keep(true, inOther) {
+			candidates = append(candidates, hash)
+		}
+	}
+	for hash := range o.hashes {
+		if _, inThis := s.hashes[hash]; !inThis && keep(false, true) {
+			candidates = append(candidates, hash)
+		}
+	}
+	// adding the hashes in ascending order lowers theta to the (k+1)th smallest hash at most once
+	slices.Sort(candidates)
+	for _, hash := range candidates {
+		result.update(hash)
+	}
+	return result
+}
+
+// Unite returns a new sketch estimating the number of distinct elements added to this sketch or other (union).
+// A nil other is treated as an empty sketch.
+func (s *sketch[T]) Unite(other Sketch[T]) Sketch[T] {
+	return s.combine(other, func(inThis, inOther bool) bool { return inThis || inOther })
+}
+
+// Intersect returns a new sketch estimating the number of distinct elements added to both, this sketch and other (intersection).
+// A nil other is treated as an empty sketch.
+func (s *sketch[T]) Intersect(other Sketch[T]) Sketch[T] {
+	return s.combine(other, func(inThis, inOther bool) bool { return inThis && inOther })
+}
+
+// Subtract returns a new sketch estimating the number of distinct elements added to this sketch but not to other (a-not-b).
+// A nil other is treated as an empty sketch.
+func (s *sketch[T]) Subtract(other Sketch[T]) Sketch[T] {
+	return s.combine(other, func(inThis, inOther bool) bool { return inThis && !inOther })
+}
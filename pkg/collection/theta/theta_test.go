@@ -0,0 +1,212 @@
+package theta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func sketchOfRange(from, to int) Sketch[int] {
+	s, _ := New[int](DefaultNominalEntries)
+	for i := from; i < to; i++ {
+		s.Add(i)
+	}
+	return s
+}
+
+func TestShouldCountExactlyBelowNominalEntries(t *testing.T) {
+	// Given
+	s, err := New[string](16)
+	assert.Nil(t, err)
+
+	// When
+	for _, elem := range []string{"a", "b", "c", "a"} {
+		s.Add(elem)
+	}
+
+	// Then
+	assert.False(t, s.IsEstimationMode())
+	assert.Equal(t, 3.0, s.Estimate())
+	assert.Equal(t, 3.0, s.LowerBound(2))
+	assert.Equal(t, 3.0, s.UpperBound(2))
+	assert.Equal(t, 3, s.Retained())
+	assert.Equal(t, 16, s.NominalEntries())
+}
+
+func TestShouldEstimateCardinality(t *testing.T) {
+	// Given
+	s := sketchOfRange(0, 100_000)
+
+	// Expect
+	assert.True(t, s.IsEstimationMode())
+	assert.Equal(t, DefaultNominalEntries, s.Retained())
+	assert.InEpsilon(t, 100_000, s.Estimate(), 0.05)
+	assert.LessOrEqual(t, s.LowerBound(3), 100_000.0)
+	assert.GreaterOrEqual(t, s.UpperBound(3), 100_000.0)
+	assert.Less(t, s.LowerBound(2), s.LowerBound(1))
+	assert.Greater(t, s.UpperBound(2), s.UpperBound(1))
+}
+
+func TestShouldEstimateSetAlgebra(t *testing.T) {
+	// Given segments A = [0, 60000) and B = [40000, 100000)
+	a := sketchOfRange(0, 60_000)
+	b := sketchOfRange(40_000, 100_000)
+
+	for name, testCase := range map[string]struct {
+		sketch   Sketch[int]
+		expected float64
+	}{
+		"union":        {sketch: a.Unite(b), expected: 100_000},
+		"intersection": {sketch: a.Intersect(b), expected: 20_000},
+		"a not b":      {sketch: a.Subtract(b), expected: 40_000},
+		"b not a":      {sketch: b.Subtract(a), expected: 40_000},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Expect
+			assert.InEpsilon(t, testCase.expected, testCase.sketch.Estimate(), 0.1)
+			assert.LessOrEqual(t, testCase.sketch.LowerBound(3), testCase.expected)
+			assert.GreaterOrEqual(t, testCase.sketch.UpperBound(3), testCase.expected)
+			assert.LessOrEqual(t, testCase.sketch.Retained(), DefaultNominalEntries)
+		})
+	}
+
+	// and the union equals the sketch of all elements
+	assert.Equal(t, sketchOfRange(0, 100_000).Estimate(), a.Unite(b).Estimate())
+	// and the operands remain unchanged
+	assert.Equal(t, sketchOfRange(0, 60_000).Estimate(), a.Estimate())
+}
+
+func TestShouldCombineSketchesInExactMode(t *testing.T) {
+	// Given
+	a := sketchOfRange(0, 30)
+	b := sketchOfRange(20, 40)
+
+	// Expect
+	assert.Equal(t, 40.0, a.Unite(b).Estimate())
+	assert.Equal(t, 10.0, a.Intersect(b).Estimate())
+	assert.Equal(t, 20.0, a.Subtract(b).Estimate())
+	assert.Equal(t, 30.0, a.Unite(nil).Estimate())
+	assert.Equal(t, 0.0, a.Intersect(nil).Estimate())
+	assert.Equal(t, 30.0, a.Subtract(nil).Estimate())
+	assert.Equal(t, 30.0, a.Copy().Estimate())
+	assert.False(t, a.Unite(b).IsEstimationMode())
+}
+
+func TestShouldUniteSketchesWithDifferentNominalEntries(t *testing.T) {
+	// Given
+	small, _ := New[int](64)
+	large, _ := New[int](1024)
+	for i := range 10_000 {
+		small.Add(i)
+		large.Add(i + 5_000)
+	}
+
+	// When
+	union := large.Unite(small)
+
+	// Then
+	assert.Equal(t, 64, union.NominalEntries())
+	assert.LessOrEqual(t, union.Retained(), 64)
+	assert.LessOrEqual(t, union.LowerBound(3), 15_000.0)
+	assert.GreaterOrEqual(t, union.UpperBound(3), 15_000.0)
+}
+
+func TestShouldFailToCreateSketchWithInvalidNominalEntries(t *testing.T) {
+	// When
+	s, err := New[int](8)
+
+	// Then
+	assert.Nil(t, s)
+	assert.EqualError(t, err, "cannot create theta sketch, nominal entries must be between 16 and 67108864 but is 8")
+}
+
+func TestShouldCreateSketchFromSet(t *testing.T) {
+	// Given
+	s := set.NewWithValues[string, int]()
+	s.AddWithValue("apple", 1)
+	s.AddWithValue("banana", 2)
+
+	// When
+	sk, err := FromSet(s, 16, nil)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, sk.Estimate())
+
+	// When
+	sk, err = FromSet[string, int](nil, 16, nil)
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, sk.Estimate())
+}
+
+func TestShouldMergeSerializedSketches(t *testing.T) {
+	// Given sketches computed on different machines
+	data1, err1 := sketchOfRange(0, 60_000).MarshalBinary()
+	data2, err2 := sketchOfRange(40_000, 100_000).MarshalBinary()
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+
+	// When
+	a, _ := New[int](16)
+	b, _ := New[int](16)
+	assert.Nil(t, a.UnmarshalBinary(data1))
+	assert.Nil(t, b.UnmarshalBinary(data2))
+
+	// Then
+	assert.Equal(t, DefaultNominalEntries, a.NominalEntries())
+	assert.Equal(t, sketchOfRange(0, 60_000).Estimate(), a.Estimate())
+	assert.Equal(t, sketchOfRange(40_000, 100_000).Intersect(sketchOfRange(0, 60_000)).Estimate(), b.Intersect(a).Estimate())
+	assert.Equal(t, sketchOfRange(0, 100_000).Estimate(), a.Unite(b).Estimate())
+
+	// and unmarshaled sketches can still be updated
+	for i := 100_000; i < 200_000; i++ {
+		a.Add(i)
+	}
+	assert.Equal(t, DefaultNominalEntries, a.Retained())
+	assert.InEpsilon(t, 160_000, a.Estimate(), 0.1)
+}
+
+func TestShouldFailToUnmarshalInvalidData(t *testing.T) {
+	// Given
+	header := []byte{1, 16, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	for name, testCase := range map[string]struct {
+		data []byte
+		err  string
+	}{
+		"too short":        {data: header[:12], err: "invalid theta sketch format: data too short (12 bytes)"},
+		"version":          {data: append([]byte{2}, header[1:]...), err: "invalid theta sketch format: unsupported version 2"},
+		"nominal entries":  {data: append([]byte{1, 8}, header[2:]...), err: "invalid theta sketch format: unsupported nominal entries 8"},
+		"missing count":    {data: header, err: "invalid theta sketch format: invalid number of retained hashes"},
+		"too many hashes":  {data: append(header, 17), err: "invalid theta sketch format: invalid number of retained hashes"},
+		"truncated":        {data: append(header, 2, 5), err: "invalid theta sketch format: unexpected end of data"},
+		"duplicate hashes": {data: append(header, 2, 5, 0), err: "invalid theta sketch format: retained hashes must be ascending and less than theta"},
+		"above theta":      {data: append([]byte{1, 16, 0, 0, 0, 10, 0, 0, 0, 0, 0, 0, 0}, 1, 10), err: "invalid theta sketch format: retained hashes must be ascending and less than theta"},
+		"trailing":         {data: append(header, 1, 5, 5), err: "invalid theta sketch format: 1 unexpected bytes after retained hashes"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			s, _ := New[int](32)
+			s.Add(42)
+
+			// When
+			err := s.UnmarshalBinary(testCase.data)
+
+			// Then
+			assert.True(t, errors.Is(err, ErrInvalidFormat))
+			assert.EqualError(t, err, testCase.err)
+			assert.Equal(t, 32, s.NominalEntries())
+			assert.Equal(t, 1.0, s.Estimate())
+		})
+	}
+}
+
+func BenchmarkSketchAdd(b *testing.B) {
+	s, _ := New[int](DefaultNominalEntries)
+	for i := 0; i < b.N; i++ {
+		s.Add(i)
+	}
+}
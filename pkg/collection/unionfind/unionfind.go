@@ -0,0 +1,195 @@
+// A disjoint-set (union-find) structure partitioning elements into connected components.
+package unionfind
+
+import (
+	"iter"
+	"strings"
+
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+// UnionFind partitions elements of type T into disjoint components. Each element starts in its own component,
+// Union merges the components of two elements. Find and Union take nearly constant amortized time
+// (path compression and union by rank).
+// A UnionFind is not safe for concurrent use, not even for Find, which compresses paths.
+type UnionFind[T comparable] interface {
+	MakeSet(T) bool
+	MakeSets(iter.Seq[T])
+	Union(T, T) bool
+
+	Find(T) (T, bool)
+	Connected(T, T) bool
+	Contains(T) bool
+	Size() int
+	ComponentCount() int
+	Components() []set.Set[T, set.InternalEmptyType]
+	String() string
+}
+
+type unionFind[T comparable] struct {
+	// indices maps each element to its index in elements, parents and ranks
+	indices  map[T]int
+	elements []T
+	parents  []int
+	// ranks are upper bounds of the heights of the trees of the components, only maintained for roots
+	ranks []uint8
+	// componentCount is the number of roots
+	componentCount int
+}
+
+// New creates a new, empty union-find structure.
+func New[T comparable]() UnionFind[T] {
+	return &unionFind[T]{indices: make(map[T]int)}
+}
+
+// FromPairs creates a new union-find structure uniting both elements of each pair.
+func FromPairs[T comparable](pairs iter.Seq2[T, T]) UnionFind[T] {
+	uf := New[T]()
+	for a, b := range pairs {
+		uf.Union(a, b)
+	}
+	return uf
+}
+
+// FromSet creates a new union-find structure containing each element of the set in its own component (ignoring the values).
+// A nil set is treated as an empty set.
+func FromSet[T comparable, V any](s set.Set[T, V]) UnionFind[T] {
+	uf := New[T]()
+	if s != nil {
+		uf.MakeSets(s.Elements())
+	}
+	return uf
+}
+
+// MakeSet adds the element in its own component and returns true, or returns false if the element is already contained.
+func (uf *unionFind[T]) MakeSet(elem T) bool {
+	if _, ok := uf.indices[elem]; ok {
+		return false
+	}
+	uf.add(elem)
+	return true
+}
+
+// MakeSets adds each element of seq which is not yet contained in its own component, e.g. the elements of a set
+// by passing set.Elements().
+func (uf *unionFind[T]) MakeSets(seq iter.Seq[T]) {
+	for elem := range seq {
+		uf.MakeSet(elem)
+	}
+}
+
+// Union merges the components of both elements and returns true, or returns false if they are already connected.
+// Elements which are not yet contained are added first.
+func (uf *unionFind[T]) Union(a, b T) bool {
+	rootA := uf.find(uf.index(a))
+	rootB := uf.find(uf.index(b))
+	if rootA == rootB {
+		return false
+	}
+	switch {
+	case uf.ranks[rootA] < uf.ranks[rootB]:
+		uf.parents[rootA] = rootB
+	case uf.ranks[rootA] > uf.ranks[rootB]:
+		uf.parents[rootB] = rootA
+	default:
+		uf.parents[rootB] = rootA
+		uf.ranks[rootA]++
+	}
+	uf.componentCount--
+	return true
+}
+
+// Find returns the representative of the component of the element, which is the same element for all elements
+// of a component until the component is merged with another one. It returns false if the element is not contained.
+func (uf *unionFind[T]) Find(elem T) (T, bool) {
+	i, ok := uf.indices[elem]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return uf.elements[uf.find(i)], true
+}
+
+// Connected checks if both elements are contained and in the same component.
+func (uf *unionFind[T]) Connected(a, b T) bool {
+	i, okA := uf.indices[a]
+	j, okB := uf.indices[b]
+	return okA && okB && uf.find(i) == uf.find(j)
+}
+
+// Contains checks if the element is contained in any component.
+func (uf *unionFind[T]) Contains(elem T) bool {
+	_, ok := uf.indices[elem]
+	return ok
+}
+
+// Size returns the number of elements of all components.
+func (uf *unionFind[T]) Size() int {
+	return len(uf.elements)
+}
+
+// ComponentCount returns the number of components.
+func (uf *unionFind[T]) ComponentCount() int {
+	return uf.componentCount
+}
+
+// Components returns a new set for each component. The components are ordered by the elements added first,
+// and each set iterates over its elements in the order they were added.
+func (uf *unionFind[T]) Components() []set.Set[T, set.InternalEmptyType] {
+	components := make([]set.Set[T, set.InternalEmptyType], 0, uf.componentCount)
+	// positions maps the index of each root to the position of its component
+	positions := make(map[int]int, uf.componentCount)
+	for i, elem := range uf.elements {
+		root := uf.find(i)
+		position, ok := positions[root]
+		if !ok {
+			position = len(components)
+			positions[root] = position
+			components = append(components, set.NewLinkedWithoutValues[T]())
+		}
+		components[position].AddWithoutValue(elem)
+	}
+	return components
+}
+
+// String returns a string representation of the components in the form {{a, b}, {c}}, ordered like Components.
+func (uf *unionFind[T]) String() string {
+	components := uf.Components()
+	strComponents := make([]string, len(components))
+	for i, component := range components {
+		strComponents[i] = "{" + component.String() + "}"
+	}
+	return "{" + strings.Join(strComponents, ", ") + "}"
+}
+
+// index returns the index of the element, adding the element in its own component if it is not contained.
+func (uf *unionFind[T]) index(elem T) int {
+	if i, ok := uf.indices[elem]; ok {
+		return i
+	}
+	return uf.add(elem)
+}
+
+// add adds the element in its own component and returns its index.
+func (uf *unionFind[T]) add(elem T) int {
+	i := len(uf.elements)
+	uf.indices[elem] = i
+	uf.elements = append(uf.elements, elem)
+	uf.parents = append(uf.parents, i)
+	uf.ranks = append(uf.ranks, 0)
+	uf.componentCount++
+	return i
+}
+
+// find returns the index of the root of the component of the element with index i,
+// pointing all elements on the path directly to the root.
+func (uf *unionFind[T]) find(i int) int {
+	root := i
+	for uf.parents[root] != root {
+		root = uf.parents[root]
+	}
+	for uf.parents[i] != root {
+		uf.parents[i], i = root, uf.parents[i]
+	}
+	return root
+}
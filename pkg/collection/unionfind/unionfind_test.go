@@ -0,0 +1,138 @@
+package unionfind
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tztz/gocollection/pkg/collection/set"
+)
+
+func pairs(p ...[2]string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, pair := range p {
+			if !yield(pair[0], pair[1]) {
+				return
+			}
+		}
+	}
+}
+
+func TestShouldMakeSingletonComponents(t *testing.T) {
+	// Given
+	uf := New[string]()
+
+	// When
+	added1 := uf.MakeSet("a")
+	added2 := uf.MakeSet("b")
+	added3 := uf.MakeSet("a")
+
+	// Then
+	assert.True(t, added1)
+	assert.True(t, added2)
+	assert.False(t, added3)
+	assert.Equal(t, 2, uf.Size())
+	assert.Equal(t, 2, uf.ComponentCount())
+	root, ok := uf.Find("a")
+	assert.True(t, ok)
+	assert.Equal(t, "a", root)
+	_, ok = uf.Find("x")
+	assert.False(t, ok)
+	assert.True(t, uf.Connected("a", "a"))
+	assert.False(t, uf.Connected("a", "b"))
+	assert.False(t, uf.Connected("x", "x"))
+	assert.Equal(t, "{{a}, {b}}", uf.String())
+}
+
+func TestShouldUniteComponents(t *testing.T) {
+	// Given
+	uf := New[string]()
+
+	// When
+	united1 := uf.Union("a", "b")
+	united2 := uf.Union("c", "d")
+	united3 := uf.Union("b", "a")
+	united4 := uf.Union("d", "a")
+	uf.MakeSet("e")
+
+	// Then
+	assert.True(t, united1)
+	assert.True(t, united2)
+	assert.False(t, united3)
+	assert.True(t, united4)
+	assert.Equal(t, 5, uf.Size())
+	assert.Equal(t, 2, uf.ComponentCount())
+	assert.True(t, uf.Connected("a", "c"))
+	assert.False(t, uf.Connected("a", "e"))
+	assert.True(t, uf.Contains("d"))
+	rootA, _ := uf.Find("a")
+	rootD, _ := uf.Find("d")
+	assert.Equal(t, rootA, rootD)
+	assert.Equal(t, "{{a, b, c, d}, {e}}", uf.String())
+}
+
+func TestShouldBuildFromPairs(t *testing.T) {
+	// Given
+	links := pairs([2]string{"alice", "bob"}, [2]string{"carol", "dave"}, [2]string{"bob", "erin"}, [2]string{"frank", "frank"})
+
+	// When
+	uf := FromPairs(links)
+
+	// Then
+	assert.Equal(t, 3, uf.ComponentCount())
+	assert.Equal(t, "{{alice, bob, erin}, {carol, dave}, {frank}}", uf.String())
+	assert.Equal(t, 2, FromPairs(maps.All(map[int]int{1: 2, 3: 4})).ComponentCount())
+}
+
+func TestShouldAddElementsFromSet(t *testing.T) {
+	// Given
+	s := set.Collect(slices.Values([]int{1, 2, 3, 4}))
+
+	// When
+	uf := FromSet(s)
+	uf.Union(1, 3)
+	uf.MakeSets(slices.Values([]int{4, 5}))
+
+	// Then
+	assert.Equal(t, 5, uf.Size())
+	assert.Equal(t, 4, uf.ComponentCount())
+	assert.Equal(t, 0, FromSet[int, set.InternalEmptyType](nil).Size())
+}
+
+func TestShouldReturnComponentsAsSets(t *testing.T) {
+	// Given
+	uf := FromPairs(pairs([2]string{"x", "y"}, [2]string{"z", "x"}))
+	uf.MakeSet("w")
+
+	// When
+	components := uf.Components()
+
+	// Then
+	assert.Len(t, components, 2)
+	assert.Equal(t, []string{"x", "y", "z"}, slices.Collect(components[0].Elements()))
+	assert.True(t, components[1].Equals(set.Collect(slices.Values([]string{"w"}))))
+	// and changing the sets does not change the structure
+	components[0].Remove("x")
+	assert.Equal(t, 3, uf.Components()[0].Size())
+}
+
+func TestShouldKeepTreesFlat(t *testing.T) {
+	// Given
+	uf := New[int]()
+	for i := range 1000 {
+		uf.Union(i, i+1)
+	}
+
+	// When
+	root, _ := uf.Find(1000)
+
+	// Then
+	assert.Equal(t, 1, uf.ComponentCount())
+	impl := uf.(*unionFind[int])
+	assert.Equal(t, impl.indices[root], impl.parents[impl.indices[1000]])
+	for _, rank := range impl.ranks {
+		assert.LessOrEqual(t, rank, uint8(10))
+	}
+}